@@ -0,0 +1,15 @@
+// Package assets holds the files bundled into the kubereport binary.
+package assets
+
+import _ "embed"
+
+// Logo is the kubereport logo, a PNG image, shown in reports unless a theme replaces it.
+//
+//go:embed kubereport.png
+var Logo []byte
+
+// Font is M+ 1p Regular, a TrueType font covering Latin, Cyrillic and Japanese, which PDF
+// reports are drawn in unless a theme replaces it. Its license is in fonts/LICENSE-mplus.txt.
+//
+//go:embed fonts/mplus-1p-regular.ttf
+var Font []byte
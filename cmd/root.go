@@ -1,29 +1,69 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kubesuiteorg/kubereport/pkg/email"
 	"github.com/kubesuiteorg/kubereport/pkg/report"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 	"github.com/robfig/cron/v3"
 	"github.com/spf13/cobra"
 )
 
 var (
-	recipient   string
-	sender      string
-	password    string
-	subject     string
-	body        string
-	kubeconfig  string
-	schedule    string
-	reportType  string
-	smtpServer  string
-	smtpPort    string
-	useTLS      bool
-	showVersion bool
+	recipient      string
+	sender         string
+	password       string
+	subject        string
+	body           string
+	kubeconfig     string
+	schedule       string
+	reportType     string
+	smtpServer     string
+	smtpPort       string
+	useTLS         bool
+	showVersion    bool
+	fromFiles      []string
+	fromDir        string
+	saveSnapshot   string
+	timeout        time.Duration
+	sectionTimeout time.Duration
+	workers        int
+	strict         bool
+	pageSize       int64
+	stream         bool
+	sortBuffer     int
+	watchCache     bool
+	qps            float32
+	burst          int
+	requestTimeout time.Duration
+	format         string
+	archive        string
+	csvNoEscape    bool
+	csvBOM         bool
+	csvDelimiter   string
+	csvComma       rune
+	sqliteAppend   string
+	output         string
+	sections       []string
+	metricsAddr    string
+	metricsFile    string
+	metrics        *report.Metrics
+	themeFile      string
+	theme          *report.Theme
 )
 
 var version = "v0.1.1"
@@ -37,39 +77,225 @@ var rootCmd = &cobra.Command{
 			return
 		}
 
+		switch format {
+		case "", "pdf", "json", "yaml", "html", "markdown", "xlsx", "csv-bundle", "sqlite":
+		default:
+			log.Fatalf("Unsupported format %q: use 'pdf', 'json', 'yaml', 'html', 'markdown', 'xlsx', 'csv-bundle' or 'sqlite'", format)
+		}
+		if sqliteAppend != "" && format != "sqlite" {
+			log.Fatalf("--sqlite-append needs --format sqlite")
+		}
+		if archive != "tar.gz" && archive != "zip" {
+			log.Fatalf("Unsupported archive %q: use 'tar.gz' or 'zip'", archive)
+		}
+		var err error
+		if csvComma, err = parseDelimiter(csvDelimiter); err != nil {
+			log.Fatalf("Invalid --csv-delimiter: %v", err)
+		}
+		switch output {
+		case "":
+			if len(sections) > 0 {
+				log.Fatalf("--sections limits what --output prints and needs it")
+			}
+		case "table", "wide":
+			if format != "" {
+				log.Fatalf("--output prints the report to the terminal and cannot be combined with --format")
+			}
+			if recipient != "" {
+				log.Fatalf("--output prints the report to the terminal, so there is no file to email")
+			}
+		default:
+			if _, err := strconv.Atoi(output); err == nil {
+				log.Fatalf("Unsupported output %q: -o is short for --output, set the SMTP port with --smtp-port", output)
+			}
+			log.Fatalf("Unsupported output %q: use 'table' or 'wide'", output)
+		}
+
+		if themeFile != "" {
+			var err error
+			if theme, err = report.LoadTheme(themeFile); err != nil {
+				log.Fatalf("Error loading theme: %v", err)
+			}
+		}
+
+		if metricsAddr != "" && schedule == "" {
+			log.Fatalf("--metrics-addr serves the figures of scheduled runs and needs --schedule; use --metrics-textfile for a single run")
+		}
+		// node_exporter silently ignores files of any other name.
+		if metricsFile != "" && !strings.HasSuffix(metricsFile, ".prom") {
+			log.Fatalf("--metrics-textfile %q must end in .prom for node_exporter to read it", metricsFile)
+		}
+
+		// Cancel in-flight collection on Ctrl-C or when the pod is terminated
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if metricsAddr != "" || metricsFile != "" {
+			metrics = report.NewMetrics(metricsFile)
+		}
+		if metricsAddr != "" {
+			serveMetrics(ctx, metricsAddr)
+		}
+
 		if schedule != "" {
+			var cache *report.Cache
+			if watchCache && len(fromFiles) == 0 && fromDir == "" {
+				// Keep the cluster in informers so that each tick renders without listing it again
+				// The HTML report shows the sections of both reports
+				reports := []section.Report{selectedReport()}
+				if format == "html" {
+					reports = []section.Report{section.General, section.Detailed}
+				}
+				var err error
+				cache, err = report.NewCache(ctx, reportOptions(nil), reports...)
+				if err != nil {
+					log.Fatalf("Error starting watch cache: %v", err)
+				}
+			}
+
 			// Schedule the report generation
 			c := cron.New()
 			_, err := c.AddFunc(schedule, func() {
-				runReportGeneration()
+				runReportGeneration(ctx, cache)
 			})
 			if err != nil {
 				log.Fatalf("Error scheduling report: %v", err)
 			}
 			c.Start()
-			// Keep the application running
-			select {}
+			// Keep the application running until it is asked to stop
+			<-ctx.Done()
+			<-c.Stop().Done()
 		} else {
 			// Run report generation immediately
-			runReportGeneration()
+			runReportGeneration(ctx, nil)
 		}
 	},
 }
 
-func runReportGeneration() {
+// reportOptions returns the report options set by the command-line flags.
+func reportOptions(cache *report.Cache) report.Options {
+	opts := report.Options{
+		Kubeconfig:     kubeconfig,
+		FromFiles:      fromFiles,
+		SaveSnapshot:   saveSnapshot,
+		Strict:         strict,
+		Workers:        workers,
+		SectionTimeout: sectionTimeout,
+		PageSize:       pageSize,
+		Stream:         stream,
+		SortBuffer:     sortBuffer,
+		QPS:            qps,
+		Burst:          burst,
+		RequestTimeout: requestTimeout,
+		CSVNoEscape:    csvNoEscape,
+		CSVBOM:         csvBOM,
+		CSVDelimiter:   csvComma,
+		ToolVersion:    version,
+		Cache:          cache,
+		Metrics:        metrics,
+		Theme:          theme,
+	}
+	if fromDir != "" {
+		opts.FromFiles = append(opts.FromFiles, fromDir)
+	}
+	return opts
+}
+
+// serveMetrics serves the metrics of the latest run at /metrics on addr until ctx is done.
+func serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error serving metrics: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+}
+
+// selectedReport returns the report to generate: the one named by --report, or the detailed
+// one, whose columns are the wide set, for --output wide.
+func selectedReport() section.Report {
+	if output == "wide" {
+		return section.Detailed
+	}
+	return section.Report(reportType)
+}
+
+// parseDelimiter returns the CSV delimiter named by s: a single character, or "tab".
+func parseDelimiter(s string) (rune, error) {
+	if s == "tab" || s == `\t` {
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	switch {
+	case size == 0 || size != len(s):
+		return 0, fmt.Errorf("%q is not a single character", s)
+	case r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError || r == '\uFEFF':
+		return 0, fmt.Errorf("%q cannot separate CSV fields", s)
+	}
+	return r, nil
+}
+
+func runReportGeneration(ctx context.Context, cache *report.Cache) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	var (
 		clusterName string
 		outputPath  string
 		err         error
 	)
 
-	switch reportType {
-	case "detailed":
+	opts := reportOptions(cache)
+
+	if output != "" {
+		// Print the sections to the terminal instead of writing a file
+		clusterName, err = report.PrintTables(ctx, opts, selectedReport(), sections, os.Stdout)
+		if err != nil {
+			log.Fatalf("Error generating report for the %s cluster: %v", clusterName, err)
+		}
+		return
+	}
+
+	switch {
+	case format == "json" || format == "yaml":
+		// Generate the structured report
+		clusterName, outputPath, err = report.GenerateDocument(ctx, opts, section.Report(reportType), format)
+	case format == "html":
+		// Generate the HTML report with the sections of both reports
+		clusterName, outputPath, err = report.GenerateHTML(ctx, opts)
+	case format == "markdown":
+		// Generate the Markdown report
+		clusterName, outputPath, err = report.GenerateMarkdown(ctx, opts, section.Report(reportType))
+	case format == "xlsx":
+		// Generate the workbook with a sheet per section
+		clusterName, outputPath, err = report.GenerateXLSX(ctx, opts, section.Report(reportType))
+	case format == "csv-bundle":
+		// Generate an archive with a CSV file per table and a manifest
+		clusterName, outputPath, err = report.GenerateCSVBundle(ctx, opts, section.Report(reportType), archive)
+	case format == "sqlite":
+		// Write the tables to a new SQLite database, or add the run to an existing one
+		clusterName, outputPath, err = report.GenerateSQLite(ctx, opts, section.Report(reportType), sqliteAppend)
+	case format == "pdf" && reportType == "detailed":
+		// Generate the detailed report as a landscape PDF
+		clusterName, outputPath, err = report.GenerateDetailedPDF(ctx, opts)
+	case reportType == "detailed":
 		// Generate the CSV report
-		clusterName, outputPath, err = report.GenerateCSV(kubeconfig)
+		clusterName, outputPath, err = report.GenerateCSV(ctx, opts)
 	default:
 		// Generate the PDF report
-		clusterName, outputPath, err = report.GeneratePDF(kubeconfig)
+		clusterName, outputPath, err = report.GeneratePDF(ctx, opts)
 	}
 
 	if err != nil {
@@ -102,7 +328,32 @@ func init() {
 	rootCmd.Flags().StringVarP(&kubeconfig, "kubeconfig", "k", "", "Path to kubeconfig file.")
 	rootCmd.Flags().StringVarP(&schedule, "schedule", "t", "", "Cron schedule for report generation (e.g., '* * * * *').")
 	rootCmd.Flags().StringVarP(&reportType, "report", "d", "general", "Report type: 'general' (PDF) or 'detailed' (CSV).")
+	rootCmd.Flags().StringVar(&format, "format", "", "Output format: 'pdf' for a PDF of either report, 'json' or 'yaml' for structured output, 'html' for a self-contained page with the general and detailed sections, 'markdown' for GitHub-flavoured tables, 'xlsx' for a workbook with a sheet per section, 'csv-bundle' for an archive with a CSV file per table, or 'sqlite' for a database with a table per table. By default the general report is a PDF and the detailed report a CSV.")
+	rootCmd.Flags().StringVarP(&output, "output", "o", "", "Print the report to the terminal instead of writing a file: 'table' for the sections of --report as aligned tables, or 'wide' for the detailed sections and their columns.")
+	rootCmd.Flags().StringSliceVar(&sections, "sections", nil, "With --output, print only these sections, named by title or key (e.g. 'pod_details'); comma-separated or repeatable.")
+	rootCmd.Flags().BoolVar(&csvNoEscape, "csv-no-escape", false, "Write CSV cells that begin with =, +, -, @, a tab or a carriage return as they are, instead of prefixing them with a single quote so that spreadsheets do not run them as formulas.")
+	rootCmd.Flags().BoolVar(&csvBOM, "csv-bom", false, "Start CSV files with a UTF-8 byte order mark, which Excel needs to detect the encoding.")
+	rootCmd.Flags().StringVar(&csvDelimiter, "csv-delimiter", ",", "Character separating CSV fields, such as ';' for European Excel locales, or 'tab'.")
+	rootCmd.Flags().StringVar(&sqliteAppend, "sqlite-append", "", "With --format sqlite, add the run to this database, created if missing, instead of writing a new one.")
+	rootCmd.Flags().StringVar(&themeFile, "theme", "", "YAML theme file with the logo, titles, colours, paper size, orientation and footer of PDF and HTML reports.")
+	rootCmd.Flags().StringVar(&archive, "archive", "tar.gz", "Archive the CSV files of --format csv-bundle are packed into: 'tar.gz' or 'zip'.")
 	rootCmd.Flags().StringVarP(&smtpServer, "smtp-server", "m", "", "SMTP server address (e.g., smtp.gmail.com).")
-	rootCmd.Flags().StringVarP(&smtpPort, "smtp-port", "o", "", "SMTP server port (default: 587).")
+	rootCmd.Flags().StringVar(&smtpPort, "smtp-port", "", "SMTP server port (default: 587).")
 	rootCmd.Flags().BoolVarP(&useTLS, "use-tls", "u", true, "Enable TLS for SMTP connection (default: true).")
+	rootCmd.Flags().StringSliceVar(&fromFiles, "from-file", nil, "Render the report offline from a saved snapshot, a 'kubectl get -o yaml' dump or a must-gather archive (repeatable).")
+	rootCmd.Flags().StringVar(&fromDir, "from-dir", "", "Render the report offline from the manifests found in a directory, such as an extracted must-gather.")
+	rootCmd.Flags().DurationVar(&timeout, "timeout", 0, "Abort report generation if it takes longer than this (e.g. 10m). Zero means no limit.")
+	rootCmd.Flags().DurationVar(&sectionTimeout, "section-timeout", 5*time.Minute, "Maximum time allowed for collecting a single resource kind. Zero means no limit.")
+	rootCmd.Flags().IntVar(&workers, "workers", 4, "Number of resource kinds collected in parallel.")
+	rootCmd.Flags().BoolVar(&strict, "strict", false, "Fail the whole report on the first resource or section error instead of marking that section unavailable.")
+	rootCmd.Flags().Int64Var(&pageSize, "page-size", snapshot.DefaultPageSize, "Number of objects requested per list call; larger lists are fetched in pages.")
+	rootCmd.Flags().BoolVar(&stream, "stream", false, "Keep only the fields the reports use and write the detailed report row by row, for very large clusters. Memory is not flat: the listed objects are still held, trimmed, until the report is written, so peak memory still grows with the number of objects.")
+	rootCmd.Flags().IntVar(&sortBuffer, "sort-buffer", table.DefaultSortBuffer, "Rows held in memory to sort a streamed section before spilling to temporary files. 0 leaves rows unsorted.")
+	rootCmd.Flags().Float32Var(&qps, "qps", 5, "Maximum sustained rate of API requests per second. A negative value disables client-side rate limiting.")
+	rootCmd.Flags().IntVar(&burst, "burst", 10, "Maximum burst of API requests above --qps.")
+	rootCmd.Flags().DurationVar(&requestTimeout, "request-timeout", 0, "Maximum time allowed for a single API request (e.g. 30s). Zero means no limit.")
+	rootCmd.Flags().BoolVar(&watchCache, "watch-cache", false, "With --schedule, keep the cluster in an informer cache and render each run from it instead of listing everything again. Node metrics and the ConfigMap/Secret tables cannot be watched and are still listed in full on every run.")
+	rootCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "With --schedule, serve the cluster, node and namespace figures of the latest run as Prometheus metrics at /metrics on this address (e.g. ':9090').")
+	rootCmd.Flags().StringVar(&metricsFile, "metrics-textfile", "", "Write the cluster, node and namespace figures of every run as Prometheus metrics to this file, for the node_exporter textfile collector (the name must end in .prom).")
+	rootCmd.Flags().StringVar(&saveSnapshot, "save-snapshot", "", "Write everything the run collected to this file (.json, .yaml, optionally .gz) for later offline rendering.")
 }
@@ -0,0 +1,324 @@
+package report
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+// BundleKind identifies the manifest of a CSV bundle, which shares the API version of Document.
+const BundleKind = "CSVBundle"
+
+// BundleManifest is manifest.json in a CSV bundle: what the report is of and, for every CSV
+// file, the table it holds.
+type BundleManifest struct {
+	APIVersion  string       `json:"apiVersion"`
+	Kind        string       `json:"kind"`
+	Report      string       `json:"report"`
+	ClusterName string       `json:"clusterName"`
+	GeneratedAt time.Time    `json:"generatedAt"`
+	CollectedAt time.Time    `json:"collectedAt"`
+	SyncedAt    *time.Time   `json:"syncedAt,omitempty"`
+	ToolVersion string       `json:"toolVersion"`
+	Files       []BundleFile `json:"files"`
+	// Errors lists everything that could not be collected or rendered, unavailable sections
+	// included; those have no file.
+	Errors []DocumentError `json:"errors,omitempty"`
+}
+
+// BundleFile is a CSV file of a bundle. Its header row holds the keys of Columns.
+type BundleFile struct {
+	Name    string `json:"name"`
+	Section string `json:"section"`
+	// Title is the title of the table within its section, for sections with several.
+	Title   string                   `json:"title,omitempty"`
+	Rows    int                      `json:"rows"`
+	Columns []table.StructuredColumn `json:"columns"`
+	Notes   []string                 `json:"notes,omitempty"`
+}
+
+// GenerateCSVBundle writes every table of a report to its own CSV file with a single header
+// row, describes them in manifest.json, and packs everything into a tar.gz or zip archive,
+// according to archive, named after the timestamp. Collection stops when ctx is cancelled or
+// its deadline passes.
+func GenerateCSVBundle(ctx context.Context, opts Options, report section.Report, archive string) (string, string, error) {
+	if archive != "tar.gz" && archive != "zip" {
+		return "", "", fmt.Errorf("unsupported archive format %q", archive)
+	}
+	if logger != nil {
+		logger.Println("Starting CSV bundle generation...")
+	}
+
+	sections := renderable[section.TableSection](section.For(report))
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	formattedTime := currentTime.Format("02-01-2006-15-04")
+	baseName := fmt.Sprintf("kubernetes_cluster_report_%s", formattedTime)
+	outputPath := baseName + "." + archive
+
+	// The files are written to a temporary directory first: tar needs the size of every file
+	// before its content.
+	dir, err := os.MkdirTemp("", "kubereport-bundle-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create bundle directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := BundleManifest{
+		APIVersion:  DocumentAPIVersion,
+		Kind:        BundleKind,
+		Report:      string(report),
+		ClusterName: clusterName,
+		GeneratedAt: currentTime,
+		CollectedAt: snap.CollectedAt,
+		ToolVersion: opts.ToolVersion,
+		Files:       []BundleFile{},
+	}
+	if !snap.SyncedAt.IsZero() {
+		syncedAt := snap.SyncedAt
+		manifest.SyncedAt = &syncedAt
+	}
+
+	used := make(map[string]bool)
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+		bw := &bundleWriter{dir: dir, section: title, used: used, opts: opts}
+
+		var failure error
+		if err := section.ResourceErr(s, snap); err != nil {
+			failure = err
+		} else if err := results[i].Err; err != nil {
+			failure = err
+		} else if err := renderBundle(s, bw, snap, results[i].Data, opts); err != nil {
+			bw.close()
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			failure = err
+		}
+		if failure != nil {
+			// The files of a section that failed part way through are left out.
+			for _, f := range bw.files {
+				os.Remove(filepath.Join(dir, f.Name))
+			}
+			errs = append(errs, collectionError{s.Name(), failure.Error()})
+			continue
+		}
+		manifest.Files = append(manifest.Files, bw.files...)
+	}
+	for _, e := range errs {
+		manifest.Errors = append(manifest.Errors, DocumentError{Source: e.Source, Reason: e.Reason})
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode bundle manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write bundle manifest: %v", err)
+	}
+
+	names := []string{"manifest.json"}
+	for _, f := range manifest.Files {
+		names = append(names, f.Name)
+	}
+	if err := writeArchive(outputPath, archive, baseName, dir, names); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save CSV bundle: %v\n", err)
+		}
+		return "", "", fmt.Errorf("failed to save CSV bundle: %v", err)
+	}
+
+	if logger != nil {
+		logger.Println("CSV bundle generated successfully.")
+	}
+	return clusterName, outputPath, nil
+}
+
+// renderBundle writes the tables of a section to their files, a row at a time when streaming.
+func renderBundle(s section.Section, bw *bundleWriter, snap *snapshot.ClusterSnapshot, data any, opts Options) error {
+	if ss, ok := s.(section.StreamSection); ok && opts.Stream {
+		return ss.Stream(snap, data, bw, opts.SortBuffer)
+	}
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := table.Write(bw, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bundleWriter is a RowWriter that writes each table of a section to its own CSV file, named
+// after the section and, for later tables, their title.
+type bundleWriter struct {
+	dir     string
+	section string
+	used    map[string]bool
+	opts    Options
+
+	file   *os.File
+	writer *table.DataCSVWriter
+	files  []BundleFile
+}
+
+func (bw *bundleWriter) Begin(t *table.Table) error {
+	name := tableName(bw.section, t, len(bw.files) == 0, bw.used) + ".csv"
+
+	file, err := os.Create(filepath.Join(bw.dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", name, err)
+	}
+	bw.file = file
+	writer, err := newCSVWriter(file, bw.opts)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", name, err)
+	}
+	bw.writer = table.NewDataCSVWriter(writer)
+	bw.writer.Escape = !bw.opts.CSVNoEscape
+	bw.files = append(bw.files, BundleFile{Name: name, Section: bw.section, Title: t.Title})
+	return bw.writer.Begin(t)
+}
+
+func (bw *bundleWriter) Row(row table.Row) error {
+	return bw.writer.Row(row)
+}
+
+func (bw *bundleWriter) End(t *table.Table) error {
+	if err := bw.writer.End(t); err != nil {
+		bw.close()
+		return err
+	}
+	f := &bw.files[len(bw.files)-1]
+	f.Rows = bw.writer.Rows
+	f.Columns = bw.writer.Columns
+	f.Notes = bw.writer.Notes
+	return bw.close()
+}
+
+// tableName returns the name a table of a section is stored under in formats with a file or
+// table per table: the key of the section and, after its first table, of the table's title,
+// numbered if it is already in use.
+func tableName(section string, t *table.Table, first bool, used map[string]bool) string {
+	name := table.Key(section)
+	if !first {
+		if key := table.Key(t.Title); key != "" {
+			name += "_" + key
+		}
+	}
+	if name == "" {
+		name = "section"
+	}
+	base := name
+	for n := 2; used[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	used[name] = true
+	return name
+}
+
+// close closes the file of the table being written, if any.
+func (bw *bundleWriter) close() error {
+	if bw.file == nil {
+		return nil
+	}
+	err := bw.file.Close()
+	bw.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close CSV file: %v", err)
+	}
+	return nil
+}
+
+// writeArchive packs the named files of dir into a tar.gz or zip archive at path, under a
+// directory called prefix.
+func writeArchive(path, archive, prefix, dir string, names []string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if archive == "zip" {
+		zw := zip.NewWriter(out)
+		for _, name := range names {
+			w, err := zw.Create(prefix + "/" + name)
+			if err != nil {
+				return err
+			}
+			if err := copyFile(w, filepath.Join(dir, name)); err != nil {
+				return err
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		return out.Close()
+	}
+
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = prefix + "/" + name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if err := copyFile(tw, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+func TestTableName(t *testing.T) {
+	type call struct {
+		section string
+		title   string
+		first   bool
+	}
+	tests := []struct {
+		name  string
+		calls []call
+		want  []string
+	}{
+		{
+			name:  "first table takes the section key",
+			calls: []call{{section: "[ POD DETAILS ]", title: "Pods", first: true}},
+			want:  []string{"pod_details"},
+		},
+		{
+			name: "later tables add their title",
+			calls: []call{
+				{section: "[ NODE DETAILS ]", title: "Nodes", first: true},
+				{section: "[ NODE DETAILS ]", title: "Node Conditions"},
+			},
+			want: []string{"node_details", "node_details_node_conditions"},
+		},
+		{
+			name: "untitled later tables are numbered",
+			calls: []call{
+				{section: "Secrets", first: true},
+				{section: "Secrets"},
+				{section: "Secrets"},
+			},
+			want: []string{"secrets", "secrets_2", "secrets_3"},
+		},
+		{
+			name: "sections with the same key are numbered",
+			calls: []call{
+				{section: "Pod Details", first: true},
+				{section: "[ POD DETAILS ]", first: true},
+			},
+			want: []string{"pod_details", "pod_details_2"},
+		},
+		{
+			name:  "section without a key",
+			calls: []call{{section: "[ ]", first: true}, {section: "***", first: true}},
+			want:  []string{"section", "section_2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[string]bool)
+			var got []string
+			for _, c := range tt.calls {
+				got = append(got, tableName(c.section, table.New(c.title), c.first, used))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("names = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundleWriterFiles(t *testing.T) {
+	dir := t.TempDir()
+	used := make(map[string]bool)
+	for _, name := range []string{"[ NODE DETAILS ]", "Node Details"} {
+		bw := &bundleWriter{dir: dir, section: name, used: used}
+		nodes := table.New("Nodes", table.Column{Header: "NAME"})
+		nodes.AddRow("node-1")
+		conditions := table.New("Conditions", table.Column{Header: "NAME"}, table.Column{Header: "READY"})
+		conditions.AddRow("node-1", "True")
+		conditions.AddRow("node-2", "False")
+		for _, tbl := range []*table.Table{nodes, conditions} {
+			if err := table.Write(bw, tbl); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		want := map[string]int{"node_details": 1, "node_details_conditions": 2}
+		if name == "Node Details" {
+			want = map[string]int{"node_details_2": 1, "node_details_conditions_2": 2}
+		}
+		for _, f := range bw.files {
+			base := f.Name[:len(f.Name)-len(".csv")]
+			rows, ok := want[base]
+			if !ok {
+				t.Errorf("unexpected file %s", f.Name)
+				continue
+			}
+			if f.Rows != rows {
+				t.Errorf("%s has %d rows, want %d", f.Name, f.Rows, rows)
+			}
+			if _, err := os.Stat(filepath.Join(dir, f.Name)); err != nil {
+				t.Errorf("%s was not written: %v", f.Name, err)
+			}
+			delete(want, base)
+		}
+		for base := range want {
+			t.Errorf("no file %s.csv", base)
+		}
+	}
+}
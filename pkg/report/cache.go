@@ -0,0 +1,67 @@
+package report
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+)
+
+// Cache keeps the resources a report is rendered from in shared informers between runs, for
+// processes that render the same report repeatedly, such as in --schedule mode. Pass it in
+// Options.Cache so that each run renders from the warm cache instead of listing the cluster.
+type Cache struct {
+	clusterName string
+	clients     section.Clients
+	cache       *snapshot.Cache
+	stats       *requestStats
+}
+
+// NewCache connects to the cluster, starts informers for the resources the sections of the
+// given reports read, and waits for their first lists. The informers run until ctx is done.
+func NewCache(ctx context.Context, opts Options, reports ...section.Report) (*Cache, error) {
+	var sections []section.Section
+	for _, report := range reports {
+		sections = append(sections, reportSections(report)...)
+	}
+	resources, deps := requirements(sections)
+	if opts.Metrics != nil {
+		resources = addMetricsRequirements(resources, deps)
+	}
+
+	config, clusterName, err := getClientConfig(opts.Kubeconfig)
+	if err != nil {
+		if logger != nil {
+			logger.Printf("Error getting client config: %v\n", err)
+		}
+		return nil, err
+	}
+
+	stats := configureClient(config, opts, true)
+	clients, err := newClients(config, deps)
+	if err != nil {
+		if logger != nil {
+			logger.Printf("Failed to create API clients: %v\n", err)
+		}
+		return nil, err
+	}
+
+	c, err := snapshot.NewCache(clients.Core, clients.Metrics, clients.Metadata, collectOptions(opts), resources...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watch cache: %v", err)
+	}
+
+	if logger != nil {
+		logger.Println("Starting watch cache...")
+	}
+	if err := c.Start(ctx); err != nil {
+		return nil, fmt.Errorf("watch cache cancelled: %v", err)
+	}
+	if logger != nil {
+		logger.Println("Watch cache synced.")
+	}
+	logRequests(stats.counts())
+
+	return &Cache{clusterName: clusterName, clients: clients, cache: c, stats: stats}, nil
+}
@@ -0,0 +1,431 @@
+// Package chart draws the charts of PDF reports with gofpdf primitives: bars that compare
+// capacity with its use and bars that count pods. Charts are drawn at the current position
+// across the width between the page margins.
+package chart
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+const (
+	// DefaultMaxBars is the number of bars a chart shows when its MaxBars is zero. Labels beyond
+	// it are left out, or summed up in a last bar, so that clusters with hundreds of namespaces
+	// or nodes still give readable charts.
+	DefaultMaxBars = 15
+
+	titleHeight = 8.0
+	rowHeight   = 6.0
+	barHeight   = 4.0
+	legendSize  = 3.0
+	fontSize    = 8.0
+	// labelShare is the share of the chart width taken by the labels left of the bars, and
+	// valueShare that taken by the figures right of them.
+	labelShare = 0.28
+	valueShare = 0.22
+)
+
+// Colours of the bars, as RGB. The first bars of a chart take the accent colour of the PDF
+// style instead.
+var (
+	availableColor = [3]int{120, 180, 110}
+	referenceColor = [3]int{225, 228, 234}
+	seriesColors   = [][3]int{{230, 145, 56}, {150, 95, 180}}
+	textColor      = [3]int{90, 98, 117}
+)
+
+// accent returns the accent colour of the PDF style of pdf.
+func accent(pdf *table.PDF) [3]int {
+	return pdf.Style.Accent
+}
+
+// series returns the colour of the series at index i: the accent colour for the first.
+func series(pdf *table.PDF, i int) [3]int {
+	if i == 0 {
+		return accent(pdf)
+	}
+	return seriesColors[(i-1)%len(seriesColors)]
+}
+
+// Chart is a chart drawn into PDF reports.
+type Chart interface {
+	// Height returns the height the chart takes on the page.
+	Height() float64
+	// DrawPDF draws the chart at the current position, across width, and moves below it.
+	DrawPDF(pdf *table.PDF, width float64)
+}
+
+// WritePDF draws the charts one after another below the current position, each on a new page
+// when it would cross the bottom margin.
+func WritePDF(pdf *table.PDF, charts ...Chart) {
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, _, right, bottom := pdf.GetMargins()
+	for _, c := range charts {
+		pdf.Ln(5)
+		if pdf.GetY()+c.Height() > pageHeight-bottom {
+			pdf.AddPage()
+		}
+		c.DrawPDF(pdf, pageWidth-left-right)
+	}
+}
+
+// Usage is a stacked bar for each resource of how much of what the nodes can allocate is in
+// use and how much is still available.
+type Usage struct {
+	Title string
+	Bars  []UsageBar
+	// Note is printed below the bars, such as why there are no usage figures.
+	Note string
+}
+
+// UsageBar is the bar of a resource in a Usage chart. Without usage figures, HasUsage is
+// false and the bar only shows the allocatable amount. Available is negative when more than
+// the allocatable amount is in use; the bar is then drawn all in use.
+type UsageBar struct {
+	Label       string
+	Unit        string
+	Allocatable float64
+	Available   float64
+	HasUsage    bool
+}
+
+func (u Usage) Height() float64 {
+	h := titleHeight + rowHeight + float64(len(u.Bars))*rowHeight
+	if u.Note != "" {
+		h += rowHeight
+	}
+	return h
+}
+
+func (u Usage) DrawPDF(pdf *table.PDF, width float64) {
+	drawTitle(pdf, u.Title)
+	drawLegend(pdf, []string{"In use", "Available"}, [][3]int{accent(pdf), availableColor})
+
+	x, y := pdf.GetX(), pdf.GetY()
+	labelWidth, barWidth, _ := columns(width)
+	for i, bar := range u.Bars {
+		top := y + float64(i)*rowHeight
+		drawLabel(pdf, x, top, labelWidth, bar.Label)
+
+		barX := x + labelWidth
+		barY := top + (rowHeight-barHeight)/2
+		if bar.Allocatable <= 0 {
+			fill(pdf, referenceColor)
+			pdf.Rect(barX, barY, barWidth, barHeight, "F")
+			drawValue(pdf, barX+barWidth, top, "nothing allocatable")
+			continue
+		}
+		if !bar.HasUsage {
+			fill(pdf, referenceColor)
+			pdf.Rect(barX, barY, barWidth, barHeight, "F")
+			drawValue(pdf, barX+barWidth, top, fmt.Sprintf("%s allocatable", amount(bar.Allocatable, bar.Unit)))
+			continue
+		}
+
+		available := min(bar.Available, bar.Allocatable)
+		used := barWidth * (bar.Allocatable - max(available, 0)) / bar.Allocatable
+		fill(pdf, accent(pdf))
+		pdf.Rect(barX, barY, used, barHeight, "F")
+		fill(pdf, availableColor)
+		pdf.Rect(barX+used, barY, barWidth-used, barHeight, "F")
+		drawValue(pdf, barX+barWidth, top, fmt.Sprintf("%.1f%% available of %s", 100*available/bar.Allocatable, amount(bar.Allocatable, bar.Unit)))
+	}
+	pdf.SetXY(x, y+float64(len(u.Bars))*rowHeight)
+	drawNote(pdf, u.Note)
+}
+
+// Bars is a horizontal bar chart that compares several series per label with a reference,
+// such as the requests and limits of every node with what it can allocate. The reference is
+// drawn as a pale bar behind the series.
+type Bars struct {
+	Title string
+	Unit  string
+	// Reference names the reference of every group, such as "Allocatable".
+	Reference string
+	// Series names the values of every group, such as "Requests" and "Limits".
+	Series []string
+	Groups []BarGroup
+	// MaxBars is the number of groups shown, those with the largest first value relative to
+	// their reference. Zero means DefaultMaxBars and a negative value shows every group.
+	MaxBars int
+}
+
+// BarGroup is the reference and the values of a label in a Bars chart.
+type BarGroup struct {
+	Label     string
+	Reference float64
+	Values    []float64
+}
+
+// shown returns the groups to draw, in their order, and how many were left out.
+func (b Bars) shown() ([]BarGroup, int) {
+	n := limit(b.MaxBars, len(b.Groups))
+	if n == len(b.Groups) {
+		return b.Groups, 0
+	}
+	ratio := func(g BarGroup) float64 {
+		if len(g.Values) == 0 {
+			return 0
+		}
+		if g.Reference <= 0 {
+			return g.Values[0]
+		}
+		return g.Values[0] / g.Reference
+	}
+	order := make([]int, len(b.Groups))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return ratio(b.Groups[order[i]]) > ratio(b.Groups[order[j]]) })
+	order = order[:n]
+	sort.Ints(order)
+
+	groups := make([]BarGroup, n)
+	for i, j := range order {
+		groups[i] = b.Groups[j]
+	}
+	return groups, len(b.Groups) - n
+}
+
+// groupHeight returns the height of the row of a group, with a thin bar for every series.
+func (b Bars) groupHeight() float64 {
+	return max(rowHeight, 1+float64(len(b.Series))*2.5)
+}
+
+func (b Bars) Height() float64 {
+	groups, _ := b.shown()
+	// The title, the legend, the groups and the note below them.
+	return titleHeight + 2*rowHeight + float64(len(groups))*b.groupHeight()
+}
+
+func (b Bars) DrawPDF(pdf *table.PDF, width float64) {
+	groups, hidden := b.shown()
+	drawTitle(pdf, b.Title)
+	names := append([]string{b.Reference}, b.Series...)
+	colors := [][3]int{referenceColor}
+	for i := range b.Series {
+		colors = append(colors, series(pdf, i))
+	}
+	drawLegend(pdf, names, colors)
+
+	scale := 0.0
+	for _, g := range groups {
+		scale = max(scale, g.Reference)
+		for _, v := range g.Values {
+			scale = max(scale, v)
+		}
+	}
+
+	x, y := pdf.GetX(), pdf.GetY()
+	labelWidth, barWidth, _ := columns(width)
+	groupHeight := b.groupHeight()
+	thin := (groupHeight - 1) / float64(max(len(b.Series), 1))
+	for i, g := range groups {
+		top := y + float64(i)*groupHeight
+		drawLabel(pdf, x, top+(groupHeight-rowHeight)/2, labelWidth, g.Label)
+
+		barX := x + labelWidth
+		if scale > 0 {
+			fill(pdf, referenceColor)
+			pdf.Rect(barX, top+0.5, barWidth*g.Reference/scale, groupHeight-1, "F")
+			for s, v := range g.Values {
+				fill(pdf, series(pdf, s))
+				pdf.Rect(barX, top+0.5+float64(s)*thin+0.3, barWidth*v/scale, thin-0.6, "F")
+			}
+		}
+
+		var figures []string
+		for _, v := range g.Values {
+			if g.Reference > 0 {
+				figures = append(figures, fmt.Sprintf("%.0f%%", 100*v/g.Reference))
+			} else {
+				figures = append(figures, amount(v, b.Unit))
+			}
+		}
+		drawValue(pdf, barX+barWidth, top+(groupHeight-rowHeight)/2, strings.Join(figures, " / "))
+	}
+	pdf.SetXY(x, y+float64(len(groups))*groupHeight)
+
+	note := fmt.Sprintf("Figures are %s as percentages of %s.", strings.ToLower(strings.Join(b.Series, " / ")), strings.ToLower(b.Reference))
+	if hidden > 0 {
+		note = fmt.Sprintf("%d of %d shown, those with the highest %s relative to %s. %s",
+			len(groups), len(groups)+hidden, strings.ToLower(b.Series[0]), strings.ToLower(b.Reference), note)
+	}
+	drawNote(pdf, note)
+}
+
+// Counts is a horizontal bar chart of counts by label, largest first. The labels beyond
+// MaxBars are summed up in a last bar.
+type Counts struct {
+	Title string
+	// Unit names what is counted, such as "pods".
+	Unit   string
+	Labels []string
+	Values []float64
+	// Other names the labels summed up in the last bar, such as "namespaces".
+	Other string
+	// MaxBars is the number of bars, the last of which sums up the labels beyond it. Zero means
+	// DefaultMaxBars and a negative value shows every label.
+	MaxBars int
+}
+
+// bars returns the labels and values to draw, largest first.
+func (c Counts) bars() ([]string, []float64) {
+	order := make([]int, len(c.Labels))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return c.Values[order[i]] > c.Values[order[j]] })
+
+	n := limit(c.MaxBars, len(order))
+	if n < len(order) {
+		// The last bar sums up the rest.
+		n = max(n-1, 0)
+	}
+	var labels []string
+	var values []float64
+	for _, i := range order[:n] {
+		labels = append(labels, c.Labels[i])
+		values = append(values, c.Values[i])
+	}
+	if rest := order[n:]; len(rest) > 0 {
+		sum := 0.0
+		for _, i := range rest {
+			sum += c.Values[i]
+		}
+		labels = append(labels, fmt.Sprintf("Other %d %s", len(rest), c.Other))
+		values = append(values, sum)
+	}
+	return labels, values
+}
+
+func (c Counts) Height() float64 {
+	labels, _ := c.bars()
+	return titleHeight + float64(max(len(labels), 1))*rowHeight
+}
+
+func (c Counts) DrawPDF(pdf *table.PDF, width float64) {
+	labels, values := c.bars()
+	drawTitle(pdf, c.Title)
+
+	x, y := pdf.GetX(), pdf.GetY()
+	if len(labels) == 0 {
+		drawNote(pdf, fmt.Sprintf("No %s.", c.Unit))
+		return
+	}
+
+	scale := 0.0
+	for _, v := range values {
+		scale = max(scale, v)
+	}
+	labelWidth, barWidth, _ := columns(width)
+	for i, label := range labels {
+		top := y + float64(i)*rowHeight
+		drawLabel(pdf, x, top, labelWidth, label)
+		if scale > 0 {
+			color := accent(pdf)
+			if i == len(labels)-1 && len(labels) < len(c.Labels) {
+				color = referenceColor
+			}
+			fill(pdf, color)
+			pdf.Rect(x+labelWidth, top+(rowHeight-barHeight)/2, barWidth*values[i]/scale, barHeight, "F")
+		}
+		drawValue(pdf, x+labelWidth+barWidth, top, amount(values[i], c.Unit))
+	}
+	pdf.SetXY(x, y+float64(len(labels))*rowHeight)
+}
+
+// columns splits the chart width between the labels, the bars and the figures.
+func columns(width float64) (label, bar, value float64) {
+	label = width * labelShare
+	value = width * valueShare
+	return label, width - label - value, value
+}
+
+// limit returns how many of n items a chart with the given maximum shows.
+func limit(maxBars, n int) int {
+	switch {
+	case maxBars < 0:
+		return n
+	case maxBars == 0:
+		maxBars = DefaultMaxBars
+	}
+	return min(maxBars, n)
+}
+
+func drawTitle(pdf *table.PDF, title string) {
+	table.SetPDFFont(pdf, "B", 10)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(0, titleHeight, table.PDFText(pdf, title), "", 1, "L", false, 0, "")
+}
+
+// drawLegend draws a row of coloured squares, each followed by its name.
+func drawLegend(pdf *table.PDF, names []string, colors [][3]int) {
+	left, y := pdf.GetX(), pdf.GetY()
+	x := left
+	table.SetPDFFont(pdf, "", fontSize)
+	pdf.SetTextColor(textColor[0], textColor[1], textColor[2])
+	for i, name := range names {
+		fill(pdf, colors[i%len(colors)])
+		pdf.Rect(x, y+(rowHeight-legendSize)/2, legendSize, legendSize, "F")
+		pdf.SetXY(x+legendSize+1, y)
+		name = table.PDFText(pdf, name)
+		pdf.CellFormat(pdf.GetStringWidth(name)+2, rowHeight, name, "", 0, "L", false, 0, "")
+		x = pdf.GetX() + 4
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetXY(left, y+rowHeight)
+}
+
+// drawLabel draws the label of a bar, right-aligned against the bar and cut to fit width.
+func drawLabel(pdf *table.PDF, x, y, width float64, label string) {
+	table.SetPDFFont(pdf, "", fontSize)
+	pdf.SetTextColor(0, 0, 0)
+	label = fit(pdf, table.PDFText(pdf, label), width-2)
+	pdf.SetXY(x, y)
+	pdf.CellFormat(width-2, rowHeight, label, "", 0, "R", false, 0, "")
+}
+
+// drawValue draws the figures of a bar right of it.
+func drawValue(pdf *table.PDF, x, y float64, value string) {
+	table.SetPDFFont(pdf, "", fontSize)
+	pdf.SetTextColor(textColor[0], textColor[1], textColor[2])
+	pdf.SetXY(x+2, y)
+	pdf.CellFormat(0, rowHeight, table.PDFText(pdf, value), "", 0, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// drawNote draws a line of small italic text below a chart.
+func drawNote(pdf *table.PDF, note string) {
+	if note == "" {
+		return
+	}
+	table.SetPDFFont(pdf, "I", fontSize)
+	pdf.SetTextColor(textColor[0], textColor[1], textColor[2])
+	pdf.CellFormat(0, rowHeight, table.PDFText(pdf, note), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// fit cuts text to width, ending it with dots if it was cut.
+func fit(pdf *table.PDF, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+func fill(pdf *table.PDF, color [3]int) {
+	pdf.SetFillColor(color[0], color[1], color[2])
+}
+
+// amount formats a figure with its unit, without decimals.
+func amount(v float64, unit string) string {
+	return strings.TrimSpace(fmt.Sprintf("%.0f %s", v, unit))
+}
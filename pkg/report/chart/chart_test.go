@@ -0,0 +1,164 @@
+package chart
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf/v2"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		maxBars, n, want int
+	}{
+		{maxBars: 0, n: 3, want: 3},
+		{maxBars: 0, n: 40, want: DefaultMaxBars},
+		{maxBars: 5, n: 40, want: 5},
+		{maxBars: 5, n: 2, want: 2},
+		{maxBars: -1, n: 40, want: 40},
+	}
+	for _, tt := range tests {
+		if got := limit(tt.maxBars, tt.n); got != tt.want {
+			t.Errorf("limit(%d, %d) = %d, want %d", tt.maxBars, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestCountsBars(t *testing.T) {
+	tests := []struct {
+		name       string
+		counts     Counts
+		wantLabels []string
+		wantValues []float64
+	}{
+		{
+			name:       "largest first",
+			counts:     Counts{Labels: []string{"a", "b", "c"}, Values: []float64{1, 3, 2}},
+			wantLabels: []string{"b", "c", "a"},
+			wantValues: []float64{3, 2, 1},
+		},
+		{
+			name:       "ties keep their order",
+			counts:     Counts{Labels: []string{"a", "b", "c"}, Values: []float64{2, 2, 2}},
+			wantLabels: []string{"a", "b", "c"},
+			wantValues: []float64{2, 2, 2},
+		},
+		{
+			name:       "rest summed in the last bar",
+			counts:     Counts{Labels: []string{"a", "b", "c", "d"}, Values: []float64{4, 1, 3, 2}, Other: "namespaces", MaxBars: 3},
+			wantLabels: []string{"a", "c", "Other 2 namespaces"},
+			wantValues: []float64{4, 3, 3},
+		},
+		{
+			name:       "exactly the limit has no Other bar",
+			counts:     Counts{Labels: []string{"a", "b", "c"}, Values: []float64{1, 2, 3}, MaxBars: 3},
+			wantLabels: []string{"c", "b", "a"},
+			wantValues: []float64{3, 2, 1},
+		},
+		{
+			name:       "a single bar sums up everything",
+			counts:     Counts{Labels: []string{"a", "b"}, Values: []float64{1, 2}, Other: "nodes", MaxBars: 1},
+			wantLabels: []string{"Other 2 nodes"},
+			wantValues: []float64{3},
+		},
+		{
+			name:       "negative shows every label",
+			counts:     Counts{Labels: labels(20), Values: make([]float64, 20), MaxBars: -1},
+			wantLabels: labels(20),
+			wantValues: make([]float64, 20),
+		},
+		{name: "empty", counts: Counts{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotLabels, gotValues := tt.counts.bars()
+			if !reflect.DeepEqual(gotLabels, tt.wantLabels) {
+				t.Errorf("labels = %q, want %q", gotLabels, tt.wantLabels)
+			}
+			if !reflect.DeepEqual(gotValues, tt.wantValues) {
+				t.Errorf("values = %v, want %v", gotValues, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestCountsDefaultLimit(t *testing.T) {
+	values := make([]float64, 20)
+	for i := range values {
+		values[i] = 1
+	}
+	labels, sums := Counts{Labels: labels(20), Values: values, Other: "namespaces"}.bars()
+	if len(labels) != DefaultMaxBars {
+		t.Fatalf("%d bars, want %d", len(labels), DefaultMaxBars)
+	}
+	if got, want := labels[len(labels)-1], "Other 6 namespaces"; got != want {
+		t.Errorf("last bar = %q, want %q", got, want)
+	}
+	if got := sums[len(sums)-1]; got != 6 {
+		t.Errorf("last bar sums %v, want 6", got)
+	}
+}
+
+func TestBarsShown(t *testing.T) {
+	groups := []BarGroup{
+		{Label: "node-1", Reference: 10, Values: []float64{2}},
+		{Label: "node-2", Reference: 10, Values: []float64{9}},
+		{Label: "node-3", Reference: 0, Values: []float64{0.5}},
+		{Label: "node-4", Reference: 4, Values: []float64{3}},
+	}
+	tests := []struct {
+		name       string
+		maxBars    int
+		wantLabels []string
+		wantHidden int
+	}{
+		{name: "all fit", maxBars: 0, wantLabels: []string{"node-1", "node-2", "node-3", "node-4"}},
+		{name: "fullest kept in their order", maxBars: 2, wantLabels: []string{"node-2", "node-4"}, wantHidden: 2},
+		{name: "no reference compares the value", maxBars: 3, wantLabels: []string{"node-2", "node-3", "node-4"}, wantHidden: 1},
+		{name: "negative shows every group", maxBars: -1, wantLabels: []string{"node-1", "node-2", "node-3", "node-4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shown, hidden := Bars{Groups: groups, MaxBars: tt.maxBars}.shown()
+			var got []string
+			for _, g := range shown {
+				got = append(got, g.Label)
+			}
+			if !reflect.DeepEqual(got, tt.wantLabels) || hidden != tt.wantHidden {
+				t.Errorf("shown %q, %d hidden; want %q, %d hidden", got, hidden, tt.wantLabels, tt.wantHidden)
+			}
+		})
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	pdf := table.NewPDF(gofpdf.New("P", "mm", "A4", ""), table.PDFStyle{Font: "Arial", Accent: [3]int{52, 120, 195}})
+	pdf.AddPage()
+	WritePDF(pdf,
+		Usage{Title: "Cluster usage", Bars: []UsageBar{
+			{Label: "CPU", Unit: "cores", Allocatable: 8, Available: 3, HasUsage: true},
+			{Label: "Memory", Unit: "GiB", Allocatable: 32, Available: -1, HasUsage: true},
+		}},
+		Usage{Title: "No metrics", Bars: []UsageBar{{Label: "CPU", Unit: "cores", Allocatable: 8}}, Note: "No usage figures."},
+		Bars{Title: "Requests", Unit: "cores", Reference: "Allocatable", Series: []string{"Requests", "Limits"}, Groups: []BarGroup{
+			{Label: "node-1", Reference: 4, Values: []float64{1, 2}},
+		}},
+		Counts{Title: "Pods per namespace", Unit: "pods", Labels: labels(30), Values: make([]float64, 30), Other: "namespaces"},
+		Counts{Title: "Empty", Unit: "pods"},
+	)
+	if err := pdf.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// labels returns n distinct labels.
+func labels(n int) []string {
+	ls := make([]string, n)
+	for i := range ls {
+		ls[i] = fmt.Sprintf("label-%02d", i)
+	}
+	return ls
+}
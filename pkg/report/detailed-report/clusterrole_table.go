@@ -1,16 +1,23 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 260, section.Func{
+		Title: "[ CLUSTERROLE DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.ClusterRoles},
+		Build: GenerateClusterRoleReport,
+	})
+}
+
 // ClusterRoleInfo holds the information for a Kubernetes ClusterRole.
 type ClusterRoleInfo struct {
 	Name        string
@@ -18,22 +25,16 @@ type ClusterRoleInfo struct {
 	APIGroups   string
 	Resources   string
 	Verbs       string
-	Age         string
+	Age         time.Duration
 	Annotations string
 }
 
-// Generates a CSV report of Kubernetes ClusterRoles.
-func GenerateClusterRoleReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch ClusterRoles from all namespaces
-	clusterRoles, err := clientset.RbacV1().ClusterRoles().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching ClusterRoles: %v", err)
-	}
-
+// Generates a report of Kubernetes ClusterRoles.
+func GenerateClusterRoleReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var clusterRoleData []ClusterRoleInfo
 
-	for _, clusterRole := range clusterRoles.Items {
-		age := time.Since(clusterRole.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, clusterRole := range snap.ClusterRoles {
+		age := time.Since(clusterRole.CreationTimestamp.Time)
 
 		// Rules - Simplified for display
 		var rules []string
@@ -66,22 +67,17 @@ func GenerateClusterRoleReportCSV(writer *csv.Writer, clientset *kubernetes.Clie
 		clusterRoleData = append(clusterRoleData, clusterRoleInfo)
 	}
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"CLUSTERROLE NAME",
-		"RULES",
-		"API GROUPS",
-		"RESOURCES",
-		"VERBS",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write ClusterRole data rows
+	t := table.New("",
+		table.Column{Header: "CLUSTERROLE NAME"},
+		table.Column{Header: "RULES"},
+		table.Column{Header: "API GROUPS"},
+		table.Column{Header: "RESOURCES"},
+		table.Column{Header: "VERBS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, clusterRole := range clusterRoleData {
-		record := []string{
+		t.AddRow(
 			clusterRole.Name,
 			clusterRole.Rules,
 			clusterRole.APIGroups,
@@ -89,17 +85,8 @@ func GenerateClusterRoleReportCSV(writer *csv.Writer, clientset *kubernetes.Clie
 			clusterRole.Verbs,
 			clusterRole.Age,
 			clusterRole.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
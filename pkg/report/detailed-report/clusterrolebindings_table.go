@@ -1,16 +1,23 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 270, section.Func{
+		Title: "[ CLUSTERROLEBINDING DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.ClusterRoleBindings},
+		Build: GenerateClusterRoleBindingReport,
+	})
+}
+
 // ClusterRoleBindingInfo holds the information for a Kubernetes ClusterRoleBinding.
 type ClusterRoleBindingInfo struct {
 	Name            string
@@ -19,22 +26,16 @@ type ClusterRoleBindingInfo struct {
 	RoleRefAPIGroup string
 	RoleRefKind     string
 	RoleRefName     string
-	Age             string
+	Age             time.Duration
 	Annotations     string
 }
 
-// Generates a CSV report of Kubernetes ClusterRoleBindings.
-func GenerateClusterRoleBindingReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch ClusterRoleBindings from all namespaces
-	clusterRoleBindings, err := clientset.RbacV1().ClusterRoleBindings().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching ClusterRoleBindings: %v", err)
-	}
-
+// Generates a report of Kubernetes ClusterRoleBindings.
+func GenerateClusterRoleBindingReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var clusterRoleBindingData []ClusterRoleBindingInfo
 
-	for _, binding := range clusterRoleBindings.Items {
-		age := time.Since(binding.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, binding := range snap.ClusterRoleBindings {
+		age := time.Since(binding.CreationTimestamp.Time)
 
 		var subjects []string
 		for _, subject := range binding.Subjects {
@@ -64,23 +65,18 @@ func GenerateClusterRoleBindingReportCSV(writer *csv.Writer, clientset *kubernet
 		clusterRoleBindingData = append(clusterRoleBindingData, clusterRoleBindingInfo)
 	}
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"CLUSTERROLEBINDING NAME",
-		"CLUSTERROLE NAME",
-		"SUBJECTS",
-		"ROLEREF API GROUP",
-		"ROLEREF KIND",
-		"ROLEREF NAME",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write ClusterRoleBinding data rows
+	t := table.New("",
+		table.Column{Header: "CLUSTERROLEBINDING NAME"},
+		table.Column{Header: "CLUSTERROLE NAME"},
+		table.Column{Header: "SUBJECTS"},
+		table.Column{Header: "ROLEREF API GROUP"},
+		table.Column{Header: "ROLEREF KIND"},
+		table.Column{Header: "ROLEREF NAME"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, binding := range clusterRoleBindingData {
-		record := []string{
+		t.AddRow(
 			binding.Name,
 			binding.ClusterRoleName,
 			binding.Subjects,
@@ -89,17 +85,8 @@ func GenerateClusterRoleBindingReportCSV(writer *csv.Writer, clientset *kubernet
 			binding.RoleRefName,
 			binding.Age,
 			binding.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
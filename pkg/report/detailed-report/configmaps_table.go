@@ -1,91 +1,57 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
-	"sort"
-	"strconv"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
-// ConfigMapInfo holds the information for a Kubernetes ConfigMap.
-type ConfigMapInfo struct {
-	Name      string
-	Namespace string
-	DataItems int
-	Age       string
-	Labels    string
+// configMapDetails lists every ConfigMap by name.
+var configMapDetails = section.Rows{
+	Title: "[ CONFIGMAP DETAILS ]",
+	// Only the number of data items is shown, so their contents are never downloaded.
+	Reads: []snapshot.Resource{snapshot.MetadataOf(snapshot.ConfigMaps)},
+	Columns: []table.Column{
+		{Header: "CONFIGMAP NAME"},
+		{Header: "NAMESPACE"},
+		{Header: "DATA ITEMS", Kind: table.Integer},
+		{Header: "AGE", Kind: table.Duration},
+		{Header: "LABELS"},
+	},
+	Order: table.ByColumn(0, false),
+	Each:  configMapRows,
 }
 
-// Generates a CSV report of Kubernetes ConfigMaps.
-func GenerateConfigMapReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch ConfigMaps
-	configMaps, err := clientset.CoreV1().ConfigMaps(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching ConfigMaps: %v", err)
-	}
+func init() {
+	section.Register(section.Detailed, 110, configMapDetails)
+}
 
-	var configMapData []ConfigMapInfo
+// Generates a report of Kubernetes ConfigMaps.
+func GenerateConfigMapReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+	return configMapDetails.Tables(snap, nil)
+}
 
+func configMapRows(snap *snapshot.ClusterSnapshot, emit func(values ...any) error) error {
 	// Iterate over ConfigMaps to get their information
-	for _, cm := range configMaps.Items {
-		age := time.Since(cm.CreationTimestamp.Time).Round(time.Hour).String()
-
-		dataItems := len(cm.Data)
-
+	for _, cm := range snap.Metadata(snapshot.ConfigMaps) {
 		labels := ""
 		if len(cm.Labels) > 0 {
 			labels = fmt.Sprintf("%v", cm.Labels)
 		}
 
-		// Create a record for the ConfigMap
-		configMapData = append(configMapData, ConfigMapInfo{
-			Name:      cm.Name,
-			Namespace: cm.Namespace,
-			DataItems: dataItems,
-			Age:       age,
-			Labels:    labels,
-		})
-	}
-
-	sort.Slice(configMapData, func(i, j int) bool {
-		return configMapData[i].Name < configMapData[j].Name
-	})
-
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"CONFIGMAP NAME",
-		"NAMESPACE",
-		"DATA ITEMS",
-		"AGE",
-		"LABELS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write ConfigMap data rows
-	for _, cm := range configMapData {
-		record := []string{
+		err := emit(
 			cm.Name,
 			cm.Namespace,
-			strconv.Itoa(cm.DataItems),
-			cm.Age,
-			cm.Labels,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
+			cm.DataItems,
+			time.Since(cm.CreationTimestamp.Time),
+			labels,
+		)
+		if err != nil {
+			return err
 		}
 	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
-	}
-
 	return nil
 }
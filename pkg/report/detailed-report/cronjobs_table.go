@@ -1,56 +1,56 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 230, section.Func{
+		Title: "[ CRONJOB DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.CronJobs},
+		Build: GenerateCronJobReport,
+	})
+}
+
 // CronJobInfo holds the information for a Kubernetes CronJob.
 type CronJobInfo struct {
-	Name              string
-	Namespace         string
-	Schedule          string
-	ActiveJobs        int32
-	LastSchedule      string
-	Age               string
-	JobDuration       string
+	Name       string
+	Namespace  string
+	Schedule   string
+	ActiveJobs int32
+	// LastSchedule is the time since the last job was scheduled, nil if none was.
+	LastSchedule *time.Duration
+	Age          time.Duration
+	// JobDuration is the time since the last successful job, nil if none succeeded.
+	JobDuration       *time.Duration
 	JobTemplate       string
 	HistoryLimit      int32
 	ConcurrencyPolicy string
 }
 
-// Generates a CSV report of Kubernetes CronJobs.
-func GenerateCronJobReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch CronJobs
-	cronJobs, err := clientset.BatchV1().CronJobs("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching CronJobs: %v", err)
-	}
-
+// Generates a report of Kubernetes CronJobs.
+func GenerateCronJobReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var cronJobData []CronJobInfo
 
-	for _, cronJob := range cronJobs.Items {
-		age := time.Since(cronJob.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, cronJob := range snap.CronJobs {
+		age := time.Since(cronJob.CreationTimestamp.Time)
 
-		var lastSchedule string
+		var lastSchedule *time.Duration
 		if cronJob.Status.LastScheduleTime != nil {
-			lastSchedule = time.Since(cronJob.Status.LastScheduleTime.Time).Round(time.Hour).String()
-		} else {
-			lastSchedule = "N/A"
+			since := time.Since(cronJob.Status.LastScheduleTime.Time)
+			lastSchedule = &since
 		}
 
-		var jobDuration string
+		var jobDuration *time.Duration
 		if cronJob.Status.LastSuccessfulTime != nil {
-			duration := time.Since(cronJob.Status.LastSuccessfulTime.Time).Round(time.Hour)
-			jobDuration = duration.String()
-		} else {
-			jobDuration = "N/A"
+			since := time.Since(cronJob.Status.LastSuccessfulTime.Time)
+			jobDuration = &since
 		}
 
 		jobTemplate := fmt.Sprintf("%s/%s", cronJob.Spec.JobTemplate.Name, cronJob.Spec.JobTemplate.Namespace)
@@ -80,42 +80,32 @@ func GenerateCronJobReportCSV(writer *csv.Writer, clientset *kubernetes.Clientse
 	sort.Slice(cronJobData, func(i, j int) bool {
 		return cronJobData[i].Name < cronJobData[j].Name
 	})
-
-	if err := writer.Write([]string{
-		"CRONJOB NAME",
-		"NAMESPACE",
-		"SCHEDULE",
-		"ACTIVE JOBS",
-		"LAST SCHEDULE",
-		"AGE",
-		"JOB DURATION",
-		"JOB TEMPLATE",
-		"HISTORY LIMIT",
-		"CONCURRENCY POLICY",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
+	t := table.New("",
+		table.Column{Header: "CRONJOB NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "SCHEDULE"},
+		table.Column{Header: "ACTIVE JOBS", Kind: table.Integer},
+		table.Column{Header: "LAST SCHEDULE", Kind: table.Duration},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "JOB DURATION", Kind: table.Duration},
+		table.Column{Header: "JOB TEMPLATE"},
+		table.Column{Header: "HISTORY LIMIT", Kind: table.Integer},
+		table.Column{Header: "CONCURRENCY POLICY"},
+	)
 	for _, cronJob := range cronJobData {
-		record := []string{
+		t.AddRow(
 			cronJob.Name,
 			cronJob.Namespace,
 			cronJob.Schedule,
-			fmt.Sprintf("%d", cronJob.ActiveJobs),
-			cronJob.LastSchedule,
+			cronJob.ActiveJobs,
+			table.Deref(cronJob.LastSchedule),
 			cronJob.Age,
-			cronJob.JobDuration,
+			table.Deref(cronJob.JobDuration),
 			cronJob.JobTemplate,
-			fmt.Sprintf("%d", cronJob.HistoryLimit),
+			cronJob.HistoryLimit,
 			cronJob.ConcurrencyPolicy,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
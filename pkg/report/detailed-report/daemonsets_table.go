@@ -1,18 +1,24 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 100, section.Func{
+		Title: "[ DAEMONSETS DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.DaemonSets},
+		Build: GenerateDaemonSetReport,
+	})
+}
+
 // DaemonSetInfo holds the information for a Kubernetes DaemonSet.
 type DaemonSetInfo struct {
 	Name         string
@@ -22,22 +28,16 @@ type DaemonSetInfo struct {
 	PodsReady    int32
 	PodsDesired  int32
 	NodeSelector string
-	Age          string
+	Age          time.Duration
 	Conditions   string
 }
 
-// Generates a CSV report of Kubernetes DaemonSets.
-func GenerateDaemonSetReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch DaemonSets
-	daemonSets, err := clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching DaemonSets: %v", err)
-	}
-
+// Generates a report of Kubernetes DaemonSets.
+func GenerateDaemonSetReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var daemonSetData []DaemonSetInfo
 
-	for _, ds := range daemonSets.Items {
-		age := time.Since(ds.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, ds := range snap.DaemonSets {
+		age := time.Since(ds.CreationTimestamp.Time)
 
 		var conditions []string
 		for _, cond := range ds.Status.Conditions {
@@ -68,44 +68,30 @@ func GenerateDaemonSetReportCSV(writer *csv.Writer, clientset *kubernetes.Client
 		return daemonSetData[i].Name < daemonSetData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"DAEMONSET NAME",
-		"NAMESPACE",
-		"DESIRED PODS",
-		"CURRENT PODS",
-		"PODS READY",
-		"PODS DESIRED",
-		"NODE SELECTOR",
-		"AGE",
-		"CONDITIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write DaemonSet data rows
+	t := table.New("",
+		table.Column{Header: "DAEMONSET NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "DESIRED PODS", Kind: table.Integer},
+		table.Column{Header: "CURRENT PODS", Kind: table.Integer},
+		table.Column{Header: "PODS READY", Kind: table.Integer},
+		table.Column{Header: "PODS DESIRED", Kind: table.Integer},
+		table.Column{Header: "NODE SELECTOR"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "CONDITIONS"},
+	)
 	for _, ds := range daemonSetData {
-		record := []string{
+		t.AddRow(
 			ds.Name,
 			ds.Namespace,
-			strconv.Itoa(int(ds.DesiredPods)),
-			strconv.Itoa(int(ds.CurrentPods)),
-			strconv.Itoa(int(ds.PodsReady)),
-			strconv.Itoa(int(ds.PodsDesired)),
+			ds.DesiredPods,
+			ds.CurrentPods,
+			ds.PodsReady,
+			ds.PodsDesired,
 			ds.NodeSelector,
 			ds.Age,
 			ds.Conditions,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
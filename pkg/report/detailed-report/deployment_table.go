@@ -1,44 +1,44 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 50, section.Func{
+		Title: "[ DEPLOYMENT DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Deployments},
+		Build: GenerateDeploymentReport,
+	})
+}
+
 // DeploymentInfo holds the information for a Kubernetes deployment.
 type DeploymentInfo struct {
 	Name              string
 	Namespace         string
-	Replicas          int32
+	Replicas          *int32
 	AvailableReplicas int32
 	PodsReady         int32
 	PodsDesired       int32
 	StrategyType      string
-	Age               string
+	Age               time.Duration
 	Conditions        string
 	Revision          int64
 }
 
-// Generates a CSV report of Kubernetes deployments.
-func GenerateDeploymentReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch deployments
-	deployments, err := clientset.AppsV1().Deployments(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching deployments: %v", err)
-	}
-
+// Generates a report of Kubernetes deployments.
+func GenerateDeploymentReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var deploymentData []DeploymentInfo
 
-	for _, deploy := range deployments.Items {
-		age := time.Since(deploy.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, deploy := range snap.Deployments {
+		age := time.Since(deploy.CreationTimestamp.Time)
 
 		var conditions []string
 		for _, cond := range deploy.Status.Conditions {
@@ -49,7 +49,7 @@ func GenerateDeploymentReportCSV(writer *csv.Writer, clientset *kubernetes.Clien
 		deploymentData = append(deploymentData, DeploymentInfo{
 			Name:              deploy.Name,
 			Namespace:         deploy.Namespace,
-			Replicas:          *deploy.Spec.Replicas,
+			Replicas:          deploy.Spec.Replicas,
 			AvailableReplicas: deploy.Status.AvailableReplicas,
 			PodsReady:         deploy.Status.ReadyReplicas,
 			PodsDesired:       deploy.Status.Replicas,
@@ -64,46 +64,32 @@ func GenerateDeploymentReportCSV(writer *csv.Writer, clientset *kubernetes.Clien
 		return deploymentData[i].Name < deploymentData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"DEPLOYMENT NAME",
-		"NAMESPACE",
-		"REPLICAS",
-		"AVAILABLE REPLICAS",
-		"PODS READY",
-		"PODS DESIRED",
-		"STRATEGY TYPE",
-		"REVISION",
-		"AGE",
-		"CONDITIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write deployment data rows
+	t := table.New("",
+		table.Column{Header: "DEPLOYMENT NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "REPLICAS", Kind: table.Integer},
+		table.Column{Header: "AVAILABLE REPLICAS", Kind: table.Integer},
+		table.Column{Header: "PODS READY", Kind: table.Integer},
+		table.Column{Header: "PODS DESIRED", Kind: table.Integer},
+		table.Column{Header: "STRATEGY TYPE"},
+		table.Column{Header: "REVISION", Kind: table.Integer},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "CONDITIONS"},
+	)
 	for _, deploy := range deploymentData {
-		record := []string{
+		t.AddRow(
 			deploy.Name,
 			deploy.Namespace,
-			strconv.Itoa(int(deploy.Replicas)),
-			strconv.Itoa(int(deploy.AvailableReplicas)),
-			strconv.Itoa(int(deploy.PodsReady)),
-			strconv.Itoa(int(deploy.PodsDesired)),
+			table.Deref(deploy.Replicas),
+			deploy.AvailableReplicas,
+			deploy.PodsReady,
+			deploy.PodsDesired,
 			deploy.StrategyType,
-			strconv.FormatInt(deploy.Revision, 10),
+			deploy.Revision,
 			deploy.Age,
 			deploy.Conditions,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,16 +1,23 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 70, section.Func{
+		Title: "[ ENDPOINTS DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Endpoints},
+		Build: GenerateEndpointsReport,
+	})
+}
+
 // EndpointInfo holds the information for a Kubernetes Endpoint.
 type EndpointInfo struct {
 	Name        string
@@ -18,21 +25,15 @@ type EndpointInfo struct {
 	Subsets     int
 	IPAddresses string
 	Ports       string
-	Age         string
+	Age         time.Duration
 }
 
-// Generates a CSV report of Kubernetes Endpoints.
-func GenerateEndpointsReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Endpoints from all namespaces
-	endpointsList, err := clientset.CoreV1().Endpoints("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Endpoints: %v", err)
-	}
-
+// Generates a report of Kubernetes Endpoints.
+func GenerateEndpointsReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var endpointData []EndpointInfo
 
-	for _, endpoint := range endpointsList.Items {
-		age := time.Since(endpoint.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, endpoint := range snap.Endpoints {
+		age := time.Since(endpoint.CreationTimestamp.Time)
 
 		subsetsCount := len(endpoint.Subsets)
 
@@ -63,38 +64,24 @@ func GenerateEndpointsReportCSV(writer *csv.Writer, clientset *kubernetes.Client
 		endpointData = append(endpointData, endpointInfo)
 	}
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"ENDPOINT NAME",
-		"NAMESPACE",
-		"SUBSETS",
-		"IP ADDRESSES",
-		"PORTS",
-		"AGE",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Endpoint data rows
+	t := table.New("",
+		table.Column{Header: "ENDPOINT NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "SUBSETS", Kind: table.Integer},
+		table.Column{Header: "IP ADDRESSES"},
+		table.Column{Header: "PORTS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+	)
 	for _, ep := range endpointData {
-		record := []string{
+		t.AddRow(
 			ep.Name,
 			ep.Namespace,
-			fmt.Sprintf("%d", ep.Subsets),
+			ep.Subsets,
 			ep.IPAddresses,
 			ep.Ports,
 			ep.Age,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,45 +1,47 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 210, section.Func{
+		Title: "[ HORIZONTAL POD AUTOSCALERS DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.HorizontalPodAutoscalers},
+		Build: GenerateHPAReport,
+	})
+}
+
 // HPAInfo holds the information for a Kubernetes Horizontal Pod Autoscaler.
 type HPAInfo struct {
 	Name                  string
 	Namespace             string
 	ScaleTargetRef        string
-	MinReplicas           int32
+	MinReplicas           *int32
 	MaxReplicas           int32
 	TargetCPUUtilization  *int32
 	CurrentReplicas       int32
-	Age                   string
+	Age                   time.Duration
 	Conditions            string
 	Metrics               string
 	CurrentCPUUtilization string
-	LastScaleTime         string
-	Behavior              string
+	// LastScale is the time since the autoscaler last scaled its target, nil if it never has.
+	LastScale *time.Duration
+	Behavior  string
 }
 
-// Generates a CSV report of Kubernetes Horizontal Pod Autoscalers.
-func GenerateHPAReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Horizontal Pod Autoscalers
-	hpas, err := clientset.AutoscalingV1().HorizontalPodAutoscalers("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Horizontal Pod Autoscalers: %v", err)
-	}
-
+// Generates a report of Kubernetes Horizontal Pod Autoscalers.
+func GenerateHPAReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var hpaData []HPAInfo
 
-	for _, hpa := range hpas.Items {
-		age := time.Since(hpa.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, hpa := range snap.HorizontalPodAutoscalers {
+		age := time.Since(hpa.CreationTimestamp.Time)
 
 		scaleTargetRef := fmt.Sprintf("%s/%s", hpa.Spec.ScaleTargetRef.Kind, hpa.Spec.ScaleTargetRef.Name)
 
@@ -47,9 +49,10 @@ func GenerateHPAReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) e
 		metrics := "N/A" // Currently not supported in autoscaling/v1
 		currentCPUUtilization := "N/A"
 
-		lastScaleTime := "N/A"
+		var lastScale *time.Duration
 		if hpa.Status.LastScaleTime != nil {
-			lastScaleTime = hpa.Status.LastScaleTime.String()
+			since := time.Since(hpa.Status.LastScaleTime.Time)
+			lastScale = &since
 		}
 
 		behavior := "N/A" // Can be implemented based on the HPA's behavior configuration
@@ -59,7 +62,7 @@ func GenerateHPAReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) e
 			Name:                  hpa.Name,
 			Namespace:             hpa.Namespace,
 			ScaleTargetRef:        scaleTargetRef,
-			MinReplicas:           *hpa.Spec.MinReplicas, // Ensure it's not nil before dereferencing
+			MinReplicas:           hpa.Spec.MinReplicas,
 			MaxReplicas:           hpa.Spec.MaxReplicas,
 			TargetCPUUtilization:  hpa.Spec.TargetCPUUtilizationPercentage,
 			CurrentReplicas:       hpa.Status.CurrentReplicas,
@@ -67,7 +70,7 @@ func GenerateHPAReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) e
 			Conditions:            "AbleToScale",
 			Metrics:               metrics,
 			CurrentCPUUtilization: currentCPUUtilization,
-			LastScaleTime:         lastScaleTime,
+			LastScale:             lastScale,
 			Behavior:              behavior,
 		}
 
@@ -78,52 +81,38 @@ func GenerateHPAReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) e
 		return hpaData[i].Name < hpaData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"HPA NAME",
-		"NAMESPACE",
-		"SCALE TARGET REF",
-		"MIN REPLICAS",
-		"MAX REPLICAS",
-		"TARGET CPU UTILIZATION",
-		"CURRENT REPLICAS",
-		"AGE",
-		"CONDITIONS",
-		"METRICS",
-		"CURRENT CPU UTILIZATION",
-		"LAST SCALE TIME",
-		"BEHAVIOR",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write HPA data rows
+	t := table.New("",
+		table.Column{Header: "HPA NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "SCALE TARGET REF"},
+		table.Column{Header: "MIN REPLICAS", Kind: table.Integer},
+		table.Column{Header: "MAX REPLICAS", Kind: table.Integer},
+		table.Column{Header: "TARGET CPU UTILIZATION", Kind: table.Integer},
+		table.Column{Header: "CURRENT REPLICAS", Kind: table.Integer},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "CONDITIONS"},
+		table.Column{Header: "METRICS"},
+		table.Column{Header: "CURRENT CPU UTILIZATION"},
+		table.Column{Header: "LAST SCALE", Kind: table.Duration},
+		table.Column{Header: "BEHAVIOR"},
+	)
 	for _, hpa := range hpaData {
-		record := []string{
+		t.AddRow(
 			hpa.Name,
 			hpa.Namespace,
 			hpa.ScaleTargetRef,
-			fmt.Sprintf("%d", hpa.MinReplicas),
-			fmt.Sprintf("%d", hpa.MaxReplicas),
-			fmt.Sprintf("%d", *hpa.TargetCPUUtilization), // Ensure it's not nil before dereferencing
-			fmt.Sprintf("%d", hpa.CurrentReplicas),
+			table.Deref(hpa.MinReplicas),
+			hpa.MaxReplicas,
+			table.Deref(hpa.TargetCPUUtilization),
+			hpa.CurrentReplicas,
 			hpa.Age,
 			hpa.Conditions,
 			hpa.Metrics,
 			hpa.CurrentCPUUtilization,
-			hpa.LastScaleTime,
+			table.Deref(hpa.LastScale),
 			hpa.Behavior,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,16 +1,23 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 170, section.Func{
+		Title: "[ INGRESS RESOURCES DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Ingresses},
+		Build: GenerateIngressReport,
+	})
+}
+
 // IngressResourceInfo holds the information for a Kubernetes Ingress resource.
 type IngressResourceInfo struct {
 	Name               string
@@ -23,22 +30,16 @@ type IngressResourceInfo struct {
 	TLSSecretName      string
 	IngressClass       string
 	Rules              string
-	Age                string
+	Age                time.Duration
 	Annotations        string
 }
 
-// Generates a CSV report of Kubernetes Ingress resources.
-func GenerateIngressReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Ingress resources
-	ingresses, err := clientset.NetworkingV1().Ingresses("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Ingress resources: %v", err)
-	}
-
+// Generates a report of Kubernetes Ingress resources.
+func GenerateIngressReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var ingressData []IngressResourceInfo
 
-	for _, ingress := range ingresses.Items {
-		age := time.Since(ingress.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, ingress := range snap.Ingresses {
+		age := time.Since(ingress.CreationTimestamp.Time)
 
 		// Prepare Hosts and Paths
 		var hosts, paths, backendServiceName, backendServicePort, tlsEnabled, tlsSecretName, ingressClass, rules string
@@ -102,27 +103,22 @@ func GenerateIngressReportCSV(writer *csv.Writer, clientset *kubernetes.Clientse
 		return ingressData[i].Name < ingressData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"INGRESS NAME",
-		"NAMESPACE",
-		"HOST(S)",
-		"PATH(S)",
-		"BACKEND SERVICE NAME",
-		"BACKEND SERVICE PORT",
-		"TLS ENABLED",
-		"TLS SECRET NAME",
-		"INGRESS CLASS",
-		"RULES",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Ingress resource data rows
+	t := table.New("",
+		table.Column{Header: "INGRESS NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "HOST(S)"},
+		table.Column{Header: "PATH(S)"},
+		table.Column{Header: "BACKEND SERVICE NAME"},
+		table.Column{Header: "BACKEND SERVICE PORT"},
+		table.Column{Header: "TLS ENABLED"},
+		table.Column{Header: "TLS SECRET NAME"},
+		table.Column{Header: "INGRESS CLASS"},
+		table.Column{Header: "RULES"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, ingress := range ingressData {
-		record := []string{
+		t.AddRow(
 			ingress.Name,
 			ingress.Namespace,
 			ingress.Hosts,
@@ -135,17 +131,8 @@ func GenerateIngressReportCSV(writer *csv.Writer, clientset *kubernetes.Clientse
 			ingress.Rules,
 			ingress.Age,
 			ingress.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
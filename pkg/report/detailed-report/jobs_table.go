@@ -1,50 +1,50 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 220, section.Func{
+		Title: "[ JOB DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Jobs},
+		Build: GenerateJobReport,
+	})
+}
+
 // JobInfo holds the information for a Kubernetes Job.
 type JobInfo struct {
 	Name          string
 	Namespace     string
-	Completions   int32
-	Parallelism   int32
+	Completions   *int32
+	Parallelism   *int32
 	ActivePods    int32
 	SucceededPods int32
 	FailedPods    int32
-	Age           string
+	Age           time.Duration
 	Conditions    string
-	JobDuration   string
-	JobTemplate   string
+	// JobDuration is nil until the job has completed.
+	JobDuration *time.Duration
+	JobTemplate string
 }
 
-// Generates a CSV report of Kubernetes Jobs.
-func GenerateJobReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Jobs
-	jobs, err := clientset.BatchV1().Jobs("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Jobs: %v", err)
-	}
-
+// Generates a report of Kubernetes Jobs.
+func GenerateJobReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var jobData []JobInfo
 
-	for _, job := range jobs.Items {
-		age := time.Since(job.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, job := range snap.Jobs {
+		age := time.Since(job.CreationTimestamp.Time)
 
-		var jobDuration string
+		var jobDuration *time.Duration
 		if job.Status.StartTime != nil && job.Status.CompletionTime != nil {
-			duration := job.Status.CompletionTime.Time.Sub(job.Status.StartTime.Time)
-			jobDuration = duration.String()
-		} else {
-			jobDuration = "N/A"
+			duration := job.Status.CompletionTime.Time.Sub(job.Status.StartTime.Time).Round(time.Second)
+			jobDuration = &duration
 		}
 
 		jobTemplate := fmt.Sprintf("%s/%s", job.Spec.Template.Name, job.Spec.Template.Namespace)
@@ -60,8 +60,8 @@ func GenerateJobReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) e
 		jobInfo := JobInfo{
 			Name:          job.Name,
 			Namespace:     job.Namespace,
-			Completions:   *job.Spec.Completions,
-			Parallelism:   *job.Spec.Parallelism,
+			Completions:   job.Spec.Completions,
+			Parallelism:   job.Spec.Parallelism,
 			ActivePods:    job.Status.Active,
 			SucceededPods: job.Status.Succeeded,
 			FailedPods:    job.Status.Failed,
@@ -77,44 +77,35 @@ func GenerateJobReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) e
 	sort.Slice(jobData, func(i, j int) bool {
 		return jobData[i].Name < jobData[j].Name
 	})
-
-	if err := writer.Write([]string{
-		"JOB NAME",
-		"NAMESPACE",
-		"COMPLETIONS",
-		"PARALLELISM",
-		"ACTIVE PODS",
-		"SUCCEEDED PODS",
-		"FAILED PODS",
-		"AGE",
-		"CONDITIONS",
-		"JOB DURATION",
-		"JOB TEMPLATE",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
+	t := table.New("",
+		table.Column{Header: "JOB NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "COMPLETIONS", Kind: table.Integer},
+		table.Column{Header: "PARALLELISM", Kind: table.Integer},
+		table.Column{Header: "ACTIVE PODS", Kind: table.Integer},
+		table.Column{Header: "SUCCEEDED PODS", Kind: table.Integer},
+		table.Column{Header: "FAILED PODS", Kind: table.Integer},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "CONDITIONS"},
+		// Jobs often take seconds, which the default rounding to hours would hide.
+		table.Column{Header: "JOB DURATION", Kind: table.Duration, Format: "%v"},
+		table.Column{Header: "JOB TEMPLATE"},
+	)
 	for _, job := range jobData {
-		record := []string{
+		t.AddRow(
 			job.Name,
 			job.Namespace,
-			fmt.Sprintf("%d", job.Completions),
-			fmt.Sprintf("%d", job.Parallelism),
-			fmt.Sprintf("%d", job.ActivePods),
-			fmt.Sprintf("%d", job.SucceededPods),
-			fmt.Sprintf("%d", job.FailedPods),
+			table.Deref(job.Completions),
+			table.Deref(job.Parallelism),
+			job.ActivePods,
+			job.SucceededPods,
+			job.FailedPods,
 			job.Age,
 			job.Conditions,
-			job.JobDuration,
+			table.Deref(job.JobDuration),
 			job.JobTemplate,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,24 +1,30 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 200, section.Func{
+		Title: "[ LIMIT RANGE DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.LimitRanges},
+		Build: GenerateLimitRangeReport,
+	})
+}
+
 // LimitRangeInfo holds the information for a Kubernetes Limit Range.
 type LimitRangeInfo struct {
 	Name            string
 	Namespace       string
 	Limits          string
 	Requests        string
-	Age             string
+	Age             time.Duration
 	Annotations     string
 	Status          string
 	LimitType       string
@@ -26,19 +32,13 @@ type LimitRangeInfo struct {
 	DefaultRequests string
 }
 
-// Generates a CSV report of Kubernetes Limit Ranges.
-func GenerateLimitRangeReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Limit Ranges
-	lrs, err := clientset.CoreV1().LimitRanges("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Limit Ranges: %v", err)
-	}
-
+// Generates a report of Kubernetes Limit Ranges.
+func GenerateLimitRangeReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var lrData []LimitRangeInfo
 
 	// Iterate over Limit Ranges to get their information
-	for _, lr := range lrs.Items {
-		age := time.Since(lr.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, lr := range snap.LimitRanges {
+		age := time.Since(lr.CreationTimestamp.Time)
 
 		// Prepare Limits and Requests
 		limits := fmt.Sprintf("%v", lr.Spec.Limits)
@@ -48,10 +48,10 @@ func GenerateLimitRangeReportCSV(writer *csv.Writer, clientset *kubernetes.Clien
 		defaultRequests := ""
 		for _, limit := range lr.Spec.Limits {
 			if limit.Default != nil {
-				defaultLimits = fmt.Sprintf("CPU: %s, Memory: %s", limit.Default[v1.ResourceCPU], limit.Default[v1.ResourceMemory])
+				defaultLimits = fmt.Sprintf("CPU: %s, Memory: %s", limit.Default.Cpu(), limit.Default.Memory())
 			}
 			if limit.DefaultRequest != nil {
-				defaultRequests = fmt.Sprintf("CPU: %s, Memory: %s", limit.DefaultRequest[v1.ResourceCPU], limit.DefaultRequest[v1.ResourceMemory])
+				defaultRequests = fmt.Sprintf("CPU: %s, Memory: %s", limit.DefaultRequest.Cpu(), limit.DefaultRequest.Memory())
 			}
 		}
 
@@ -78,25 +78,20 @@ func GenerateLimitRangeReportCSV(writer *csv.Writer, clientset *kubernetes.Clien
 		return lrData[i].Name < lrData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"RESOURCE NAME",
-		"NAMESPACE",
-		"LIMITS",
-		"REQUESTS",
-		"AGE",
-		"ANNOTATIONS",
-		"STATUS",
-		"LIMIT TYPE",
-		"DEFAULT LIMITS",
-		"DEFAULT REQUESTS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Limit Range data rows
+	t := table.New("",
+		table.Column{Header: "RESOURCE NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "LIMITS"},
+		table.Column{Header: "REQUESTS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+		table.Column{Header: "STATUS"},
+		table.Column{Header: "LIMIT TYPE"},
+		table.Column{Header: "DEFAULT LIMITS"},
+		table.Column{Header: "DEFAULT REQUESTS"},
+	)
 	for _, lr := range lrData {
-		record := []string{
+		t.AddRow(
 			lr.Name,
 			lr.Namespace,
 			lr.Limits,
@@ -107,17 +102,8 @@ func GenerateLimitRangeReportCSV(writer *csv.Writer, clientset *kubernetes.Clien
 			lr.LimitType,
 			lr.DefaultLimits,
 			lr.DefaultRequests,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
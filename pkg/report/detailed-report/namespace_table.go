@@ -1,42 +1,62 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
-	"strconv"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"github.com/kubesuiteorg/kubereport/pkg/report/usage"
 )
 
-// Generates a CSV file for namespace resource usage.
-func GenerateNamespaceTable(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	headers := []string{"NAMESPACE", "PODS", "RUNNING PODS", "PENDING PODS", "FAILED PODS", "SERVICES", "DEPLOYMENTS", "REPLICASETS", "STATEFULSETS", "DAEMONSETS", "CONFIGMAPS", "SECRETS", "ANNOTATIONS", "CPU REQ (MCPU)", "CPU LIM (MCPU)", "MEMORY REQ (MIB)", "MEMORY LIM (MIB)"}
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write header to CSV file: %v", err)
-	}
+func init() {
+	section.Register(section.Detailed, 30, section.Func{
+		Title: "[ NAMESPACE DETAILS ]",
+		// Pods are read in full for their phase and resources; everything else is only counted.
+		Reads: []snapshot.Resource{
+			snapshot.Pods,
+			snapshot.MetadataOf(snapshot.Namespaces),
+			snapshot.MetadataOf(snapshot.Services),
+			snapshot.MetadataOf(snapshot.Deployments),
+			snapshot.MetadataOf(snapshot.ReplicaSets),
+			snapshot.MetadataOf(snapshot.StatefulSets),
+			snapshot.MetadataOf(snapshot.DaemonSets),
+			snapshot.MetadataOf(snapshot.ConfigMaps),
+			snapshot.MetadataOf(snapshot.Secrets),
+		},
+		Build: GenerateNamespaceTable,
+	})
+}
 
-	ctx := context.TODO()
-	namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to list namespaces: %v", err)
-	}
+// Generates a table of object counts and resource usage per namespace.
+func GenerateNamespaceTable(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+	t := table.New("",
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "PODS", Kind: table.Integer},
+		table.Column{Header: "RUNNING PODS", Kind: table.Integer},
+		table.Column{Header: "PENDING PODS", Kind: table.Integer},
+		table.Column{Header: "FAILED PODS", Kind: table.Integer},
+		table.Column{Header: "SERVICES", Kind: table.Integer},
+		table.Column{Header: "DEPLOYMENTS", Kind: table.Integer},
+		table.Column{Header: "REPLICASETS", Kind: table.Integer},
+		table.Column{Header: "STATEFULSETS", Kind: table.Integer},
+		table.Column{Header: "DAEMONSETS", Kind: table.Integer},
+		table.Column{Header: "CONFIGMAPS", Kind: table.Integer},
+		table.Column{Header: "SECRETS", Kind: table.Integer},
+		table.Column{Header: "ANNOTATIONS"},
+		table.Column{Header: "CPU REQ (MCPU)", Kind: table.Integer, Unit: "mCPU"},
+		table.Column{Header: "CPU LIM (MCPU)", Kind: table.Integer, Unit: "mCPU"},
+		table.Column{Header: "MEMORY REQ (MIB)", Kind: table.Integer, Unit: "MiB"},
+		table.Column{Header: "MEMORY LIM (MIB)", Kind: table.Integer, Unit: "MiB"},
+	)
 
-	for _, ns := range namespaces.Items {
-		podCount := 0
+	for _, ns := range snap.Metadata(snapshot.Namespaces) {
 		runningPods := 0
 		pendingPods := 0
 		failedPods := 0
 
-		// List pods in the namespace
-		pods, err := clientset.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{})
-		if err != nil {
-			return fmt.Errorf("failed to list pods in namespace %s: %v", ns.Name, err)
-		}
-
-		podCount = len(pods.Items)
-		for _, pod := range pods.Items {
+		pods := snap.PodsInNamespace(ns.Name)
+		for _, pod := range pods {
 			switch pod.Status.Phase {
 			case "Running":
 				runningPods++
@@ -47,65 +67,28 @@ func GenerateNamespaceTable(writer *csv.Writer, clientset *kubernetes.Clientset)
 			}
 		}
 
-		// Count resources
-		services, _ := clientset.CoreV1().Services(ns.Name).List(ctx, metav1.ListOptions{})
-		deployments, _ := clientset.AppsV1().Deployments(ns.Name).List(ctx, metav1.ListOptions{})
-		replicaSets, _ := clientset.AppsV1().ReplicaSets(ns.Name).List(ctx, metav1.ListOptions{})
-		statefulSets, _ := clientset.AppsV1().StatefulSets(ns.Name).List(ctx, metav1.ListOptions{})
-		daemonSets, _ := clientset.AppsV1().DaemonSets(ns.Name).List(ctx, metav1.ListOptions{})
-		configMaps, _ := clientset.CoreV1().ConfigMaps(ns.Name).List(ctx, metav1.ListOptions{})
-		secrets, _ := clientset.CoreV1().Secrets(ns.Name).List(ctx, metav1.ListOptions{})
-
-		annotations := fmt.Sprintf("%v", ns.Annotations)
-
-		// Calculate resource requests and limits
-		cpuReq := 0
-		cpuLim := 0
-		memReq := 0
-		memLim := 0
+		requested := usage.OfPods(pods)
 
-		for _, pod := range pods.Items {
-			for _, container := range pod.Spec.Containers {
-				if req, ok := container.Resources.Requests["cpu"]; ok {
-					cpuReq += int(req.MilliValue())
-				}
-				if lim, ok := container.Resources.Limits["cpu"]; ok {
-					cpuLim += int(lim.MilliValue())
-				}
-				if req, ok := container.Resources.Requests["memory"]; ok {
-					memReq += int(req.Value() / (1024 * 1024)) // Convert to MiB
-				}
-				if lim, ok := container.Resources.Limits["memory"]; ok {
-					memLim += int(lim.Value() / (1024 * 1024)) // Convert to MiB
-				}
-			}
-		}
-
-		// Prepare the row for the CSV
-		row := []string{
+		t.AddRow(
 			ns.Name,
-			strconv.Itoa(podCount),
-			strconv.Itoa(runningPods),
-			strconv.Itoa(pendingPods),
-			strconv.Itoa(failedPods),
-			strconv.Itoa(len(services.Items)),
-			strconv.Itoa(len(deployments.Items)),
-			strconv.Itoa(len(replicaSets.Items)),
-			strconv.Itoa(len(statefulSets.Items)),
-			strconv.Itoa(len(daemonSets.Items)),
-			strconv.Itoa(len(configMaps.Items)),
-			strconv.Itoa(len(secrets.Items)),
-			annotations,
-			strconv.Itoa(cpuReq),
-			strconv.Itoa(cpuLim),
-			strconv.Itoa(memReq),
-			strconv.Itoa(memLim),
-		}
-
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write row to CSV file: %v", err)
-		}
+			len(pods),
+			runningPods,
+			pendingPods,
+			failedPods,
+			snap.CountInNamespace(snapshot.Services, ns.Name),
+			snap.CountInNamespace(snapshot.Deployments, ns.Name),
+			snap.CountInNamespace(snapshot.ReplicaSets, ns.Name),
+			snap.CountInNamespace(snapshot.StatefulSets, ns.Name),
+			snap.CountInNamespace(snapshot.DaemonSets, ns.Name),
+			snap.CountInNamespace(snapshot.ConfigMaps, ns.Name),
+			snap.CountInNamespace(snapshot.Secrets, ns.Name),
+			fmt.Sprintf("%v", ns.Annotations),
+			usage.Millicores(requested.CPURequests),
+			usage.Millicores(requested.CPULimits),
+			usage.MiB(requested.MemoryRequests),
+			usage.MiB(requested.MemoryLimits),
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
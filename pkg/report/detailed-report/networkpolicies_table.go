@@ -1,16 +1,23 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 180, section.Func{
+		Title: "[ NETWORK POLICY DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.NetworkPolicies},
+		Build: GenerateNetworkPolicyReport,
+	})
+}
+
 // NetworkPolicyInfo holds the information for a Kubernetes Network Policy.
 type NetworkPolicyInfo struct {
 	Name              string
@@ -23,23 +30,17 @@ type NetworkPolicyInfo struct {
 	IngressAction     string
 	EgressAction      string
 	MatchLabels       string
-	Age               string
+	Age               time.Duration
 	Annotations       string
 }
 
-// Generates a CSV report of Kubernetes Network Policies.
-func GenerateNetworkPolicyReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Network Policies
-	nps, err := clientset.NetworkingV1().NetworkPolicies("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Network Policies: %v", err)
-	}
-
+// Generates a report of Kubernetes Network Policies.
+func GenerateNetworkPolicyReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var npData []NetworkPolicyInfo
 
 	// Iterate over Network Policies to get their information
-	for _, np := range nps.Items {
-		age := time.Since(np.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, np := range snap.NetworkPolicies {
+		age := time.Since(np.CreationTimestamp.Time)
 
 		// Prepare Pod Selector
 		podSelector := fmt.Sprintf("%v", np.Spec.PodSelector)
@@ -108,27 +109,22 @@ func GenerateNetworkPolicyReportCSV(writer *csv.Writer, clientset *kubernetes.Cl
 		return npData[i].Name < npData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"NETWORK POLICY NAME",
-		"NAMESPACE",
-		"POD SELECTOR",
-		"NAMESPACE SELECTOR",
-		"POLICY TYPES",
-		"INGRESS RULES",
-		"EGRESS RULES",
-		"INGRESS ACTION",
-		"EGRESS ACTION",
-		"MATCH LABELS",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Network Policy data rows
+	t := table.New("",
+		table.Column{Header: "NETWORK POLICY NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "POD SELECTOR"},
+		table.Column{Header: "NAMESPACE SELECTOR"},
+		table.Column{Header: "POLICY TYPES"},
+		table.Column{Header: "INGRESS RULES"},
+		table.Column{Header: "EGRESS RULES"},
+		table.Column{Header: "INGRESS ACTION"},
+		table.Column{Header: "EGRESS ACTION"},
+		table.Column{Header: "MATCH LABELS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, np := range npData {
-		record := []string{
+		t.AddRow(
 			np.Name,
 			np.Namespace,
 			np.PodSelector,
@@ -141,17 +137,8 @@ func GenerateNetworkPolicyReportCSV(writer *csv.Writer, clientset *kubernetes.Cl
 			np.MatchLabels,
 			np.Age,
 			np.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,45 +1,47 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"github.com/kubesuiteorg/kubereport/pkg/report/usage"
 )
 
-// Generates a CSV file for node resource usage.
-func GenerateNodeSummaryTable(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	headers := []string{"NODE NAME", "STATUS", "SCHEDULABLE", "ROLES", "CPU CAPACITY", "CPU REQUESTS", "CPU LIMITS", "MEMORY CAPACITY", "MEMORY REQUESTS", "MEMORY LIMITS", "DISK CAPACITY", "DISK USAGE", "NODE AGE", "POD COUNT", "CONDITIONS", "TAINTS"}
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write header to CSV file: %v", err)
-	}
-
-	ctx := context.TODO()
-	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to list nodes: %v", err)
-	}
-
-	for _, node := range nodes.Items {
-		nodeName := node.Name
+func init() {
+	section.Register(section.Detailed, 20, section.Func{
+		Title: "[ NODE RESOURCE DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Nodes, snapshot.Pods},
+		Build: GenerateNodeSummaryTable,
+	})
+}
 
-		// Determine node health status
-		nodeStatus := "Unknown"
-		for _, condition := range node.Status.Conditions {
-			if condition.Type == "Ready" {
-				if condition.Status == "True" {
-					nodeStatus = "Healthy"
-				} else {
-					nodeStatus = "Unhealthy"
-				}
-				break
-			}
-		}
+// Generates a table of node capacity and the requests and limits of the pods on each node.
+func GenerateNodeSummaryTable(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+	t := table.New("",
+		table.Column{Header: "NODE NAME"},
+		table.Column{Header: "STATUS"},
+		table.Column{Header: "SCHEDULABLE"},
+		table.Column{Header: "ROLES"},
+		table.Column{Header: "CPU CAPACITY", Kind: table.Integer, Unit: "mCPU", Format: "%dm"},
+		table.Column{Header: "CPU REQUESTS", Kind: table.Integer, Unit: "mCPU", Format: "%dm"},
+		table.Column{Header: "CPU LIMITS", Kind: table.Integer, Unit: "mCPU", Format: "%dm"},
+		table.Column{Header: "MEMORY CAPACITY", Kind: table.Decimal, Unit: "GiB", Format: "%.2fGi"},
+		table.Column{Header: "MEMORY REQUESTS", Kind: table.Decimal, Unit: "GiB", Format: "%.2fGi"},
+		table.Column{Header: "MEMORY LIMITS", Kind: table.Decimal, Unit: "GiB", Format: "%.2fGi"},
+		table.Column{Header: "DISK CAPACITY", Kind: table.Decimal, Unit: "GiB", Format: "%.2fGi"},
+		table.Column{Header: "DISK USAGE", Kind: table.Decimal, Unit: "GiB", Format: "%.2fGi"},
+		table.Column{Header: "NODE AGE", Kind: table.Duration},
+		table.Column{Header: "POD COUNT", Kind: table.Integer},
+		table.Column{Header: "CONDITIONS"},
+		table.Column{Header: "TAINTS"},
+	)
+
+	for i := range snap.Nodes {
+		node := &snap.Nodes[i]
 
 		schedulable := "Yes"
 		if node.Spec.Unschedulable {
@@ -51,82 +53,38 @@ func GenerateNodeSummaryTable(writer *csv.Writer, clientset *kubernetes.Clientse
 			roles = "master"
 		}
 
-		cpuCapacity := node.Status.Capacity.Cpu().MilliValue()
-		memoryCapacity := node.Status.Capacity.Memory().Value()
-		diskCapacity := node.Status.Capacity.StorageEphemeral().Value()
-
-		cpuRequests := resource.NewMilliQuantity(0, resource.DecimalSI)
-		cpuLimits := resource.NewMilliQuantity(0, resource.DecimalSI)
-		memoryRequests := resource.NewQuantity(0, resource.BinarySI)
-		memoryLimits := resource.NewQuantity(0, resource.BinarySI)
-		diskUsage := resource.NewQuantity(0, resource.BinarySI)
-
-		pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
-			FieldSelector: fmt.Sprintf("spec.nodeName=%s", nodeName),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to list pods on node %s: %v", nodeName, err)
-		}
-
-		for _, pod := range pods.Items {
-			for _, container := range pod.Spec.Containers {
-				if cpuRequest, ok := container.Resources.Requests["cpu"]; ok {
-					cpuRequests.Add(cpuRequest)
-				}
-				if cpuLimit, ok := container.Resources.Limits["cpu"]; ok {
-					cpuLimits.Add(cpuLimit)
-				}
-				if memRequest, ok := container.Resources.Requests["memory"]; ok {
-					memoryRequests.Add(memRequest)
-				}
-				if memLimit, ok := container.Resources.Limits["memory"]; ok {
-					memoryLimits.Add(memLimit)
-				}
-			}
-		}
-
-		nodeAge := time.Since(node.CreationTimestamp.Time).Round(time.Hour).String()
-		podCount := len(pods.Items)
+		pods := snap.PodsOnNode(node.Name)
+		requested := usage.OfPods(pods)
 
-		conditions := ""
+		var conditions []string
 		for _, condition := range node.Status.Conditions {
-			if conditions != "" {
-				conditions += ", "
-			}
-			conditions += fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+			conditions = append(conditions, fmt.Sprintf("%s=%s", condition.Type, condition.Status))
 		}
 
-		taints := ""
+		var taints []string
 		for _, taint := range node.Spec.Taints {
-			if taints != "" {
-				taints += ", "
-			}
-			taints += fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect)
+			taints = append(taints, fmt.Sprintf("%s=%s:%s", taint.Key, taint.Value, taint.Effect))
 		}
 
-		row := []string{
-			nodeName,
-			nodeStatus,
+		t.AddRow(
+			node.Name,
+			usage.NodeStatus(node),
 			schedulable,
 			roles,
-			strconv.Itoa(int(cpuCapacity)) + "m",
-			strconv.Itoa(int(cpuRequests.MilliValue())) + "m",
-			strconv.Itoa(int(cpuLimits.MilliValue())) + "m",
-			fmt.Sprintf("%.2fGi", float64(memoryCapacity)/1024/1024/1024),
-			fmt.Sprintf("%.2fGi", float64(memoryRequests.Value())/1024/1024/1024),
-			fmt.Sprintf("%.2fGi", float64(memoryLimits.Value())/1024/1024/1024),
-			fmt.Sprintf("%.2fGi", float64(diskCapacity)/1024/1024/1024),
-			fmt.Sprintf("%.2fGi", float64(diskUsage.Value())/1024/1024/1024),
-			nodeAge,
-			strconv.Itoa(podCount),
-			conditions,
-			taints,
-		}
-
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write row to CSV file: %v", err)
-		}
+			usage.Millicores(*node.Status.Capacity.Cpu()),
+			usage.Millicores(requested.CPURequests),
+			usage.Millicores(requested.CPULimits),
+			usage.GiB(*node.Status.Capacity.Memory()),
+			usage.GiB(requested.MemoryRequests),
+			usage.GiB(requested.MemoryLimits),
+			usage.GiB(*node.Status.Capacity.StorageEphemeral()),
+			0.0,
+			time.Since(node.CreationTimestamp.Time),
+			len(pods),
+			strings.Join(conditions, ", "),
+			strings.Join(taints, ", "),
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,17 +1,24 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
+func init() {
+	section.Register(section.Detailed, 140, section.Func{
+		Title: "[ PERSISTENT VOLUMES DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.PersistentVolumes, snapshot.Pods},
+		Build: GeneratePersistentVolumeReport,
+	})
+}
+
 // PersistentVolumeInfo holds the information for a Kubernetes Persistent Volume.
 type PersistentVolumeInfo struct {
 	Name                  string
@@ -21,7 +28,7 @@ type PersistentVolumeInfo struct {
 	Status                string
 	PersistentVolumeClaim string
 	StorageClass          string
-	Age                   string
+	Age                   time.Duration
 	Phase                 string
 	Annotations           string
 	Claimant              string // The application or pod using the PV
@@ -29,39 +36,21 @@ type PersistentVolumeInfo struct {
 	MountOptions          string // Specific mount options used for the PV
 }
 
-// getPodUsingPVC finds the pod(s) using a specific Persistent Volume Claim (PVC) in a namespace.
-func getPodUsingPVC(clientset *kubernetes.Clientset, namespace, pvcName string) (string, error) {
-	// List all pods in the namespace
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return "", fmt.Errorf("error fetching pods in namespace %s: %v", namespace, err)
-	}
-
-	// Iterate over all pods to find the one using the PVC
-	for _, pod := range pods.Items {
-		for _, volume := range pod.Spec.Volumes {
-			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName {
-				return pod.Name, nil
-			}
-		}
+// getPodUsingPVC finds the pod using a specific Persistent Volume Claim (PVC) in a namespace.
+func getPodUsingPVC(snap *snapshot.ClusterSnapshot, namespace, pvcName string) string {
+	if pods := snap.PodsUsingClaim(namespace, pvcName); len(pods) > 0 {
+		return pods[0].Name
 	}
-
-	return "Unknown", nil
+	return "Unknown"
 }
 
-// Generates a CSV report of Kubernetes Persistent Volumes.
-func GeneratePersistentVolumeReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Persistent Volumes
-	persistentVolumes, err := clientset.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Persistent Volumes: %v", err)
-	}
-
+// Generates a report of Kubernetes Persistent Volumes.
+func GeneratePersistentVolumeReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var pvData []PersistentVolumeInfo
 
 	// Iterate over Persistent Volumes to get their information
-	for _, pv := range persistentVolumes.Items {
-		age := time.Since(pv.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, pv := range snap.PersistentVolumes {
+		age := time.Since(pv.CreationTimestamp.Time)
 
 		accessModes := fmt.Sprintf("%v", pv.Spec.AccessModes)
 
@@ -73,10 +62,7 @@ func GeneratePersistentVolumeReportCSV(writer *csv.Writer, clientset *kubernetes
 			pvClaim = pv.Spec.ClaimRef.Name
 
 			// Find the pod or application using the PVC
-			claimant, err = getPodUsingPVC(clientset, pv.Spec.ClaimRef.Namespace, pvClaim)
-			if err != nil {
-				fmt.Printf("error finding pod using PVC %s in namespace %s: %v\n", pvClaim, pv.Spec.ClaimRef.Namespace, err)
-			}
+			claimant = getPodUsingPVC(snap, pv.Spec.ClaimRef.Namespace, pvClaim)
 		}
 
 		volumeMode := ""
@@ -112,28 +98,23 @@ func GeneratePersistentVolumeReportCSV(writer *csv.Writer, clientset *kubernetes
 		return pvData[i].Name < pvData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"PV NAME",
-		"CAPACITY",
-		"ACCESS MODES",
-		"RECLAIM POLICY",
-		"STATUS",
-		"PERSISTENT VOLUME CLAIM",
-		"STORAGE CLASS",
-		"AGE",
-		"PHASE",
-		"ANNOTATIONS",
-		"CLAIMANT",
-		"VOLUME MODE",
-		"MOUNT OPTIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Persistent Volume data rows
+	t := table.New("",
+		table.Column{Header: "PV NAME"},
+		table.Column{Header: "CAPACITY"},
+		table.Column{Header: "ACCESS MODES"},
+		table.Column{Header: "RECLAIM POLICY"},
+		table.Column{Header: "STATUS"},
+		table.Column{Header: "PERSISTENT VOLUME CLAIM"},
+		table.Column{Header: "STORAGE CLASS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "PHASE"},
+		table.Column{Header: "ANNOTATIONS"},
+		table.Column{Header: "CLAIMANT"},
+		table.Column{Header: "VOLUME MODE"},
+		table.Column{Header: "MOUNT OPTIONS"},
+	)
 	for _, pv := range pvData {
-		record := []string{
+		t.AddRow(
 			pv.Name,
 			pv.Capacity,
 			pv.AccessModes,
@@ -147,17 +128,8 @@ func GeneratePersistentVolumeReportCSV(writer *csv.Writer, clientset *kubernetes
 			pv.Claimant,
 			pv.VolumeMode,
 			pv.MountOptions,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
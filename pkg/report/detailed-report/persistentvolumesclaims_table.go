@@ -1,17 +1,25 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 )
 
+func init() {
+	section.Register(section.Detailed, 150, section.Func{
+		Title: "[ PERSISTENT VOLUME CLAIM DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.PersistentVolumeClaims},
+		Build: GeneratePersistentVolumeClaimReport,
+	})
+}
+
 // PersistentVolumeClaimInfo holds the information for a Kubernetes Persistent Volume Claim.
 type PersistentVolumeClaimInfo struct {
 	Name         string
@@ -21,25 +29,19 @@ type PersistentVolumeClaimInfo struct {
 	Capacity     string
 	AccessModes  string
 	StorageClass string
-	Age          string
+	Age          time.Duration
 	VolumeMode   string
 	Annotations  string
 	Selector     string
 }
 
-// Generates a CSV report of Kubernetes Persistent Volume Claims.
-func GeneratePersistentVolumeClaimReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Persistent Volume Claims
-	pvcs, err := clientset.CoreV1().PersistentVolumeClaims("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Persistent Volume Claims: %v", err)
-	}
-
+// Generates a report of Kubernetes Persistent Volume Claims.
+func GeneratePersistentVolumeClaimReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var pvcData []PersistentVolumeClaimInfo
 
 	// Iterate over Persistent Volume Claims to get their information
-	for _, pvc := range pvcs.Items {
-		age := time.Since(pvc.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, pvc := range snap.PersistentVolumeClaims {
+		age := time.Since(pvc.CreationTimestamp.Time)
 
 		accessModes := fmt.Sprintf("%v", pvc.Spec.AccessModes)
 
@@ -88,26 +90,21 @@ func GeneratePersistentVolumeClaimReportCSV(writer *csv.Writer, clientset *kuber
 		return pvcData[i].Name < pvcData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"PVC NAME",
-		"NAMESPACE",
-		"STATUS",
-		"VOLUME",
-		"CAPACITY",
-		"ACCESS MODES",
-		"STORAGE CLASS",
-		"AGE",
-		"VOLUME MODE",
-		"ANNOTATIONS",
-		"SELECTOR",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Persistent Volume Claim data rows
+	t := table.New("",
+		table.Column{Header: "PVC NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "STATUS"},
+		table.Column{Header: "VOLUME"},
+		table.Column{Header: "CAPACITY"},
+		table.Column{Header: "ACCESS MODES"},
+		table.Column{Header: "STORAGE CLASS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "VOLUME MODE"},
+		table.Column{Header: "ANNOTATIONS"},
+		table.Column{Header: "SELECTOR"},
+	)
 	for _, pvc := range pvcData {
-		record := []string{
+		t.AddRow(
 			pvc.Name,
 			pvc.Namespace,
 			pvc.Status,
@@ -119,17 +116,8 @@ func GeneratePersistentVolumeClaimReportCSV(writer *csv.Writer, clientset *kuber
 			pvc.VolumeMode,
 			pvc.Annotations,
 			pvc.Selector,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -0,0 +1,37 @@
+package detailedreport
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+)
+
+func TestPodRestarts(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []corev1.ContainerStatus
+		want     int64
+	}{
+		{name: "no statuses yet"},
+		{name: "one container", statuses: []corev1.ContainerStatus{{RestartCount: 3}}, want: 3},
+		{name: "all containers", statuses: []corev1.ContainerStatus{{RestartCount: 3}, {RestartCount: 4}}, want: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := &snapshot.ClusterSnapshot{Pods: []corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{Name: "web"},
+				Status:     corev1.PodStatus{ContainerStatuses: tt.statuses},
+			}}}
+			tables, err := GeneratePodResourceUsageReport(snap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tables[0].Rows[0][8]; got != tt.want {
+				t.Errorf("restarts = %v, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,23 +1,30 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 190, section.Func{
+		Title: "[ RESOURCE QUOTA DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.ResourceQuotas},
+		Build: GenerateResourceQuotaReport,
+	})
+}
+
 // ResourceQuotaInfo holds the information for a Kubernetes Resource Quota.
 type ResourceQuotaInfo struct {
 	Name          string
 	Namespace     string
 	HardLimits    string
 	UsedResources string
-	Age           string
+	Age           time.Duration
 	Annotations   string
 	Status        string
 	UsedPods      int
@@ -25,19 +32,13 @@ type ResourceQuotaInfo struct {
 	LimitType     string
 }
 
-// Generates a CSV report of Kubernetes Resource Quotas.
-func GenerateResourceQuotaReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Resource Quotas
-	rqs, err := clientset.CoreV1().ResourceQuotas("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Resource Quotas: %v", err)
-	}
-
+// Generates a report of Kubernetes Resource Quotas.
+func GenerateResourceQuotaReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var rqData []ResourceQuotaInfo
 
 	// Iterate over Resource Quotas to get their information
-	for _, rq := range rqs.Items {
-		age := time.Since(rq.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, rq := range snap.ResourceQuotas {
+		age := time.Since(rq.CreationTimestamp.Time)
 
 		hardLimits := fmt.Sprintf("%v", rq.Spec.Hard)
 
@@ -80,25 +81,20 @@ func GenerateResourceQuotaReportCSV(writer *csv.Writer, clientset *kubernetes.Cl
 		return rqData[i].Name < rqData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"RESOURCE NAME",
-		"NAMESPACE",
-		"HARD LIMITS",
-		"USED RESOURCES",
-		"AGE",
-		"ANNOTATIONS",
-		"STATUS",
-		"USED PODS",
-		"REQUEST LIMITS",
-		"LIMIT TYPE",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Resource Quota data rows
+	t := table.New("",
+		table.Column{Header: "RESOURCE NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "HARD LIMITS"},
+		table.Column{Header: "USED RESOURCES"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+		table.Column{Header: "STATUS"},
+		table.Column{Header: "USED PODS", Kind: table.Integer},
+		table.Column{Header: "REQUEST LIMITS"},
+		table.Column{Header: "LIMIT TYPE"},
+	)
 	for _, rq := range rqData {
-		record := []string{
+		t.AddRow(
 			rq.Name,
 			rq.Namespace,
 			rq.HardLimits,
@@ -106,20 +102,11 @@ func GenerateResourceQuotaReportCSV(writer *csv.Writer, clientset *kubernetes.Cl
 			rq.Age,
 			rq.Annotations,
 			rq.Status,
-			fmt.Sprintf("%d", rq.UsedPods),
+			rq.UsedPods,
 			rq.RequestLimits,
 			rq.LimitType,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,38 +1,39 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 240, section.Func{
+		Title: "[ ROLE DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Roles},
+		Build: GenerateRoleReport,
+	})
+}
+
 // RoleInfo holds the information for a Kubernetes Role.
 type RoleInfo struct {
 	Name        string
 	Namespace   string
 	Rules       string
-	Age         string
+	Age         time.Duration
 	Annotations string
 }
 
-// Generates a CSV report of Kubernetes Roles.
-func GenerateRoleReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Roles from all namespaces
-	roles, err := clientset.RbacV1().Roles(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Roles: %v", err)
-	}
-
+// Generates a report of Kubernetes Roles.
+func GenerateRoleReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var roleData []RoleInfo
 
-	for _, role := range roles.Items {
-		age := time.Since(role.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, role := range snap.Roles {
+		age := time.Since(role.CreationTimestamp.Time)
 
 		// Rules - Simplified for display
 		var rules []string
@@ -69,36 +70,22 @@ func GenerateRoleReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset)
 		return roleData[i].Name < roleData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"ROLE NAME",
-		"NAMESPACE",
-		"RULES",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Role data rows
+	t := table.New("",
+		table.Column{Header: "ROLE NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "RULES"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, role := range roleData {
-		record := []string{
+		t.AddRow(
 			role.Name,
 			role.Namespace,
 			role.Rules,
 			role.Age,
 			role.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
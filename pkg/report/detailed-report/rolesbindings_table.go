@@ -1,17 +1,24 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 250, section.Func{
+		Title: "[ ROLEBINDING DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.RoleBindings},
+		Build: GenerateRoleBindingReport,
+	})
+}
+
 // RoleBindingInfo holds the information for a Kubernetes RoleBinding.
 type RoleBindingInfo struct {
 	Name        string
@@ -20,22 +27,16 @@ type RoleBindingInfo struct {
 	Subjects    string
 	Kind        string
 	APIGroup    string
-	Age         string
+	Age         time.Duration
 	Annotations string
 }
 
-// Generates a CSV report of Kubernetes RoleBindings.
-func GenerateRoleBindingReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch RoleBindings from all namespaces
-	roleBindings, err := clientset.RbacV1().RoleBindings(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching RoleBindings: %v", err)
-	}
-
+// Generates a report of Kubernetes RoleBindings.
+func GenerateRoleBindingReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var roleBindingData []RoleBindingInfo
 
-	for _, roleBinding := range roleBindings.Items {
-		age := time.Since(roleBinding.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, roleBinding := range snap.RoleBindings {
+		age := time.Since(roleBinding.CreationTimestamp.Time)
 
 		roleName := roleBinding.RoleRef.Name
 
@@ -78,23 +79,18 @@ func GenerateRoleBindingReportCSV(writer *csv.Writer, clientset *kubernetes.Clie
 		return roleBindingData[i].Name < roleBindingData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"ROLEBINDING NAME",
-		"NAMESPACE",
-		"ROLE NAME",
-		"SUBJECTS",
-		"KIND",
-		"API GROUP",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write RoleBinding data rows
+	t := table.New("",
+		table.Column{Header: "ROLEBINDING NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "ROLE NAME"},
+		table.Column{Header: "SUBJECTS"},
+		table.Column{Header: "KIND"},
+		table.Column{Header: "API GROUP"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, roleBinding := range roleBindingData {
-		record := []string{
+		t.AddRow(
 			roleBinding.Name,
 			roleBinding.Namespace,
 			roleBinding.RoleName,
@@ -103,17 +99,8 @@ func GenerateRoleBindingReportCSV(writer *csv.Writer, clientset *kubernetes.Clie
 			roleBinding.APIGroup,
 			roleBinding.Age,
 			roleBinding.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,94 +1,58 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
-	"sort"
-	"strconv"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
-// SecretInfo holds the information for a Kubernetes Secret.
-type SecretInfo struct {
-	Name      string
-	Namespace string
-	Type      string
-	DataItems int
-	Age       string
-	Labels    string
+// secretDetails lists every Secret by name.
+var secretDetails = section.Rows{
+	Title: "[ SECRET DETAILS ]",
+	// Only the number of data items is shown, so their contents are never downloaded.
+	Reads: []snapshot.Resource{snapshot.MetadataOf(snapshot.Secrets)},
+	Columns: []table.Column{
+		{Header: "SECRET NAME"},
+		{Header: "NAMESPACE"},
+		{Header: "TYPE"},
+		{Header: "DATA ITEMS", Kind: table.Integer},
+		{Header: "AGE", Kind: table.Duration},
+		{Header: "LABELS"},
+	},
+	Order: table.ByColumn(0, false),
+	Each:  secretRows,
 }
 
-// Generates a CSV report of Kubernetes Secrets.
-func GenerateSecretReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch Secrets
-	secrets, err := clientset.CoreV1().Secrets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching Secrets: %v", err)
-	}
-
-	var secretData []SecretInfo
-
-	for _, secret := range secrets.Items {
-		age := time.Since(secret.CreationTimestamp.Time).Round(time.Hour).String()
+func init() {
+	section.Register(section.Detailed, 120, secretDetails)
+}
 
-		dataItems := len(secret.Data)
+// Generates a report of Kubernetes Secrets.
+func GenerateSecretReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+	return secretDetails.Tables(snap, nil)
+}
 
+func secretRows(snap *snapshot.ClusterSnapshot, emit func(values ...any) error) error {
+	for _, secret := range snap.Metadata(snapshot.Secrets) {
 		labels := ""
 		if len(secret.Labels) > 0 {
 			labels = fmt.Sprintf("%v", secret.Labels)
 		}
 
-		// Create a record for the Secret
-		secretData = append(secretData, SecretInfo{
-			Name:      secret.Name,
-			Namespace: secret.Namespace,
-			Type:      string(secret.Type),
-			DataItems: dataItems,
-			Age:       age,
-			Labels:    labels,
-		})
-	}
-
-	sort.Slice(secretData, func(i, j int) bool {
-		return secretData[i].Name < secretData[j].Name
-	})
-
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"SECRET NAME",
-		"NAMESPACE",
-		"TYPE",
-		"DATA ITEMS",
-		"AGE",
-		"LABELS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write Secret data rows
-	for _, secret := range secretData {
-		record := []string{
+		err := emit(
 			secret.Name,
 			secret.Namespace,
 			secret.Type,
-			strconv.Itoa(secret.DataItems),
-			secret.Age,
-			secret.Labels,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
+			secret.DataItems,
+			time.Since(secret.CreationTimestamp.Time),
+			labels,
+		)
+		if err != nil {
+			return err
 		}
 	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
-	}
-
 	return nil
 }
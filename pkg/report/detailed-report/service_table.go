@@ -1,18 +1,25 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 60, section.Func{
+		Title: "[ SERVICE DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.Services},
+		Build: GenerateServiceReport,
+	})
+}
+
 // ServiceInfo holds the information for a Kubernetes service.
 type ServiceInfo struct {
 	Name            string
@@ -24,22 +31,16 @@ type ServiceInfo struct {
 	TargetPort      string
 	Selector        string
 	SessionAffinity string
-	Age             string
+	Age             time.Duration
 	Conditions      string
 }
 
-// Generates a CSV report of Kubernetes services.
-func GenerateServiceReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch services
-	services, err := clientset.CoreV1().Services(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching services: %v", err)
-	}
-
+// Generates a report of Kubernetes services.
+func GenerateServiceReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var serviceData []ServiceInfo
 
-	for _, svc := range services.Items {
-		age := time.Since(svc.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, svc := range snap.Services {
+		age := time.Since(svc.CreationTimestamp.Time)
 
 		// Prepare ports and target ports
 		var ports []string
@@ -77,26 +78,21 @@ func GenerateServiceReportCSV(writer *csv.Writer, clientset *kubernetes.Clientse
 		return serviceData[i].Name < serviceData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"SERVICE NAME",
-		"NAMESPACE",
-		"TYPE",
-		"CLUSTER IP",
-		"EXTERNAL IP",
-		"PORT(S)",
-		"TARGET PORT",
-		"SELECTOR",
-		"SESSION AFFINITY",
-		"AGE",
-		"CONDITIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write service data rows
+	t := table.New("",
+		table.Column{Header: "SERVICE NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "TYPE"},
+		table.Column{Header: "CLUSTER IP"},
+		table.Column{Header: "EXTERNAL IP"},
+		table.Column{Header: "PORT(S)"},
+		table.Column{Header: "TARGET PORT"},
+		table.Column{Header: "SELECTOR"},
+		table.Column{Header: "SESSION AFFINITY"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "CONDITIONS"},
+	)
 	for _, svc := range serviceData {
-		record := []string{
+		t.AddRow(
 			svc.Name,
 			svc.Namespace,
 			svc.ServiceType,
@@ -108,19 +104,10 @@ func GenerateServiceReportCSV(writer *csv.Writer, clientset *kubernetes.Clientse
 			svc.SessionAffinity,
 			svc.Age,
 			svc.Conditions,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
 
 func formatSelector(selector map[string]string) string {
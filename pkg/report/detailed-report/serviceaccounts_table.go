@@ -1,38 +1,39 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 130, section.Func{
+		Title: "[ SERVICEACCOUNT DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.ServiceAccounts},
+		Build: GenerateServiceAccountReport,
+	})
+}
+
 // ServiceAccountInfo holds the information for a Kubernetes ServiceAccount.
 type ServiceAccountInfo struct {
 	Name             string
 	Namespace        string
 	Secrets          string
 	Annotations      string
-	Age              string
+	Age              time.Duration
 	ImagePullSecrets string
 }
 
-// Generates a CSV report of Kubernetes ServiceAccounts.
-func GenerateServiceAccountReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch ServiceAccounts from all namespaces
-	serviceAccounts, err := clientset.CoreV1().ServiceAccounts("").List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching ServiceAccounts: %v", err)
-	}
-
+// Generates a report of Kubernetes ServiceAccounts.
+func GenerateServiceAccountReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var serviceAccountData []ServiceAccountInfo
 
-	for _, sa := range serviceAccounts.Items {
-		age := time.Since(sa.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, sa := range snap.ServiceAccounts {
+		age := time.Since(sa.CreationTimestamp.Time)
 
 		var secrets []string
 		for _, secret := range sa.Secrets {
@@ -67,38 +68,24 @@ func GenerateServiceAccountReportCSV(writer *csv.Writer, clientset *kubernetes.C
 		serviceAccountData = append(serviceAccountData, serviceAccountInfo)
 	}
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"SERVICEACCOUNT NAME",
-		"NAMESPACE",
-		"SECRETS",
-		"ANNOTATIONS",
-		"AGE",
-		"IMAGE PULL SECRETS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write ServiceAccount data rows
+	t := table.New("",
+		table.Column{Header: "SERVICEACCOUNT NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "SECRETS"},
+		table.Column{Header: "ANNOTATIONS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "IMAGE PULL SECRETS"},
+	)
 	for _, sa := range serviceAccountData {
-		record := []string{
+		t.AddRow(
 			sa.Name,
 			sa.Namespace,
 			sa.Secrets,
 			sa.Annotations,
 			sa.Age,
 			sa.ImagePullSecrets,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
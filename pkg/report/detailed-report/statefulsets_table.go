@@ -1,43 +1,43 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 90, section.Func{
+		Title: "[ STATEFULSET DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.StatefulSets},
+		Build: GenerateStatefulSetReport,
+	})
+}
+
 // StatefulSetInfo is used to hold the information for a Kubernetes StatefulSet.
 type StatefulSetInfo struct {
 	Name            string
 	Namespace       string
-	DesiredReplicas int32
+	DesiredReplicas *int32
 	CurrentReplicas int32
 	PodsReady       int32
 	PodsDesired     int32
 	ServiceName     string
-	Age             string
+	Age             time.Duration
 	Conditions      string
 }
 
-// Generates a CSV report of Kubernetes StatefulSets.
-func GenerateStatefulSetReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch StatefulSets
-	statefulSets, err := clientset.AppsV1().StatefulSets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching StatefulSets: %v", err)
-	}
-
+// Generates a report of Kubernetes StatefulSets.
+func GenerateStatefulSetReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var statefulSetData []StatefulSetInfo
 
-	for _, ss := range statefulSets.Items {
-		age := time.Since(ss.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, ss := range snap.StatefulSets {
+		age := time.Since(ss.CreationTimestamp.Time)
 
 		var conditions []string
 		for _, cond := range ss.Status.Conditions {
@@ -54,7 +54,7 @@ func GenerateStatefulSetReportCSV(writer *csv.Writer, clientset *kubernetes.Clie
 		statefulSetData = append(statefulSetData, StatefulSetInfo{
 			Name:            ss.Name,
 			Namespace:       ss.Namespace,
-			DesiredReplicas: *ss.Spec.Replicas,
+			DesiredReplicas: ss.Spec.Replicas,
 			CurrentReplicas: ss.Status.Replicas,
 			PodsReady:       ss.Status.ReadyReplicas,
 			PodsDesired:     ss.Status.Replicas,
@@ -68,44 +68,30 @@ func GenerateStatefulSetReportCSV(writer *csv.Writer, clientset *kubernetes.Clie
 		return statefulSetData[i].Name < statefulSetData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"STATEFULSET NAME",
-		"NAMESPACE",
-		"DESIRED REPLICAS",
-		"CURRENT REPLICAS",
-		"PODS READY",
-		"PODS DESIRED",
-		"SERVICE NAME",
-		"AGE",
-		"CONDITIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write StatefulSet data rows
+	t := table.New("",
+		table.Column{Header: "STATEFULSET NAME"},
+		table.Column{Header: "NAMESPACE"},
+		table.Column{Header: "DESIRED REPLICAS", Kind: table.Integer},
+		table.Column{Header: "CURRENT REPLICAS", Kind: table.Integer},
+		table.Column{Header: "PODS READY", Kind: table.Integer},
+		table.Column{Header: "PODS DESIRED", Kind: table.Integer},
+		table.Column{Header: "SERVICE NAME"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "CONDITIONS"},
+	)
 	for _, ss := range statefulSetData {
-		record := []string{
+		t.AddRow(
 			ss.Name,
 			ss.Namespace,
-			strconv.Itoa(int(ss.DesiredReplicas)),
-			strconv.Itoa(int(ss.CurrentReplicas)),
-			strconv.Itoa(int(ss.PodsReady)),
-			strconv.Itoa(int(ss.PodsDesired)),
+			table.Deref(ss.DesiredReplicas),
+			ss.CurrentReplicas,
+			ss.PodsReady,
+			ss.PodsDesired,
 			ss.ServiceName,
 			ss.Age,
 			ss.Conditions,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
@@ -1,16 +1,23 @@
 package detailedreport
 
 import (
-	"context"
-	"encoding/csv"
 	"fmt"
 	"sort"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.Detailed, 160, section.Func{
+		Title: "[ STORAGE CLASS DETAILS ]",
+		Reads: []snapshot.Resource{snapshot.StorageClasses},
+		Build: GenerateStorageClassReport,
+	})
+}
+
 // StorageClassInfo holds the information for a Kubernetes StorageClass.
 type StorageClassInfo struct {
 	Name                 string
@@ -20,22 +27,16 @@ type StorageClassInfo struct {
 	AllowVolumeExpansion string
 	Default              bool
 	Parameters           string
-	Age                  string
+	Age                  time.Duration
 	Annotations          string
 }
 
-// Generates a CSV report of Kubernetes StorageClasses.
-func GenerateStorageClassReportCSV(writer *csv.Writer, clientset *kubernetes.Clientset) error {
-	// Fetch StorageClasses
-	storageClasses, err := clientset.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching StorageClasses: %v", err)
-	}
-
+// Generates a report of Kubernetes StorageClasses.
+func GenerateStorageClassReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
 	var storageClassData []StorageClassInfo
 
-	for _, sc := range storageClasses.Items {
-		age := time.Since(sc.CreationTimestamp.Time).Round(time.Hour).String()
+	for _, sc := range snap.StorageClasses {
+		age := time.Since(sc.CreationTimestamp.Time)
 
 		reclaimPolicy := ""
 		if sc.ReclaimPolicy != nil {
@@ -92,44 +93,30 @@ func GenerateStorageClassReportCSV(writer *csv.Writer, clientset *kubernetes.Cli
 		return storageClassData[i].Name < storageClassData[j].Name
 	})
 
-	// Write CSV headers
-	if err := writer.Write([]string{
-		"STORAGECLASS NAME",
-		"PROVISIONER",
-		"RECLAIM POLICY",
-		"BINDING MODE",
-		"ALLOW VOLUME EXPANSION",
-		"DEFAULT",
-		"PARAMETERS",
-		"AGE",
-		"ANNOTATIONS",
-	}); err != nil {
-		return fmt.Errorf("error writing headers to CSV: %v", err)
-	}
-
-	// Write StorageClass data rows
+	t := table.New("",
+		table.Column{Header: "STORAGECLASS NAME"},
+		table.Column{Header: "PROVISIONER"},
+		table.Column{Header: "RECLAIM POLICY"},
+		table.Column{Header: "BINDING MODE"},
+		table.Column{Header: "ALLOW VOLUME EXPANSION"},
+		table.Column{Header: "DEFAULT", Kind: table.Bool},
+		table.Column{Header: "PARAMETERS"},
+		table.Column{Header: "AGE", Kind: table.Duration},
+		table.Column{Header: "ANNOTATIONS"},
+	)
 	for _, sc := range storageClassData {
-		record := []string{
+		t.AddRow(
 			sc.Name,
 			sc.Provisioner,
 			sc.ReclaimPolicy,
 			sc.BindingMode,
 			sc.AllowVolumeExpansion,
-			fmt.Sprintf("%t", sc.Default),
+			sc.Default,
 			sc.Parameters,
 			sc.Age,
 			sc.Annotations,
-		}
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing record to CSV: %v", err)
-		}
-	}
-
-	// Flush the writer
-	writer.Flush()
-	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing CSV writer: %v", err)
+		)
 	}
 
-	return nil
+	return []*table.Table{t}, nil
 }
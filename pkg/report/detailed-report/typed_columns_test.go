@@ -0,0 +1,90 @@
+package detailedreport
+
+import (
+	"testing"
+	"time"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+func TestTimeColumnsAreTyped(t *testing.T) {
+	start := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+
+	snap := &snapshot.ClusterSnapshot{
+		Jobs: []batchv1.Job{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a-done"}, Status: batchv1.JobStatus{StartTime: &start, CompletionTime: &end}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b-running"}, Status: batchv1.JobStatus{StartTime: &start}},
+		},
+		CronJobs: []batchv1.CronJob{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a-ran"}, Status: batchv1.CronJobStatus{LastScheduleTime: &start, LastSuccessfulTime: &start}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b-never"}},
+		},
+		HorizontalPodAutoscalers: []autoscalingv1.HorizontalPodAutoscaler{
+			{ObjectMeta: metav1.ObjectMeta{Name: "a-scaled"}, Status: autoscalingv1.HorizontalPodAutoscalerStatus{LastScaleTime: &start}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "b-idle"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		generate func(*snapshot.ClusterSnapshot) ([]*table.Table, error)
+		key      string
+		want     int64
+	}{
+		{name: "job duration", generate: GenerateJobReport, key: "job_duration", want: 90},
+		{name: "last schedule", generate: GenerateCronJobReport, key: "last_schedule", want: 7200},
+		{name: "last successful job", generate: GenerateCronJobReport, key: "job_duration", want: 7200},
+		{name: "last scale", generate: GenerateHPAReport, key: "last_scale", want: 7200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables, err := tt.generate(snap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			st := table.Structured(tables[0])
+
+			var found bool
+			for _, c := range st.Columns {
+				if c.Key == tt.key {
+					found = true
+					if c.Type != "duration" {
+						t.Errorf("column %s has type %s, want duration", tt.key, c.Type)
+					}
+				}
+			}
+			if !found {
+				t.Fatalf("no column %s", tt.key)
+			}
+
+			if got, ok := st.Rows[0][tt.key].(int64); !ok || got < tt.want || got > tt.want+5 {
+				t.Errorf("first row %s = %v, want about %d seconds", tt.key, st.Rows[0][tt.key], tt.want)
+			}
+			if got := st.Rows[1][tt.key]; got != nil {
+				t.Errorf("second row %s = %v, want null", tt.key, got)
+			}
+		})
+	}
+}
+
+func TestJobDurationText(t *testing.T) {
+	start := metav1.NewTime(time.Now())
+	end := metav1.NewTime(start.Add(90*time.Second + 400*time.Millisecond))
+	snap := &snapshot.ClusterSnapshot{Jobs: []batchv1.Job{
+		{ObjectMeta: metav1.ObjectMeta{Name: "short"}, Status: batchv1.JobStatus{StartTime: &start, CompletionTime: &end}},
+	}}
+	tables, err := GenerateJobReport(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cells := tables[0].Strings(tables[0].Rows[0])
+	if got := cells[9]; got != "1m30s" {
+		t.Errorf("JOB DURATION = %q, want 1m30s", got)
+	}
+}
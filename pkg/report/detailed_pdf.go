@@ -0,0 +1,76 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+)
+
+// GenerateDetailedPDF renders the sections of the detailed report as a PDF, landscape unless
+// the theme says otherwise, each section from a new page after the cover and the table of
+// contents, and saves it to a file named after the timestamp. Collection stops when ctx is cancelled or its deadline passes.
+func GenerateDetailedPDF(ctx context.Context, opts Options) (string, string, error) {
+	if logger != nil {
+		logger.Println("Starting detailed PDF report generation...")
+	}
+
+	sections := renderable[section.PDFSection](section.For(section.Detailed))
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	formattedTime := currentTime.Format("02-01-2006-15-04")
+	outputPath := fmt.Sprintf("kubernetes_cluster_report_%s.pdf", formattedTime)
+
+	doc := newPDFDocument(opts.theme(), true, clusterName, currentTime, snap.SyncedAt, len(sections))
+	pdf := doc.pdf
+
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		pdf.AddPage()
+		doc.heading(s.Name())
+		pdf.Ln(12)
+
+		if err := section.ResourceErr(s, snap); err != nil {
+			writePDFUnavailable(pdf, err)
+		} else if err := results[i].Err; err != nil {
+			writePDFUnavailable(pdf, err)
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if err := section.RenderPDF(s, pdf, snap, results[i].Data); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			writePDFUnavailable(pdf, err)
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		writePDFCollectionErrors(doc, errs)
+	}
+
+	if err := doc.close(outputPath); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save PDF file: %v\n", err)
+		}
+		return "", "", fmt.Errorf("failed to save PDF file: %v", err)
+	}
+
+	if logger != nil {
+		logger.Println("Detailed PDF report generated successfully.")
+	}
+	return clusterName, outputPath, nil
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+)
+
+// pdfMediaBox matches the page size gofpdf writes for the pages of a document.
+var pdfMediaBox = regexp.MustCompile(`/MediaBox \[0 0 ([0-9.]+) ([0-9.]+)\]`)
+
+// pdfPages returns the number of pages of a PDF written by gofpdf and whether its pages are
+// wider than they are high.
+func pdfPages(t *testing.T, path string) (pages int, landscape bool) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		t.Fatalf("%s is not a PDF", path)
+	}
+	m := pdfMediaBox.FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("%s has no page size", path)
+	}
+	width, _ := strconv.ParseFloat(string(m[1]), 64)
+	height, _ := strconv.ParseFloat(string(m[2]), 64)
+	return bytes.Count(data, []byte("/Type /Page\n")), width > height
+}
+
+func TestGenerateDetailedPDF(t *testing.T) {
+	dir := t.TempDir()
+	dump := filepath.Join(dir, "dump.yaml")
+	if err := os.WriteFile(dump, []byte(documentDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+	sections := len(renderable[section.PDFSection](section.For(section.Detailed)))
+
+	portrait := DefaultTheme()
+	portrait.DetailedOrientation = "portrait"
+	tests := []struct {
+		name          string
+		theme         *Theme
+		wantLandscape bool
+	}{
+		{name: "landscape by default", wantLandscape: true},
+		{name: "orientation of the theme", theme: portrait},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, path, err := GenerateDetailedPDF(context.Background(), Options{FromFiles: []string{dump}, Theme: tt.theme})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(path)
+
+			pages, landscape := pdfPages(t, path)
+			if landscape != tt.wantLandscape {
+				t.Errorf("landscape = %v, want %v", landscape, tt.wantLandscape)
+			}
+			// The cover, at least a page of contents, and a page per section.
+			if pages < sections+2 {
+				t.Errorf("%d pages for %d sections, want at least %d", pages, sections, sections+2)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DocumentAPIVersion and DocumentKind identify a report written as JSON or YAML. The
+	// schema is described in docs/report-schema.md; fields are only added within a version.
+	DocumentAPIVersion = "kubereport.kubesuite.org/v1"
+	DocumentKind       = "Report"
+)
+
+// Document is a report in structured form, as written by --format json and yaml.
+type Document struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Report is the report the sections belong to: general or detailed.
+	Report      string    `json:"report"`
+	ClusterName string    `json:"clusterName"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	// CollectedAt is when the data was read from the cluster or, offline, from disk.
+	CollectedAt time.Time `json:"collectedAt"`
+	// SyncedAt is when a watch cache the report was rendered from last listed the cluster.
+	SyncedAt    *time.Time        `json:"syncedAt,omitempty"`
+	ToolVersion string            `json:"toolVersion"`
+	Sections    []DocumentSection `json:"sections"`
+	// Errors lists everything that could not be collected or rendered.
+	Errors []DocumentError `json:"errors,omitempty"`
+}
+
+// DocumentSection is a section of a structured report.
+type DocumentSection struct {
+	// Key is the title of the section in snake case, such as "pod_details".
+	Key   string `json:"key"`
+	Title string `json:"title"`
+	// Available is false when the section could not be produced; Error then says why.
+	Available bool                    `json:"available"`
+	Error     string                  `json:"error,omitempty"`
+	Tables    []table.StructuredTable `json:"tables,omitempty"`
+}
+
+// DocumentError is a resource or section that could not be collected or rendered.
+type DocumentError struct {
+	Source string `json:"source"`
+	Reason string `json:"reason"`
+}
+
+// GenerateDocument writes the sections of a report as JSON or YAML, according to format, to a
+// file named after the timestamp. Collection stops when ctx is cancelled or its deadline passes.
+func GenerateDocument(ctx context.Context, opts Options, report section.Report, format string) (string, string, error) {
+	if format != "json" && format != "yaml" {
+		return "", "", fmt.Errorf("unsupported document format %q", format)
+	}
+	if logger != nil {
+		logger.Printf("Starting %s report generation...\n", strings.ToUpper(format))
+	}
+
+	sections := section.For(report)
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	formattedTime := currentTime.Format("02-01-2006-15-04")
+	outputPath := fmt.Sprintf("kubernetes_cluster_report_%s.%s", formattedTime, format)
+
+	doc := Document{
+		APIVersion:  DocumentAPIVersion,
+		Kind:        DocumentKind,
+		Report:      string(report),
+		ClusterName: clusterName,
+		GeneratedAt: currentTime,
+		CollectedAt: snap.CollectedAt,
+		ToolVersion: opts.ToolVersion,
+		Sections:    []DocumentSection{},
+	}
+	if !snap.SyncedAt.IsZero() {
+		syncedAt := snap.SyncedAt
+		doc.SyncedAt = &syncedAt
+	}
+
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+		ds := DocumentSection{Key: table.Key(title), Title: title}
+
+		var failure error
+		if err := section.ResourceErr(s, snap); err != nil {
+			failure = err
+		} else if err := results[i].Err; err != nil {
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if tables, err := section.TablesOf(s, snap, results[i].Data); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else {
+			ds.Available = true
+			for _, t := range tables {
+				ds.Tables = append(ds.Tables, table.Structured(t))
+			}
+		}
+		if failure != nil {
+			ds.Error = failure.Error()
+		}
+		doc.Sections = append(doc.Sections, ds)
+	}
+
+	for _, e := range errs {
+		doc.Errors = append(doc.Errors, DocumentError{Source: e.Source, Reason: e.Reason})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode report: %v", err)
+	}
+	if format == "yaml" {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return "", "", fmt.Errorf("failed to convert report to YAML: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save %s file: %v\n", strings.ToUpper(format), err)
+		}
+		return "", "", fmt.Errorf("failed to save %s file: %v", strings.ToUpper(format), err)
+	}
+
+	if logger != nil {
+		logger.Printf("%s report generated successfully.\n", strings.ToUpper(format))
+	}
+	return clusterName, outputPath, nil
+}
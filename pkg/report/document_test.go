@@ -0,0 +1,122 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"sigs.k8s.io/yaml"
+)
+
+const documentDump = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: node-1
+  status:
+    capacity:
+      pods: "110"
+`
+
+func TestGenerateDocument(t *testing.T) {
+	dir := t.TempDir()
+	dump := filepath.Join(dir, "dump.yaml")
+	if err := os.WriteFile(dump, []byte(documentDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, dir)
+
+	report := section.Report("test document")
+	section.Register(report, 10, section.Func{
+		Title: "[ NODE PODS ]",
+		Reads: []snapshot.Resource{snapshot.Nodes},
+		Build: func(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+			t := table.New("", table.Column{Header: "NAME"}, table.Column{Header: "POD CAPACITY", Kind: table.Integer})
+			for _, node := range snap.Nodes {
+				t.AddRow(node.Name, node.Status.Capacity.Pods().Value())
+			}
+			return []*table.Table{t}, nil
+		},
+	})
+	section.Register(report, 20, section.Func{
+		Title: "Broken",
+		Build: func(*snapshot.ClusterSnapshot) ([]*table.Table, error) {
+			return nil, errors.New("cannot build")
+		},
+	})
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			_, path, err := GenerateDocument(context.Background(), Options{FromFiles: []string{dump}, ToolVersion: "v1.2.3"}, report, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			os.Remove(path)
+			if format == "yaml" {
+				if data, err = yaml.YAMLToJSON(data); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var doc Document
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc.APIVersion != DocumentAPIVersion || doc.Kind != DocumentKind || doc.Report != string(report) || doc.ToolVersion != "v1.2.3" {
+				t.Errorf("header = %s %s %s %s", doc.APIVersion, doc.Kind, doc.Report, doc.ToolVersion)
+			}
+			if len(doc.Sections) != 2 {
+				t.Fatalf("%d sections, want 2", len(doc.Sections))
+			}
+
+			nodes := doc.Sections[0]
+			if nodes.Key != "node_pods" || nodes.Title != "NODE PODS" || !nodes.Available || len(nodes.Tables) != 1 {
+				t.Fatalf("first section = %+v", nodes)
+			}
+			row := nodes.Tables[0].Rows[0]
+			// JSON numbers decode as float64.
+			if row["name"] != "node-1" || row["pod_capacity"] != float64(110) {
+				t.Errorf("row = %v, want node-1 with a pod capacity of 110", row)
+			}
+
+			broken := doc.Sections[1]
+			if broken.Available || broken.Error != "cannot build" || broken.Tables != nil {
+				t.Errorf("second section = %+v, want it unavailable", broken)
+			}
+			if len(doc.Errors) != 1 || doc.Errors[0].Source != "Broken" {
+				t.Errorf("errors = %+v, want the broken section", doc.Errors)
+			}
+		})
+	}
+}
+
+func TestGenerateDocumentFormat(t *testing.T) {
+	if _, _, err := GenerateDocument(context.Background(), Options{}, section.General, "toml"); err == nil {
+		t.Error("GenerateDocument accepted the toml format")
+	}
+}
+
+// chdir changes the working directory to dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -1,63 +1,92 @@
 package tables
 
 import (
-	"context"
-	"fmt"
+	"sort"
 
-	"github.com/jung-kurt/gofpdf/v2"
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/chart"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 )
 
+func init() {
+	section.Register(section.General, 50, section.Func{
+		Title:  "Pod Distribution Details",
+		Reads:  []snapshot.Resource{snapshot.Pods},
+		Build:  GeneratePodDistributionReport,
+		Charts: GeneratePodDistributionCharts,
+	})
+}
+
 // Generates a report of pod distribution by namespace and node.
-func GeneratePodDistributionReport(pdf *gofpdf.Fpdf, clientset *kubernetes.Clientset) error {
-	// Fetch pods
-	podList, err := clientset.CoreV1().Pods(v1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching pods: %v", err)
-	}
+func GeneratePodDistributionReport(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+	namespaceCounts, nodeCounts := podCounts(snap)
 
-	namespaceCounts := make(map[string]int)
-	nodeCounts := make(map[string]int)
+	byNamespace := distributionTable("Pod Distribution By Namespace", "Name", namespaceCounts)
+	byNode := distributionTable("Pod Distribution By Node", "Node", nodeCounts)
 
-	// Count pods by namespace and node
-	for _, pod := range podList.Items {
-		namespaceCounts[pod.Namespace]++
-		nodeCounts[pod.Spec.NodeName]++
-	}
+	return []*table.Table{byNamespace, byNode}, nil
+}
 
-	pdf.Ln(5)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(0, 10, "Pod Distribution By Namespace")
-	pdf.Ln(10)
+// distributionTable lists pod counts by key, in key order.
+func distributionTable(title, keyHeader string, counts map[string]int) *table.Table {
+	t := table.New(title,
+		table.Column{Header: keyHeader, Width: 130},
+		table.Column{Header: "Value", Kind: table.Integer, Unit: "pods", Format: "%d pods", Width: 30},
+	)
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	colWidth := 130.0
+	for _, key := range keys {
+		t.AddRow(key, counts[key])
+	}
+	return t
+}
 
-	pdf.SetFont("Arial", "B", 10)
-	pdf.CellFormat(colWidth, 10, "Name", "1", 0, "L", false, 0, "")
-	pdf.CellFormat(30, 10, "Value", "1", 1, "L", false, 0, "")
+// Generates bar charts of pod distribution by namespace and node, for PDF reports. The
+// namespaces and nodes with the fewest pods are summed up in a last bar.
+func GeneratePodDistributionCharts(snap *snapshot.ClusterSnapshot) []chart.Chart {
+	namespaceCounts, nodeCounts := podCounts(snap)
 
-	pdf.SetFont("Arial", "", 8)
-	for ns, count := range namespaceCounts {
-		pdf.CellFormat(colWidth, 10, ns, "1", 0, "L", false, 0, "")
-		pdf.CellFormat(30, 10, fmt.Sprintf("%d pods", count), "1", 1, "L", false, 0, "")
+	// Pods not scheduled yet have no node.
+	if n, ok := nodeCounts[""]; ok {
+		delete(nodeCounts, "")
+		nodeCounts["(unscheduled)"] = n
 	}
 
-	pdf.Ln(5)
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(0, 10, "Pod Distribution By Node")
-	pdf.Ln(10)
+	return []chart.Chart{
+		distributionChart("Pods By Namespace", "namespaces", namespaceCounts),
+		distributionChart("Pods By Node", "nodes", nodeCounts),
+	}
+}
 
-	pdf.SetFont("Arial", "B", 10)
-	pdf.CellFormat(colWidth, 10, "Node", "1", 0, "L", false, 0, "")
-	pdf.CellFormat(30, 10, "Value", "1", 1, "L", false, 0, "")
+// podCounts counts pods by namespace and by node.
+func podCounts(snap *snapshot.ClusterSnapshot) (namespaceCounts, nodeCounts map[string]int) {
+	namespaceCounts = make(map[string]int)
+	nodeCounts = make(map[string]int)
+	for _, pod := range snap.Pods {
+		namespaceCounts[pod.Namespace]++
+		nodeCounts[pod.Spec.NodeName]++
+	}
+	return namespaceCounts, nodeCounts
+}
 
-	pdf.SetFont("Arial", "", 8)
-	for node, count := range nodeCounts {
-		pdf.CellFormat(colWidth, 10, node, "1", 0, "L", false, 0, "")
-		pdf.CellFormat(30, 10, fmt.Sprintf("%d pods", count), "1", 1, "L", false, 0, "")
+// distributionChart charts pod counts by key; keys with equal counts are in key order.
+func distributionChart(title, other string, counts map[string]int) chart.Counts {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	return nil
+	c := chart.Counts{Title: title, Unit: "pods", Other: other}
+	for _, key := range keys {
+		c.Labels = append(c.Labels, key)
+		c.Values = append(c.Values, float64(counts[key]))
+	}
+	return c
 }
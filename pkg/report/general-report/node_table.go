@@ -1,127 +1,92 @@
 package tables
 
 import (
-	"context"
 	"fmt"
-	"strconv"
 
-	"github.com/jung-kurt/gofpdf/v2"
-	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"github.com/kubesuiteorg/kubereport/pkg/report/chart"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"github.com/kubesuiteorg/kubereport/pkg/report/usage"
 )
 
-// Generates a summary table of node resources.
-func GenerateNodeSummaryTable(pdf *gofpdf.Fpdf, clientset *kubernetes.Clientset) error {
-	// Fetch nodes
-	nodeList, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("error fetching nodes: %v", err)
-	}
-
-	// Set column widths
-	colWidths := []float64{78.0, 20.0, 20.0, 20.0, 20.0, 20.0, 20.0}
-	headers := []string{
-		"Node Name[Status]",
-		"CPU Allo(mCPU)",
-		"Memory Allo(MiB)",
-		"CPU Lim(mCPU)",
-		"CPU Req(mCPU)",
-		"Memory Lim(MiB)",
-		"Memory Req(MiB)",
-	}
-
-	// Function to print the headers
-	printHeaders := func() {
-		pdf.SetFont("Arial", "B", 6)
-		for i, header := range headers {
-			pdf.CellFormat(colWidths[i], 8, header, "1", 0, "C", false, 0, "")
-		}
-		pdf.Ln(8)
-	}
+func init() {
+	section.Register(section.General, 20, section.Func{
+		Title:  "Node Resource Details",
+		Reads:  []snapshot.Resource{snapshot.Nodes, snapshot.Pods},
+		Build:  GenerateNodeSummaryTable,
+		Charts: GenerateNodeSummaryCharts,
+	})
+}
 
-	// Print the headers initially
-	printHeaders()
+// Generates a summary table of node resources.
+func GenerateNodeSummaryTable(snap *snapshot.ClusterSnapshot) ([]*table.Table, error) {
+	t := table.New("",
+		table.Column{Header: "Node Name[Status]", Width: 3.9},
+		table.Column{Header: "CPU Allo(mCPU)", Kind: table.Integer, Unit: "mCPU"},
+		table.Column{Header: "Memory Allo(MiB)", Kind: table.Integer, Unit: "MiB"},
+		table.Column{Header: "CPU Lim(mCPU)", Kind: table.Integer, Unit: "mCPU"},
+		table.Column{Header: "CPU Req(mCPU)", Kind: table.Integer, Unit: "mCPU"},
+		table.Column{Header: "Memory Lim(MiB)", Kind: table.Integer, Unit: "MiB"},
+		table.Column{Header: "Memory Req(MiB)", Kind: table.Integer, Unit: "MiB"},
+	)
 
 	// Iterate over nodes to get their resource information
-	for _, node := range nodeList.Items {
-		// Get the node status
-		nodeStatus := "Unknown"
-		for _, condition := range node.Status.Conditions {
-			if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
-				nodeStatus = "Ready"
-				break
-			} else if condition.Type == v1.NodeReady && condition.Status != v1.ConditionTrue {
-				nodeStatus = "NotReady"
-				break
-			}
-		}
+	for i := range snap.Nodes {
+		node := &snap.Nodes[i]
 
 		// Combine node name and status
-		nodeNameWithStatus := fmt.Sprintf("%s [%s]", node.Name, nodeStatus)
+		nodeNameWithStatus := fmt.Sprintf("%s [%s]", node.Name, usage.NodeStatus(node))
+
+		capacity := usage.NodeCapacity(snap, node)
+		requested := usage.OfPods(snap.PodsOnNode(node.Name))
+
+		t.AddRow(
+			nodeNameWithStatus,
+			usage.Millicores(capacity.AllocatableCPU),
+			usage.MiB(capacity.AllocatableMemory),
+			usage.Millicores(requested.CPULimits),
+			usage.Millicores(requested.CPURequests),
+			usage.MiB(requested.MemoryLimits),
+			usage.MiB(requested.MemoryRequests),
+		)
+	}
 
-		allocatableCPU := node.Status.Allocatable[v1.ResourceCPU]
-		allocatableMemory := node.Status.Allocatable[v1.ResourceMemory]
+	return []*table.Table{t}, nil
+}
 
-		// Convert to milli values and MiB
-		allocatableCPUInMillis := allocatableCPU.MilliValue()
-		allocatableMemoryInMi := allocatableMemory.ScaledValue(resource.Mega)
+// Generates charts of the requests and limits of every node against its allocatable CPU and
+// memory, for PDF reports. Large clusters show the nodes with the highest requests.
+func GenerateNodeSummaryCharts(snap *snapshot.ClusterSnapshot) []chart.Chart {
+	cpu := chart.Bars{
+		Title:     "Node CPU Requests and Limits (mCPU)",
+		Unit:      "mCPU",
+		Reference: "Allocatable",
+		Series:    []string{"Requests", "Limits"},
+	}
+	memory := chart.Bars{
+		Title:     "Node Memory Requests and Limits (MiB)",
+		Unit:      "MiB",
+		Reference: "Allocatable",
+		Series:    []string{"Requests", "Limits"},
+	}
 
-		// Initialize counters for requested and limit values
-		totalRequestedCPU := resource.NewQuantity(0, resource.DecimalSI)
-		totalLimitCPU := resource.NewQuantity(0, resource.DecimalSI)
-		totalRequestedMemory := resource.NewQuantity(0, resource.BinarySI)
-		totalLimitMemory := resource.NewQuantity(0, resource.BinarySI)
+	for i := range snap.Nodes {
+		node := &snap.Nodes[i]
+		capacity := usage.NodeCapacity(snap, node)
+		requested := usage.OfPods(snap.PodsOnNode(node.Name))
 
-		// Fetch pods on the node
-		podList, err := clientset.CoreV1().Pods(v1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
-			FieldSelector: fmt.Sprintf("spec.nodeName=%s", node.Name),
+		cpu.Groups = append(cpu.Groups, chart.BarGroup{
+			Label:     node.Name,
+			Reference: float64(usage.Millicores(capacity.AllocatableCPU)),
+			Values:    []float64{float64(usage.Millicores(requested.CPURequests)), float64(usage.Millicores(requested.CPULimits))},
+		})
+		memory.Groups = append(memory.Groups, chart.BarGroup{
+			Label:     node.Name,
+			Reference: float64(usage.MiB(capacity.AllocatableMemory)),
+			Values:    []float64{float64(usage.MiB(requested.MemoryRequests)), float64(usage.MiB(requested.MemoryLimits))},
 		})
-		if err != nil {
-			return fmt.Errorf("error fetching pods for node %s: %v", node.Name, err)
-		}
-
-		// Calculate requested and limit values for each pod
-		for _, pod := range podList.Items {
-			for _, container := range pod.Spec.Containers {
-				// Get the requested and limit values
-				requestedCPU := container.Resources.Requests[v1.ResourceCPU]
-				limitCPU := container.Resources.Limits[v1.ResourceCPU]
-				requestedMemory := container.Resources.Requests[v1.ResourceMemory]
-				limitMemory := container.Resources.Limits[v1.ResourceMemory]
-
-				totalRequestedCPU.Add(requestedCPU)
-				totalLimitCPU.Add(limitCPU)
-				totalRequestedMemory.Add(requestedMemory)
-				totalLimitMemory.Add(limitMemory)
-			}
-		}
-
-		// Convert totals to milli values and MiB
-		requestedCPUInMillis := totalRequestedCPU.MilliValue()
-		limitCPUInMillis := totalLimitCPU.MilliValue()
-		requestedMemoryInMi := totalRequestedMemory.ScaledValue(resource.Mega)
-		limitMemoryInMi := totalLimitMemory.ScaledValue(resource.Mega)
-
-		// Check for page break before adding a new row
-		_, pageHeight := pdf.GetPageSize()
-		if pdf.GetY() > pageHeight-40 { // Check if there's enough space for one more row
-			pdf.AddPage()
-			printHeaders() // Reprint headers on new page
-		}
-
-		pdf.SetFont("Arial", "", 6)
-
-		// Print node information in the table
-		pdf.CellFormat(colWidths[0], 8, nodeNameWithStatus, "1", 0, "L", false, 0, "")
-		pdf.CellFormat(colWidths[1], 8, strconv.Itoa(int(allocatableCPUInMillis)), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(colWidths[2], 8, strconv.Itoa(int(allocatableMemoryInMi)), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(colWidths[3], 8, strconv.Itoa(int(limitCPUInMillis)), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(colWidths[4], 8, strconv.Itoa(int(requestedCPUInMillis)), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(colWidths[5], 8, strconv.Itoa(int(limitMemoryInMi)), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(colWidths[6], 8, strconv.Itoa(int(requestedMemoryInMi)), "1", 1, "C", false, 0, "")
 	}
 
-	return nil
+	return []chart.Chart{cpu, memory}
 }
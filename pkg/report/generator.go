@@ -1,18 +1,24 @@
 package report
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strings"
 	"time"
 
-	detailed "github.com/kubesuiteorg/kubereport/pkg/report/detailed-report"
-	general "github.com/kubesuiteorg/kubereport/pkg/report/general-report"
+	_ "github.com/kubesuiteorg/kubereport/pkg/report/detailed-report"
+	_ "github.com/kubesuiteorg/kubereport/pkg/report/general-report"
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
 
-	"github.com/jung-kurt/gofpdf/v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
@@ -26,6 +32,16 @@ func init() {
 	}
 }
 
+// warnf logs a message every run should show: to the in-cluster logger or, outside a cluster,
+// to the standard log on stderr.
+func warnf(format string, args ...any) {
+	if logger != nil {
+		logger.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
+
 // Check if the application is running in a Kubernetes pod
 func isRunningInKubernetes() bool {
 	// Read the environment variable that Kubernetes sets
@@ -67,95 +83,328 @@ func getClientConfig(kubeconfigPath string) (*rest.Config, string, error) {
 	return config, clusterName, nil
 }
 
-type reportSection struct {
-	Title        string
-	PDFGenerator func(pdf *gofpdf.Fpdf, clientset *kubernetes.Clientset) error
-	CSVGenerator func(writer *csv.Writer, clientset *kubernetes.Clientset) error
+// collectionError is a row of the "Collection errors" appendix.
+type collectionError struct {
+	Source string
+	Reason string
 }
 
-// GeneratePDF creates a PDF report and saves it to a dynamically named file based on the cluster name and timestamp.
-func GeneratePDF(kubeconfigPath string) (string, string, error) {
-	if logger != nil {
-		logger.Println("Starting PDF report generation...")
+// collectionErrors starts the appendix with every resource that failed to collect.
+func collectionErrors(snap *snapshot.ClusterSnapshot) []collectionError {
+	var errs []collectionError
+	for _, resource := range snap.Failed() {
+		errs = append(errs, collectionError{string(resource), snap.Err(resource).Error()})
+		if logger != nil {
+			logger.Printf("Failed to collect %s: %v\n", resource, snap.Err(resource))
+		}
+	}
+	return errs
+}
+
+// Options controls where the data for a report comes from.
+type Options struct {
+	// Kubeconfig is the kubeconfig used to reach a live cluster.
+	Kubeconfig string
+	// FromFiles renders the report from snapshots, manifests, archives or directories on disk
+	// instead of a live cluster.
+	FromFiles []string
+	// SaveSnapshot, when set, writes everything the run collected to this path.
+	SaveSnapshot string
+	// Strict aborts the report on the first failed resource or section instead of rendering
+	// the failure in place.
+	Strict bool
+	// Workers is the number of resources collected concurrently.
+	Workers int
+	// SectionTimeout limits how long collecting a single resource may take; zero means no limit.
+	SectionTimeout time.Duration
+	// PageSize is the number of objects requested per list call; zero uses the default.
+	PageSize int64
+	// Stream keeps only the fields the reports read of every listed object and writes the
+	// sections of the detailed report a row at a time instead of building their tables in memory.
+	// It does not make memory flat: every listed object is still held, trimmed, in the snapshot
+	// until the report is written, so peak memory still grows with the number of objects.
+	Stream bool
+	// SortBuffer is the number of rows a streamed section holds in memory to sort them before
+	// spilling to temporary files; zero leaves streamed rows in collection order.
+	SortBuffer int
+	// QPS and Burst limit the rate of API requests; zero uses the client-go defaults of 5 and 10,
+	// and a negative QPS disables client-side rate limiting.
+	QPS   float32
+	Burst int
+	// RequestTimeout limits how long a single API request may take; zero means no limit.
+	RequestTimeout time.Duration
+	// CSVNoEscape writes CSV cells that begin like a spreadsheet formula as they are, instead of
+	// prefixing them with a single quote.
+	CSVNoEscape bool
+	// CSVBOM starts CSV files with a UTF-8 byte order mark, which Excel needs to detect the encoding.
+	CSVBOM bool
+	// CSVDelimiter separates the fields of CSV output; zero uses a comma.
+	CSVDelimiter rune
+	// ToolVersion is the kubereport version recorded in structured output.
+	ToolVersion string
+	// Cache, when set, renders the report from a warm watch cache instead of listing the cluster.
+	// The cache must have been created for the same report, or for both for HTML. FromFiles takes precedence.
+	Cache *Cache
+	// Metrics, when set, is updated with the figures of every run. A cache must have been
+	// created with the same Metrics for them to be complete.
+	Metrics *Metrics
+	// Theme is the branding and page layout of PDF and HTML reports; nil uses DefaultTheme.
+	Theme *Theme
+}
+
+// requirements returns the snapshot resources and API clients the given sections need.
+func requirements(sections []section.Section) ([]snapshot.Resource, map[section.Dependency]bool) {
+	var resources []snapshot.Resource
+	seen := make(map[snapshot.Resource]bool)
+	deps := make(map[section.Dependency]bool)
+	for _, s := range sections {
+		for _, resource := range s.Resources() {
+			if !seen[resource] {
+				seen[resource] = true
+				resources = append(resources, resource)
+			}
+			deps[section.ResourceDependency(resource)] = true
+		}
+		for _, dep := range s.Dependencies() {
+			deps[dep] = true
+		}
+	}
+	return resources, deps
+}
+
+// prepare returns the snapshot the sections are rendered from, either read from disk or collected
+// from the cluster, saves it when requested, and runs the Collect step of every section.
+func prepare(ctx context.Context, opts Options, sections []section.Section) (*snapshot.ClusterSnapshot, []section.Result, error) {
+	var (
+		snap    *snapshot.ClusterSnapshot
+		clients section.Clients
+		err     error
+	)
+
+	// The API requests of the run are logged once everything has been fetched.
+	var requests func() requestCounts
+	defer func() {
+		if requests != nil {
+			logRequests(requests())
+		}
+	}()
+
+	resources, deps := requirements(sections)
+	if opts.Metrics != nil {
+		resources = addMetricsRequirements(resources, deps)
+	}
+	if len(opts.FromFiles) > 0 {
+		load := snapshot.Load
+		if opts.Stream {
+			load = snapshot.LoadCompact
+		}
+		snap, err = load(opts.FromFiles...)
+		if err != nil {
+			if logger != nil {
+				logger.Printf("Failed to load snapshot from disk: %v\n", err)
+			}
+			return nil, nil, fmt.Errorf("failed to load snapshot from disk: %v", err)
+		}
+	} else if opts.Cache != nil {
+		mark := opts.Cache.stats.counts()
+		requests = func() requestCounts { return opts.Cache.stats.counts().sub(mark) }
+		snap, err = opts.Cache.cache.Snapshot(ctx)
+		if err != nil {
+			if logger != nil {
+				logger.Printf("Failed to read cluster snapshot from cache: %v\n", err)
+			}
+			return nil, nil, fmt.Errorf("failed to read cluster snapshot from cache: %v", err)
+		}
+		snap.ClusterName = opts.Cache.clusterName
+		clients = opts.Cache.clients
+		if logger != nil {
+			logger.Printf("Rendering from watch cache last listed at %s\n", snap.SyncedAt.Format(time.RFC3339))
+		}
+	} else {
+		var stats *requestStats
+		snap, clients, stats, err = collectSnapshot(ctx, opts, resources, deps)
+		if stats != nil {
+			requests = stats.counts
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if failed := snap.Failed(); opts.Strict && len(failed) > 0 {
+		return nil, nil, fmt.Errorf("snapshot is incomplete: %v", snap.Err(failed[0]))
+	}
+
+	if opts.SaveSnapshot != "" {
+		if err := snapshot.Save(opts.SaveSnapshot, snap); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to save snapshot: %v\n", err)
+			}
+			return nil, nil, fmt.Errorf("failed to save snapshot: %v", err)
+		}
+	}
+
+	// Metrics that cannot be written do not stop the report; the served ones are updated anyway.
+	if opts.Metrics != nil {
+		if err := opts.Metrics.Update(snap, time.Now()); err != nil {
+			warnf("Failed to update metrics: %v\n", err)
+		}
+	}
+
+	results := section.CollectAll(ctx, sections, clients, snap, opts.Workers, opts.SectionTimeout)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("report generation cancelled: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil && opts.Strict {
+			return nil, nil, fmt.Errorf("failed to collect %s: %v", sections[i].Name(), result.Err)
+		}
+	}
+
+	return snap, results, nil
+}
+
+// newClients creates the API clients for the given dependencies.
+func newClients(config *rest.Config, deps map[section.Dependency]bool) (section.Clients, error) {
+	var (
+		clients section.Clients
+		err     error
+	)
+
+	if deps[section.CoreClient] {
+		// Built-in types are listed as protobuf, which is smaller to transfer and faster to decode.
+		protoConfig := rest.CopyConfig(config)
+		protoConfig.ContentType = runtime.ContentTypeProtobuf
+		protoConfig.AcceptContentTypes = runtime.ContentTypeProtobuf + "," + runtime.ContentTypeJSON
+		clients.Core, err = kubernetes.NewForConfig(protoConfig)
+		if err != nil {
+			return clients, fmt.Errorf("failed to create Kubernetes clientset: %v", err)
+		}
+	}
+	if deps[section.MetricsClient] {
+		clients.Metrics, err = metricsv.NewForConfig(config)
+		if err != nil {
+			return clients, fmt.Errorf("failed to create metrics clientset: %v", err)
+		}
+	}
+	if deps[section.DynamicClient] {
+		clients.Dynamic, err = dynamic.NewForConfig(config)
+		if err != nil {
+			return clients, fmt.Errorf("failed to create dynamic client: %v", err)
+		}
+	}
+	if deps[section.MetadataClient] {
+		clients.Metadata, err = metadata.NewForConfig(config)
+		if err != nil {
+			return clients, fmt.Errorf("failed to create metadata client: %v", err)
+		}
 	}
 
-	config, clusterName, err := getClientConfig(kubeconfigPath)
+	return clients, nil
+}
+
+// collectOptions returns the snapshot collection settings of a run.
+func collectOptions(opts Options) snapshot.CollectOptions {
+	return snapshot.CollectOptions{
+		Workers:  opts.Workers,
+		Timeout:  opts.SectionTimeout,
+		FailFast: opts.Strict,
+		PageSize: opts.PageSize,
+		Compact:  opts.Stream,
+	}
+}
+
+// collectSnapshot connects to the cluster and lists the given resources once into a shared snapshot.
+// The returned stats count the requests made through the clients.
+func collectSnapshot(ctx context.Context, opts Options, resources []snapshot.Resource, deps map[section.Dependency]bool) (*snapshot.ClusterSnapshot, section.Clients, *requestStats, error) {
+	config, clusterName, err := getClientConfig(opts.Kubeconfig)
 	if err != nil {
 		if logger != nil {
 			logger.Printf("Error getting client config: %v\n", err)
 		}
-		return "", "", err
+		return nil, section.Clients{}, nil, err
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
+	stats := configureClient(config, opts, false)
+	clients, err := newClients(config, deps)
 	if err != nil {
 		if logger != nil {
-			logger.Printf("Failed to create Kubernetes clientset: %v\n", err)
+			logger.Printf("Failed to create API clients: %v\n", err)
 		}
-		return "", "", fmt.Errorf("failed to create Kubernetes clientset: %v", err)
+		return nil, section.Clients{}, nil, err
 	}
 
-	metricsClientset, err := metricsv.NewForConfig(config)
+	snap, err := snapshot.Collect(ctx, clients.Core, clients.Metrics, clients.Metadata, collectOptions(opts), resources...)
 	if err != nil {
 		if logger != nil {
-			logger.Printf("Failed to create metrics clientset: %v\n", err)
+			logger.Printf("Failed to collect cluster snapshot: %v\n", err)
 		}
-		return "", "", fmt.Errorf("failed to create metrics clientset: %v", err)
+		return nil, section.Clients{}, nil, fmt.Errorf("failed to collect cluster snapshot: %v", err)
 	}
+	snap.ClusterName = clusterName
+
+	return snap, clients, stats, nil
+}
 
-	sections := []reportSection{
-		{"Cluster Resource Details", func(pdf *gofpdf.Fpdf, cs *kubernetes.Clientset) error {
-			return general.GenerateClusterSummaryTable(pdf, cs, metricsClientset)
-		}, nil},
-		{"Node Resource Details", general.GenerateNodeSummaryTable, nil},
-		{"Namespace Resource Details", general.GenerateNamespaceTable, nil},
-		{"Namespace Summary ", general.GenerateNamespaceSummaryTable, nil},
-		{"Pod Distribution Details", general.GeneratePodDistributionReport, nil},
-		{"Pod Resource Details", general.GeneratePodResourceUsageTable, nil},
-		{"Pod Status", general.GeneratePodDetailsTable, nil},
+// GeneratePDF creates a PDF report and saves it to a dynamically named file based on the cluster name and timestamp.
+// Collection stops when ctx is cancelled or its deadline passes.
+func GeneratePDF(ctx context.Context, opts Options) (string, string, error) {
+	if logger != nil {
+		logger.Println("Starting PDF report generation...")
+	}
+
+	sections := reportSections(section.General)
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
 	}
+	clusterName := snap.ClusterName
 
 	currentTime := time.Now()
 	formattedTime := currentTime.Format("02-01-2006-15-04")
 	outputPath := fmt.Sprintf("kubernetes_cluster_report_%s.pdf", formattedTime)
 
-	pdf := gofpdf.New("P", "mm", "A4", "")
+	doc := newPDFDocument(opts.theme(), false, clusterName, currentTime, snap.SyncedAt, len(sections))
+	pdf := doc.pdf
 	pdf.AddPage()
 
-	// Set font and add the title text
-	pdf.SetFont("Arial", "B", 18)
-	pdf.Ln(10)
-	pdf.Cell(40, 10, "KUBEREPORT")
-	pdf.Ln(15)
-
-	pdf.SetFont("Arial", "B", 18)
-	pdf.Cell(40, 10, "Kubernetes Cluster Qualification Report")
-	pdf.Ln(10)
-	pdf.SetFont("Arial", "", 12)
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
 
-	for _, section := range sections {
 		pdf.Ln(5)
-		pdf.SetFont("Arial", "B", 15)
-		pdf.Cell(0, 10, section.Title)
+		doc.heading(s.Name())
 		pdf.Ln(10)
 
-		if section.PDFGenerator != nil {
-			if err := section.PDFGenerator(pdf, clientset); err != nil {
-				if logger != nil {
-					logger.Printf("Failed to generate %s: %v\n", section.Title, err)
-				}
-				return "", "", fmt.Errorf("failed to generate %s: %v", section.Title, err)
+		if err := section.ResourceErr(s, snap); err != nil {
+			writePDFUnavailable(pdf, err)
+		} else if err := results[i].Err; err != nil {
+			writePDFUnavailable(pdf, err)
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if err := section.RenderPDF(s, pdf, snap, results[i].Data); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
 			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			writePDFUnavailable(pdf, err)
+			errs = append(errs, collectionError{s.Name(), err.Error()})
 		}
 
-		separator := strings.Repeat("-", 160)
 		pdf.Ln(10)
-		pdf.Cell(0, 0, separator)
+		doc.rule()
 		pdf.Ln(4)
+	}
 
+	if len(errs) > 0 {
+		writePDFCollectionErrors(doc, errs)
 	}
 
-	if err := pdf.OutputFileAndClose(outputPath); err != nil {
+	if err := doc.close(outputPath); err != nil {
 		if logger != nil {
 			logger.Printf("Failed to save PDF file: %v\n", err)
 		}
@@ -169,66 +418,19 @@ func GeneratePDF(kubeconfigPath string) (string, string, error) {
 }
 
 // Generate a CSV report and saves it to a dynamically named file based on the cluster name and timestamp.
-func GenerateCSV(kubeconfigPath string) (string, string, error) {
+// Collection stops when ctx is cancelled or its deadline passes.
+func GenerateCSV(ctx context.Context, opts Options) (string, string, error) {
 	if logger != nil {
 		logger.Println("Starting CSV report generation...")
 	}
 
-	config, clusterName, err := getClientConfig(kubeconfigPath)
-	if err != nil {
-		if logger != nil {
-			logger.Printf("Error getting client config: %v\n", err)
-		}
-		return "", "", err
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		if logger != nil {
-			logger.Printf("Failed to create Kubernetes clientset: %v\n", err)
-		}
-		return "", "", fmt.Errorf("failed to create Kubernetes clientset: %v", err)
-	}
+	sections := reportSections(section.Detailed)
 
-	metricsClientset, err := metricsv.NewForConfig(config)
+	snap, results, err := prepare(ctx, opts, sections)
 	if err != nil {
-		if logger != nil {
-			logger.Printf("Failed to create metrics clientset: %v\n", err)
-		}
-		return "", "", fmt.Errorf("failed to create metrics clientset: %v", err)
-	}
-
-	sections := []reportSection{
-		{"[ CLUSTER RESOURCE DETAILS ]", nil, func(writer *csv.Writer, cs *kubernetes.Clientset) error {
-			return detailed.GenerateClusterSummaryCSV(writer, cs, metricsClientset)
-		}},
-		{"[ NODE RESOURCE DETAILS ]", nil, detailed.GenerateNodeSummaryTable},
-		{"[ NAMESPACE DETAILS ]", nil, detailed.GenerateNamespaceTable},
-		{"[ POD DETAILS ]", nil, detailed.GeneratePodResourceUsageCSV},
-		{"[ DEPLOYMENT DETAILS ]", nil, detailed.GenerateDeploymentReportCSV},
-		{"[ SERVICE DETAILS ]", nil, detailed.GenerateServiceReportCSV},
-		{"[ ENDPOINTS DETAILS ]", nil, detailed.GenerateEndpointsReportCSV},
-		{"[ REPLICASET DETAILS ]", nil, detailed.GenerateReplicaSetReportCSV},
-		{"[ STATEFULSET DETAILS ]", nil, detailed.GenerateStatefulSetReportCSV},
-		{"[ DAEMONSETS DETAILS ]", nil, detailed.GenerateDaemonSetReportCSV},
-		{"[ CONFIGMAP DETAILS ]", nil, detailed.GenerateConfigMapReportCSV},
-		{"[ SECRET DETAILS ]", nil, detailed.GenerateSecretReportCSV},
-		{"[ SERVICEACCOUNT DETAILS ]", nil, detailed.GenerateServiceAccountReportCSV},
-		{"[ PERSISTENT VOLUMES DETAILS ]", nil, detailed.GeneratePersistentVolumeReportCSV},
-		{"[ PERSISTENT VOLUME CLAIM DETAILS ]", nil, detailed.GeneratePersistentVolumeClaimReportCSV},
-		{"[ STORAGE CLASS DETAILS ]", nil, detailed.GenerateStorageClassReportCSV},
-		{"[ INGRESS RESOURCES DETAILS ]", nil, detailed.GenerateIngressReportCSV},
-		{"[ NETWORK POLICY DETAILS ]", nil, detailed.GenerateNetworkPolicyReportCSV},
-		{"[ RESOURCE QUOTA DETAILS ]", nil, detailed.GenerateResourceQuotaReportCSV},
-		{"[ LIMIT RANGE DETAILS ]", nil, detailed.GenerateLimitRangeReportCSV},
-		{"[ HORIZONTAL POD AUTOSCALERS DETAILS ]", nil, detailed.GenerateHPAReportCSV},
-		{"[ JOB DETAILS ]", nil, detailed.GenerateJobReportCSV},
-		{"[ CRONJOB DETAILS ]", nil, detailed.GenerateCronJobReportCSV},
-		{"[ ROLE DETAILS ]", nil, detailed.GenerateRoleReportCSV},
-		{"[ ROLEBINDING DETAILS ]", nil, detailed.GenerateRoleBindingReportCSV},
-		{"[ CLUSTERROLE DETAILS ]", nil, detailed.GenerateClusterRoleReportCSV},
-		{"[ CLUSTERROLEBINDING DETAILS ]", nil, detailed.GenerateClusterRoleBindingReportCSV},
+		return "", "", err
 	}
+	clusterName := snap.ClusterName
 
 	currentTime := time.Now()
 	formattedTime := currentTime.Format("02-01-2006-15-04")
@@ -243,31 +445,58 @@ func GenerateCSV(kubeconfigPath string) (string, string, error) {
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	writer, err := newCSVWriter(file, opts)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	head := csvTableWriter(writer, opts)
 
 	// Add image reference at the top of the CSV
-	if err := writer.Write([]string{"KUBEREPORT"}); err != nil {
+	if err := head.Record("KUBEREPORT"); err != nil {
 		if logger != nil {
 			logger.Printf("Failed to write KUBEREPORT to CSV: %v\n", err)
 		}
 		return "", "", fmt.Errorf("failed to write KUBEREPORT to CSV: %v", err)
 	}
+	if !snap.SyncedAt.IsZero() {
+		if err := head.Record("Rendered from watch cache, last listed", snap.SyncedAt.Format("02-01-2006 15:04")); err != nil {
+			return "", "", fmt.Errorf("failed to write sync time to CSV: %v", err)
+		}
+	}
+
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
 
-	for _, section := range sections {
-		if err := writer.Write([]string{section.Title}); err != nil {
+		cw := csvTableWriter(writer, opts)
+		if err := cw.Record(s.Name()); err != nil {
 			if logger != nil {
-				logger.Printf("Failed to write %s title row to CSV: %v\n", section.Title, err)
+				logger.Printf("Failed to write %s title row to CSV: %v\n", s.Name(), err)
 			}
-			return "", "", fmt.Errorf("failed to write %s title row to CSV: %v", section.Title, err)
+			return "", "", fmt.Errorf("failed to write %s title row to CSV: %v", s.Name(), err)
 		}
 
-		if section.CSVGenerator != nil {
-			if err := section.CSVGenerator(writer, clientset); err != nil {
-				if logger != nil {
-					logger.Printf("Failed to generate %s: %v\n", section.Title, err)
-				}
-				return "", "", fmt.Errorf("failed to generate %s: %v", section.Title, err)
+		var failure error
+		if err := section.ResourceErr(s, snap); err != nil {
+			failure = err
+		} else if err := results[i].Err; err != nil {
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if err := renderCSV(s, cw, snap, results[i].Data, opts); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		}
+		if failure != nil {
+			if err := writeCSVUnavailable(cw, failure); err != nil {
+				return "", "", err
 			}
 		}
 
@@ -279,8 +508,65 @@ func GenerateCSV(kubeconfigPath string) (string, string, error) {
 		}
 	}
 
+	if len(errs) > 0 {
+		if err := writeCSVCollectionErrors(csvTableWriter(writer, opts), errs); err != nil {
+			return "", "", err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", "", fmt.Errorf("failed to write CSV file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", "", fmt.Errorf("failed to close CSV file: %v", err)
+	}
+
 	if logger != nil {
 		logger.Println("CSV report generated successfully.")
 	}
 	return clusterName, outputPath, nil
 }
+
+// renderCSV writes a section of the detailed report through cw, a row at a time when streaming.
+// Sections that write their own CSV are given cw too, so their cells are escaped like any other.
+func renderCSV(s section.Section, cw *table.CSVWriter, snap *snapshot.ClusterSnapshot, data any, opts Options) error {
+	if ss, ok := s.(section.StreamSection); ok && opts.Stream {
+		return ss.Stream(snap, data, cw, opts.SortBuffer)
+	}
+	if _, ok := s.(section.CSVSection); ok {
+		return section.RenderCSV(s, cw, snap, data)
+	}
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := table.Write(cw, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCSVWriter returns a CSV writer to w with the delimiter of opts, after writing a byte order
+// mark if opts asks for one.
+func newCSVWriter(w io.Writer, opts Options) (*csv.Writer, error) {
+	if opts.CSVBOM {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+	}
+	writer := csv.NewWriter(w)
+	if opts.CSVDelimiter != 0 {
+		writer.Comma = opts.CSVDelimiter
+	}
+	return writer, nil
+}
+
+// csvTableWriter returns a table writer for writer that escapes text unless opts turns it off.
+func csvTableWriter(writer *csv.Writer, opts Options) *table.CSVWriter {
+	cw := table.NewCSVWriter(writer)
+	cw.Escape = !opts.CSVNoEscape
+	return cw
+}
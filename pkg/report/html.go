@@ -0,0 +1,256 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"github.com/kubesuiteorg/kubereport/pkg/report/usage"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var (
+	//go:embed html/report.html
+	htmlPage string
+	//go:embed html/report.css
+	htmlCSS string
+	//go:embed html/report.js
+	htmlJS string
+
+	htmlPageTemplate = template.Must(template.New("report").Parse(htmlPage))
+)
+
+// htmlReport is the data of the HTML page template.
+type htmlReport struct {
+	Brand       string
+	Title       string
+	Logo        template.URL
+	Footer      string
+	ClusterName string
+	GeneratedAt string
+	SyncedAt    string
+	ToolVersion string
+	Figures     []htmlFigure
+	Groups      []htmlGroup
+	Errors      []collectionError
+	CSS         template.CSS
+	ThemeCSS    template.CSS
+	JS          template.JS
+}
+
+// htmlFigure is one of the summary figures shown at the top of the page.
+type htmlFigure struct {
+	Label  string
+	Value  string
+	Detail string
+}
+
+// htmlGroup is the sections of one report, listed under its own heading in the sidebar.
+type htmlGroup struct {
+	Name     string
+	Sections []htmlSection
+}
+
+type htmlSection struct {
+	ID     string
+	Title  string
+	Error  string
+	Tables template.HTML
+}
+
+// htmlReports are the reports whose sections the HTML page shows, in order.
+var htmlReports = []struct {
+	name   string
+	report section.Report
+}{
+	{"General", section.General},
+	{"Detailed", section.Detailed},
+}
+
+// GenerateHTML writes the sections of both the general and the detailed report to a single
+// self-contained HTML file, with its styles and script inlined so that it opens offline.
+// Collection stops when ctx is cancelled or its deadline passes.
+func GenerateHTML(ctx context.Context, opts Options) (string, string, error) {
+	if logger != nil {
+		logger.Println("Starting HTML report generation...")
+	}
+
+	// Both section sets are prepared from one snapshot; groups records where each report starts.
+	var (
+		sections []section.Section
+		starts   []int
+	)
+	for _, r := range htmlReports {
+		starts = append(starts, len(sections))
+		sections = append(sections, renderable[section.TableSection](section.For(r.report))...)
+	}
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	formattedTime := currentTime.Format("02-01-2006-15-04")
+	outputPath := fmt.Sprintf("kubernetes_cluster_report_%s.html", formattedTime)
+
+	theme := opts.theme()
+	page := htmlReport{
+		Brand:       theme.Brand,
+		Title:       theme.Title,
+		Footer:      theme.Footer,
+		ClusterName: clusterName,
+		GeneratedAt: currentTime.Format("02-01-2006 15:04"),
+		ToolVersion: opts.ToolVersion,
+		Figures:     summaryFigures(snap),
+		CSS:         template.CSS(htmlCSS),
+		ThemeCSS:    htmlThemeCSS(theme),
+		JS:          template.JS(htmlJS),
+	}
+	if theme.logo != nil {
+		// The logo is inlined like the styles, so that the page stays self-contained.
+		page.Logo = template.URL("data:" + theme.logoMIME() + ";base64," + base64.StdEncoding.EncodeToString(theme.logo))
+	}
+	if !snap.SyncedAt.IsZero() {
+		page.SyncedAt = snap.SyncedAt.Format("02-01-2006 15:04")
+	}
+
+	errs := collectionErrors(snap)
+	for g, r := range htmlReports {
+		end := len(sections)
+		if g+1 < len(starts) {
+			end = starts[g+1]
+		}
+
+		group := htmlGroup{Name: r.name}
+		for i := starts[g]; i < end; i++ {
+			if err := ctx.Err(); err != nil {
+				return "", "", fmt.Errorf("report generation cancelled: %v", err)
+			}
+
+			s := sections[i]
+			title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+			hs := htmlSection{ID: fmt.Sprintf("%s-%s", r.report, table.Key(title)), Title: title}
+
+			if err := section.ResourceErr(s, snap); err != nil {
+				hs.Error = err.Error()
+			} else if err := results[i].Err; err != nil {
+				hs.Error = err.Error()
+				errs = append(errs, collectionError{s.Name(), err.Error()})
+			} else if tables, err := renderHTMLTables(s, snap, results[i].Data); err != nil {
+				if logger != nil {
+					logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+				}
+				if opts.Strict {
+					return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+				}
+				hs.Error = err.Error()
+				errs = append(errs, collectionError{s.Name(), err.Error()})
+			} else {
+				hs.Tables = tables
+			}
+			group.Sections = append(group.Sections, hs)
+		}
+		page.Groups = append(page.Groups, group)
+	}
+	page.Errors = errs
+
+	var buf bytes.Buffer
+	if err := htmlPageTemplate.Execute(&buf, page); err != nil {
+		return "", "", fmt.Errorf("failed to render HTML report: %v", err)
+	}
+
+	if err := os.WriteFile(outputPath, buf.Bytes(), 0o644); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save HTML file: %v\n", err)
+		}
+		return "", "", fmt.Errorf("failed to save HTML file: %v", err)
+	}
+
+	if logger != nil {
+		logger.Println("HTML report generated successfully.")
+	}
+	return clusterName, outputPath, nil
+}
+
+// htmlThemeCSS returns the colours and page layout of the theme as CSS, which overrides the
+// defaults of report.css. LoadTheme has checked every value it writes.
+func htmlThemeCSS(theme *Theme) template.CSS {
+	return template.CSS(fmt.Sprintf(":root { --primary: %s; --accent: %s; --table-header: %s; }\n@page { size: %s %s; }",
+		theme.Colors.Primary, theme.Colors.Accent, theme.Colors.TableHeader, strings.ToLower(theme.Paper), theme.Orientation))
+}
+
+// renderHTMLTables renders the tables of a section as HTML markup.
+func renderHTMLTables(s section.Section, snap *snapshot.ClusterSnapshot, data any) (template.HTML, error) {
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := table.WriteHTML(&buf, tables...); err != nil {
+		return "", err
+	}
+	// WriteHTML escapes every value it writes.
+	return template.HTML(buf.String()), nil
+}
+
+// summaryFigures returns the headline figures of the cluster, leaving out those whose
+// resources failed to collect.
+func summaryFigures(snap *snapshot.ClusterSnapshot) []htmlFigure {
+	var figures []htmlFigure
+
+	if snap.Err(snapshot.Nodes) == nil {
+		ready := 0
+		for i := range snap.Nodes {
+			if usage.NodeStatus(&snap.Nodes[i]) == "Ready" {
+				ready++
+			}
+		}
+		figures = append(figures, htmlFigure{
+			Label:  "Nodes",
+			Value:  fmt.Sprint(len(snap.Nodes)),
+			Detail: fmt.Sprintf("%d ready", ready),
+		})
+	}
+
+	if snap.Err(snapshot.Namespaces) == nil {
+		figures = append(figures, htmlFigure{Label: "Namespaces", Value: fmt.Sprint(len(snap.Namespaces))})
+	}
+
+	if snap.Err(snapshot.Pods) == nil {
+		running := 0
+		for i := range snap.Pods {
+			if snap.Pods[i].Status.Phase == corev1.PodRunning {
+				running++
+			}
+		}
+		figures = append(figures, htmlFigure{
+			Label:  "Pods",
+			Value:  fmt.Sprint(len(snap.Pods)),
+			Detail: fmt.Sprintf("%d running", running),
+		})
+	}
+
+	if snap.Err(snapshot.Nodes) == nil && len(snap.Nodes) > 0 {
+		capacity := usage.ClusterCapacity(snap)
+		cpu := htmlFigure{Label: "Allocatable CPU", Value: fmt.Sprintf("%.1f cores", float64(usage.Millicores(capacity.AllocatableCPU))/1000)}
+		memory := htmlFigure{Label: "Allocatable memory", Value: fmt.Sprintf("%.1f GiB", usage.GiB(capacity.AllocatableMemory))}
+		if snap.Err(snapshot.NodeMetrics) == nil && len(snap.NodeMetrics) > 0 {
+			cpu.Detail = fmt.Sprintf("%.1f%% available", capacity.AvailableCPUPercent())
+			memory.Detail = fmt.Sprintf("%.1f%% available", capacity.AvailableMemoryPercent())
+		}
+		figures = append(figures, cpu, memory)
+	}
+
+	return figures
+}
@@ -0,0 +1,56 @@
+(function () {
+  // Sort a table by the clicked column, numerically when its cells carry data-sort values.
+  document.querySelectorAll("table thead th").forEach(function (th) {
+    th.addEventListener("click", function () {
+      var table = th.closest("table");
+      var tbody = table.tBodies[0];
+      var index = Array.prototype.indexOf.call(th.parentNode.children, th);
+      var desc = th.classList.contains("sorted-asc");
+      table.querySelectorAll("thead th").forEach(function (other) {
+        other.classList.remove("sorted-asc", "sorted-desc");
+      });
+      th.classList.add(desc ? "sorted-desc" : "sorted-asc");
+
+      var rows = Array.prototype.slice.call(tbody.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[index], y = b.cells[index];
+        var nx = sortValue(x), ny = sortValue(y);
+        var cmp;
+        if (nx !== null && ny !== null) {
+          cmp = nx === ny ? 0 : (nx < ny ? -1 : 1);
+        } else if (nx !== null || ny !== null) {
+          // Numbers sort before text such as "N/A" in a numeric column.
+          cmp = nx !== null ? -1 : 1;
+        } else {
+          cmp = x.textContent.localeCompare(y.textContent, undefined, { numeric: true });
+        }
+        return desc ? -cmp : cmp;
+      });
+      rows.forEach(function (row) { tbody.appendChild(row); });
+    });
+  });
+
+  // sortValue returns the number a cell sorts by: its data-sort value, -Infinity when that is
+  // empty, or null for cells without a numeric one, which sort by their text.
+  function sortValue(cell) {
+    if (cell.dataset.sort === undefined) {
+      return null;
+    }
+    if (cell.dataset.sort === "") {
+      return -Infinity;
+    }
+    var n = parseFloat(cell.dataset.sort);
+    return isNaN(n) ? null : n;
+  }
+
+  // Hide the rows of a table that do not contain the text typed in its filter box.
+  document.querySelectorAll("input.filter").forEach(function (input) {
+    var table = input.parentNode.querySelector("table");
+    input.addEventListener("input", function () {
+      var needle = input.value.toLowerCase();
+      Array.prototype.forEach.call(table.tBodies[0].rows, function (row) {
+        row.classList.toggle("hidden", needle !== "" && row.textContent.toLowerCase().indexOf(needle) < 0);
+      });
+    });
+  });
+})();
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummaryFigures(t *testing.T) {
+	node := func(name string, ready corev1.ConditionStatus) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("4"),
+					corev1.ResourceMemory: resource.MustParse("8Gi"),
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+			},
+		}
+	}
+	pod := func(phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{Phase: phase}}
+	}
+	cluster := func() *snapshot.ClusterSnapshot {
+		return &snapshot.ClusterSnapshot{
+			Nodes:      []corev1.Node{node("node-1", corev1.ConditionTrue), node("node-2", corev1.ConditionFalse)},
+			Namespaces: []corev1.Namespace{{}, {}, {}},
+			Pods:       []corev1.Pod{pod(corev1.PodRunning), pod(corev1.PodPending)},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		failed []snapshot.Resource
+		want   []htmlFigure
+	}{
+		{
+			name: "every figure",
+			want: []htmlFigure{
+				{Label: "Nodes", Value: "2", Detail: "1 ready"},
+				{Label: "Namespaces", Value: "3"},
+				{Label: "Pods", Value: "2", Detail: "1 running"},
+				{Label: "Allocatable CPU", Value: "8.0 cores"},
+				{Label: "Allocatable memory", Value: "16.0 GiB"},
+			},
+		},
+		{
+			name:   "failed nodes leave out the capacity",
+			failed: []snapshot.Resource{snapshot.Nodes},
+			want: []htmlFigure{
+				{Label: "Namespaces", Value: "3"},
+				{Label: "Pods", Value: "2", Detail: "1 running"},
+			},
+		},
+		{
+			name:   "failed pods and namespaces",
+			failed: []snapshot.Resource{snapshot.Pods, snapshot.Namespaces},
+			want: []htmlFigure{
+				{Label: "Nodes", Value: "2", Detail: "1 ready"},
+				{Label: "Allocatable CPU", Value: "8.0 cores"},
+				{Label: "Allocatable memory", Value: "16.0 GiB"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := cluster()
+			for _, resource := range tt.failed {
+				snap.SetErr(resource, errors.New("forbidden"))
+			}
+			snap.BuildIndexes()
+			if got := summaryFigures(snap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("summaryFigures() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+// GenerateMarkdown writes the sections of a report as GitHub-flavoured Markdown, under a table
+// of contents, to a file named after the timestamp. Collection stops when ctx is cancelled or
+// its deadline passes.
+func GenerateMarkdown(ctx context.Context, opts Options, report section.Report) (string, string, error) {
+	if logger != nil {
+		logger.Println("Starting Markdown report generation...")
+	}
+
+	sections := renderable[section.TableSection](section.For(report))
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	formattedTime := currentTime.Format("02-01-2006-15-04")
+	outputPath := fmt.Sprintf("kubernetes_cluster_report_%s.md", formattedTime)
+
+	// The sections are written first so that the table of contents can link to their headings.
+	var body bytes.Buffer
+	md := table.NewMarkdownWriter(&body)
+	anchors := make(map[string]int)
+	var contents []string
+
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+		contents = append(contents, fmt.Sprintf("- [%s](#%s)", title, markdownAnchor(title, anchors)))
+		fmt.Fprintf(&body, "## %s\n\n", title)
+
+		if err := section.ResourceErr(s, snap); err != nil {
+			writeMarkdownUnavailable(&body, err)
+		} else if err := results[i].Err; err != nil {
+			writeMarkdownUnavailable(&body, err)
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if err := renderMarkdown(s, md, snap, results[i].Data); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			writeMarkdownUnavailable(&body, err)
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		}
+	}
+
+	if len(errs) > 0 {
+		title := "Collection errors"
+		contents = append(contents, fmt.Sprintf("- [%s](#%s)", title, markdownAnchor(title, anchors)))
+		fmt.Fprintf(&body, "## %s\n\n", title)
+		t := table.New("", table.Column{Header: "Source"}, table.Column{Header: "Reason"})
+		for _, e := range errs {
+			t.AddRow(e.Source, e.Reason)
+		}
+		if err := md.WriteMarkdown(t); err != nil {
+			return "", "", err
+		}
+	}
+
+	if err := md.Footnotes(); err != nil {
+		return "", "", err
+	}
+
+	theme := opts.theme()
+	title := theme.Title
+	if report == section.Detailed {
+		title = theme.DetailedTitle
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "# %s\n\n", table.EscapeMarkdown(title))
+	fmt.Fprintf(&out, "Cluster **%s**, generated %s", table.EscapeMarkdown(clusterName), currentTime.Format("02-01-2006 15:04"))
+	if !snap.SyncedAt.IsZero() {
+		fmt.Fprintf(&out, ", rendered from watch cache, last listed %s", snap.SyncedAt.Format("02-01-2006 15:04"))
+	}
+	out.WriteString(".\n\n## Contents\n\n")
+	out.WriteString(strings.Join(contents, "\n"))
+	out.WriteString("\n\n")
+	out.Write(body.Bytes())
+
+	if err := os.WriteFile(outputPath, out.Bytes(), 0o644); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save Markdown file: %v\n", err)
+		}
+		return "", "", fmt.Errorf("failed to save Markdown file: %v", err)
+	}
+
+	if logger != nil {
+		logger.Println("Markdown report generated successfully.")
+	}
+	return clusterName, outputPath, nil
+}
+
+// renderMarkdown writes the tables of a section as Markdown.
+func renderMarkdown(s section.Section, md *table.MarkdownWriter, snap *snapshot.ClusterSnapshot, data any) error {
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return err
+	}
+	return md.WriteMarkdown(tables...)
+}
+
+// writeMarkdownUnavailable notes in place of a section's tables that it could not be produced.
+func writeMarkdownUnavailable(body *bytes.Buffer, err error) {
+	fmt.Fprintf(body, "_Section unavailable: %s_\n\n", table.EscapeMarkdown(err.Error()))
+}
+
+// markdownAnchor returns the anchor GitHub generates for a heading: lower case, without
+// punctuation, spaces replaced by hyphens and a numeric suffix for repeated headings.
+func markdownAnchor(heading string, seen map[string]int) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	anchor := b.String()
+	if n := seen[anchor]; n > 0 {
+		seen[anchor] = n + 1
+		return fmt.Sprintf("%s-%d", anchor, n)
+	}
+	seen[anchor] = 1
+	return anchor
+}
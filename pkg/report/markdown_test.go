@@ -0,0 +1,22 @@
+package report
+
+import "testing"
+
+func TestMarkdownAnchor(t *testing.T) {
+	seen := make(map[string]int)
+	tests := []struct {
+		heading, want string
+	}{
+		{"[ NODE DETAILS ]", "-node-details-"},
+		{"Pod Details", "pod-details"},
+		{"Pod Details", "pod-details-1"},
+		{"Pod Details", "pod-details-2"},
+		{"Persistent_Volumes (v1)", "persistent_volumes-v1"},
+		{"Nœuds et pods", "nœuds-et-pods"},
+	}
+	for _, tt := range tests {
+		if got := markdownAnchor(tt.heading, seen); got != tt.want {
+			t.Errorf("markdownAnchor(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
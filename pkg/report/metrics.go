@@ -0,0 +1,268 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/usage"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Content types of the two exposition formats Metrics serves.
+const (
+	prometheusContentType  = "text/plain; version=0.0.4; charset=utf-8"
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// metricsResources are the snapshot resources the metrics are computed from. They are collected
+// on every run that exports metrics, whatever its sections read.
+var metricsResources = []snapshot.Resource{snapshot.Nodes, snapshot.NodeMetrics, snapshot.Pods}
+
+// Metrics exports the cluster, node and namespace figures of the latest run as Prometheus
+// gauges: over HTTP, as a handler for /metrics, and, when Textfile is set, as a file written
+// after every run for the textfile collector of node_exporter. The figures are computed the
+// same way as in the report tables. The metric names and labels are listed in docs/metrics.md.
+type Metrics struct {
+	// Textfile is the file the metrics are written to after every run, such as
+	// /var/lib/node_exporter/textfile_collector/kubereport.prom. Empty writes no file.
+	Textfile string
+
+	mu   sync.RWMutex
+	body []byte
+}
+
+// NewMetrics returns a Metrics without figures, which writes them to textfile, if set, once a
+// run has updated them.
+func NewMetrics(textfile string) *Metrics {
+	return &Metrics{Textfile: textfile}
+}
+
+// Update replaces the exported metrics with the figures of snap and writes them to the textfile.
+func (m *Metrics) Update(snap *snapshot.ClusterSnapshot, generatedAt time.Time) error {
+	body := metricsBody(snap, generatedAt)
+
+	m.mu.Lock()
+	m.body = body
+	m.mu.Unlock()
+
+	if m.Textfile == "" {
+		return nil
+	}
+	// The file is replaced in one step so that node_exporter never reads it half written; it
+	// ignores files not ending in .prom, such as the temporary one.
+	tmp, err := os.CreateTemp(filepath.Dir(m.Textfile), ".kubereport-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics textfile: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %v", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), m.Textfile); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %v", err)
+	}
+	return nil
+}
+
+// ServeHTTP serves the metrics of the latest run, in the OpenMetrics format to scrapers that
+// accept it and in the Prometheus text format otherwise. Before the first run there are none.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.RLock()
+	body := m.body
+	m.mu.RUnlock()
+
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		w.Header().Set("Content-Type", openMetricsContentType)
+		w.Write(body)
+		w.Write([]byte("# EOF\n"))
+		return
+	}
+	w.Header().Set("Content-Type", prometheusContentType)
+	w.Write(body)
+}
+
+// addMetricsRequirements adds the resources the metrics are computed from, and the clients
+// that list them, to those of the sections of a run.
+func addMetricsRequirements(resources []snapshot.Resource, deps map[section.Dependency]bool) []snapshot.Resource {
+	for _, resource := range metricsResources {
+		found := false
+		for _, r := range resources {
+			found = found || r == resource
+		}
+		if !found {
+			resources = append(resources, resource)
+		}
+		deps[section.ResourceDependency(resource)] = true
+	}
+	return resources
+}
+
+// metricFamily is a gauge and its samples.
+type metricFamily struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+// metricSample is a sample of a gauge, with the values of its labels in the order of the names.
+type metricSample struct {
+	labels []string
+	values []string
+	value  float64
+}
+
+// metricsBody returns the figures of snap in the Prometheus text format, without the final
+// "# EOF" line of OpenMetrics.
+func metricsBody(snap *snapshot.ClusterSnapshot, generatedAt time.Time) []byte {
+	cluster := []string{"cluster"}
+	clusterValue := []string{snap.ClusterName}
+	families := make(map[string]*metricFamily)
+	var order []string
+	add := func(name, help string, labels, values []string, value float64) {
+		f, ok := families[name]
+		if !ok {
+			f = &metricFamily{name: name, help: help}
+			families[name] = f
+			order = append(order, name)
+		}
+		f.samples = append(f.samples, metricSample{labels: labels, values: values, value: value})
+	}
+
+	add("kubereport_last_run_timestamp_seconds", "Unix time of the last report run.", cluster, clusterValue, float64(generatedAt.Unix()))
+	add("kubereport_collection_errors", "Resources the last report run could not collect.", cluster, clusterValue, float64(len(snap.Failed())))
+
+	nodesOK := snap.Err(snapshot.Nodes) == nil
+	podsOK := snap.Err(snapshot.Pods) == nil
+	// Available figures are only exported when some node reports its usage.
+	usageOK := snap.Err(snapshot.NodeMetrics) == nil && len(snap.NodeMetrics) > 0
+
+	if nodesOK {
+		capacity := usage.ClusterCapacity(snap)
+		add("kubereport_cluster_nodes", "Nodes in the cluster.", cluster, clusterValue, float64(len(snap.Nodes)))
+		add("kubereport_cluster_allocatable_cpu_cores", "CPU allocatable to pods on all nodes.", cluster, clusterValue, cores(capacity.AllocatableCPU))
+		add("kubereport_cluster_allocatable_memory_bytes", "Memory allocatable to pods on all nodes.", cluster, clusterValue, bytesOf(capacity.AllocatableMemory))
+		if usageOK {
+			add("kubereport_cluster_available_cpu_cores", "Allocatable CPU not in use on the nodes that report usage metrics.", cluster, clusterValue, cores(capacity.AvailableCPU))
+			add("kubereport_cluster_available_memory_bytes", "Allocatable memory not in use on the nodes that report usage metrics.", cluster, clusterValue, bytesOf(capacity.AvailableMemory))
+		}
+	}
+	if podsOK {
+		var requested usage.Resources
+		for i := range snap.Pods {
+			requested.Add(&snap.Pods[i])
+		}
+		add("kubereport_cluster_pods", "Pods in the cluster.", cluster, clusterValue, float64(len(snap.Pods)))
+		addResources(add, "cluster", "the pods in the cluster", cluster, clusterValue, requested)
+	}
+
+	if nodesOK {
+		nodes := make([]int, len(snap.Nodes))
+		for i := range nodes {
+			nodes[i] = i
+		}
+		sort.Slice(nodes, func(a, b int) bool { return snap.Nodes[nodes[a]].Name < snap.Nodes[nodes[b]].Name })
+
+		labels := []string{"cluster", "node"}
+		for _, i := range nodes {
+			node := &snap.Nodes[i]
+			values := []string{snap.ClusterName, node.Name}
+			capacity := usage.NodeCapacity(snap, node)
+
+			ready := 0.0
+			if usage.NodeStatus(node) == "Ready" {
+				ready = 1
+			}
+			add("kubereport_node_ready", "Whether the node is Ready (1) or not (0).", labels, values, ready)
+			add("kubereport_node_allocatable_cpu_cores", "CPU allocatable to pods on the node.", labels, values, cores(capacity.AllocatableCPU))
+			add("kubereport_node_allocatable_memory_bytes", "Memory allocatable to pods on the node.", labels, values, bytesOf(capacity.AllocatableMemory))
+			if snap.MetricsForNode(node.Name) != nil {
+				add("kubereport_node_available_cpu_cores", "Allocatable CPU not in use on the node.", labels, values, cores(capacity.AvailableCPU))
+				add("kubereport_node_available_memory_bytes", "Allocatable memory not in use on the node.", labels, values, bytesOf(capacity.AvailableMemory))
+			}
+			if podsOK {
+				pods := snap.PodsOnNode(node.Name)
+				add("kubereport_node_pods", "Pods scheduled on the node.", labels, values, float64(len(pods)))
+				addResources(add, "node", "the pods on the node", labels, values, usage.OfPods(pods))
+			}
+		}
+	}
+
+	if podsOK {
+		var namespaces []string
+		seen := make(map[string]bool)
+		for i := range snap.Pods {
+			if ns := snap.Pods[i].Namespace; !seen[ns] {
+				seen[ns] = true
+				namespaces = append(namespaces, ns)
+			}
+		}
+		sort.Strings(namespaces)
+
+		labels := []string{"cluster", "namespace"}
+		for _, ns := range namespaces {
+			values := []string{snap.ClusterName, ns}
+			pods := snap.PodsInNamespace(ns)
+			add("kubereport_namespace_pods", "Pods in the namespace.", labels, values, float64(len(pods)))
+			addResources(add, "namespace", "the pods in the namespace", labels, values, usage.OfPods(pods))
+		}
+	}
+
+	var b bytes.Buffer
+	for _, name := range order {
+		f := families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", f.name, f.help, f.name)
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			b.WriteString("{")
+			for i, label := range s.labels {
+				if i > 0 {
+					b.WriteString(",")
+				}
+				fmt.Fprintf(&b, "%s=\"%s\"", label, labelEscaper.Replace(s.values[i]))
+			}
+			b.WriteString("} ")
+			b.WriteString(strconv.FormatFloat(s.value, 'f', -1, 64))
+			b.WriteString("\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// addResources adds the request and limit gauges of a cluster, node or namespace.
+func addResources(add func(name, help string, labels, values []string, value float64), scope, of string, labels, values []string, r usage.Resources) {
+	prefix := "kubereport_" + scope + "_"
+	add(prefix+"cpu_requests_cores", fmt.Sprintf("CPU requested by %s.", of), labels, values, cores(r.CPURequests))
+	add(prefix+"cpu_limits_cores", fmt.Sprintf("CPU limits of %s.", of), labels, values, cores(r.CPULimits))
+	add(prefix+"memory_requests_bytes", fmt.Sprintf("Memory requested by %s.", of), labels, values, bytesOf(r.MemoryRequests))
+	add(prefix+"memory_limits_bytes", fmt.Sprintf("Memory limits of %s.", of), labels, values, bytesOf(r.MemoryLimits))
+}
+
+// labelEscaper escapes label values for both exposition formats.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// cores returns a CPU quantity in cores, the base unit of CPU metrics.
+func cores(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000
+}
+
+// bytesOf returns a memory quantity in bytes, the base unit of memory metrics.
+func bytesOf(q resource.Quantity) float64 {
+	return float64(q.Value())
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+)
+
+func metricsSnapshot(clusterName, namespace string) *snapshot.ClusterSnapshot {
+	s := &snapshot.ClusterSnapshot{
+		ClusterName: clusterName,
+		Nodes: []corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+				Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}},
+		Pods: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+			Spec: corev1.PodSpec{NodeName: "node-a", Containers: []corev1.Container{{Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+			}}}},
+		}},
+	}
+	s.BuildIndexes()
+	return s
+}
+
+func TestMetricsBody(t *testing.T) {
+	generatedAt := time.Unix(1700000000, 0)
+	tests := []struct {
+		name        string
+		clusterName string
+		namespace   string
+		want        []string
+	}{
+		{
+			name:        "plain",
+			clusterName: "prod",
+			namespace:   "web",
+			want: []string{
+				"# TYPE kubereport_cluster_nodes gauge\n",
+				`kubereport_last_run_timestamp_seconds{cluster="prod"} 1700000000` + "\n",
+				`kubereport_cluster_allocatable_cpu_cores{cluster="prod"} 2` + "\n",
+				`kubereport_node_ready{cluster="prod",node="node-a"} 1` + "\n",
+				`kubereport_node_memory_requests_bytes{cluster="prod",node="node-a"} 1073741824` + "\n",
+				`kubereport_namespace_cpu_requests_cores{cluster="prod",namespace="web"} 0.5` + "\n",
+			},
+		},
+		{
+			name:        "escaped labels",
+			clusterName: "a \"quoted\" \\ name\nnext",
+			namespace:   "web",
+			want: []string{
+				`kubereport_cluster_nodes{cluster="a \"quoted\" \\ name\nnext"} 1` + "\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := string(metricsBody(metricsSnapshot(tt.clusterName, tt.namespace), generatedAt))
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("metrics have no %q:\n%s", want, body)
+				}
+			}
+			// Available figures need node metrics.
+			if strings.Contains(body, "available") {
+				t.Errorf("available figures exported without node metrics:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestMetricsUpdateTextfile(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		textfile string
+		wantErr  bool
+	}{
+		{name: "written", textfile: filepath.Join(dir, "kubereport.prom")},
+		{name: "missing directory", textfile: filepath.Join(dir, "missing", "kubereport.prom"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMetrics(tt.textfile)
+			err := m.Update(metricsSnapshot("prod", "web"), time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(m.body) == 0 {
+				t.Errorf("served metrics not updated")
+			}
+			data, err := os.ReadFile(tt.textfile)
+			if tt.wantErr {
+				return
+			}
+			if err != nil || string(data) != string(m.body) {
+				t.Errorf("textfile = %q, %v, want the served metrics", data, err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+				t.Errorf("%d files in the textfile directory, want the textfile only", len(entries))
+			}
+		})
+	}
+}
+
+func TestPrepareWithUnwritableTextfile(t *testing.T) {
+	dir := t.TempDir()
+	dump := filepath.Join(dir, "dump.yaml")
+	if err := os.WriteFile(dump, []byte("apiVersion: v1\nkind: Node\nmetadata:\n  name: node-a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	metrics := NewMetrics(filepath.Join(dir, "missing", "kubereport.prom"))
+
+	snap, _, err := prepare(context.Background(), Options{FromFiles: []string{dump}, Metrics: metrics}, nil)
+	if err != nil {
+		t.Fatalf("prepare() error = %v, want the run to carry on", err)
+	}
+	if len(snap.Nodes) != 1 || len(metrics.body) == 0 {
+		t.Errorf("got %d nodes and %d bytes of metrics, want the node and its metrics", len(snap.Nodes), len(metrics.body))
+	}
+}
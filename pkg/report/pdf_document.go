@@ -0,0 +1,255 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+const (
+	// pdfTopMargin leaves room for the running header above the content of a page, and
+	// pdfBottomMargin for the footer below it.
+	pdfTopMargin    = 20.0
+	pdfBottomMargin = 20.0
+	// pdfTOCLineHeight is the height of an entry of the table of contents.
+	pdfTOCLineHeight = 8.0
+	// pdfTOCHeading is the height taken by the heading of a page of the table of contents.
+	pdfTOCHeading = 20.0
+	// pdfHeadingHeight is the height of the heading of a section, and pdfHeadingKeep the room
+	// below it that must fit on the same page, so that a heading is not left alone at the bottom.
+	pdfHeadingHeight = 10.0
+	pdfHeadingKeep   = 20.0
+)
+
+// pdfDocument lays out a PDF report around its sections: a cover page, a table of contents
+// with the page of every section, a bookmark per section in the document outline, and on
+// every page after the cover a header with the cluster and generation time and a footer with
+// the page number. Its branding, paper and colours come from a theme.
+type pdfDocument struct {
+	pdf     *table.PDF
+	primary [3]int
+
+	// tocPage is the first page of the table of contents and tocPages their number. They are
+	// left blank until close, once the page of every section is known.
+	tocPage, tocPages int
+	entries           []pdfEntry
+	// coverEnd is the position below the text of the cover page, where close notes characters
+	// that could not be drawn.
+	coverEnd float64
+}
+
+// pdfEntry is a section in the table of contents.
+type pdfEntry struct {
+	title string
+	page  int
+	link  int
+}
+
+// newPDFDocument starts the PDF of the general or detailed report with its cover page and
+// room for a table of contents of up to sections entries, plus one for the collection
+// errors. The first section starts on the page after the table of contents.
+func newPDFDocument(theme *Theme, detailed bool, clusterName string, generatedAt, syncedAt time.Time, sections int) *pdfDocument {
+	title := theme.Title
+	if detailed {
+		title = theme.DetailedTitle
+	}
+	fpdf := gofpdf.New(theme.pdfOrientation(detailed), "mm", theme.Paper, "")
+	fauxBold := theme.addPDFFont(fpdf)
+	pdf := table.NewPDF(fpdf, table.PDFStyle{
+		Font:       pdfFontFamily,
+		FauxBold:   fauxBold,
+		HeaderFill: rgb(theme.Colors.TableHeader),
+		Accent:     rgb(theme.Colors.Accent),
+	})
+	d := &pdfDocument{pdf: pdf, primary: rgb(theme.Colors.Primary)}
+
+	left, _, right, _ := pdf.GetMargins()
+	pdf.SetMargins(left, pdfTopMargin, right)
+	pdf.SetAutoPageBreak(true, pdfBottomMargin)
+	pdf.AliasNbPages("")
+
+	generated := generatedAt.Format("02-01-2006 15:04")
+	pdf.SetHeaderFuncMode(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(8)
+		table.SetPDFFont(pdf, "", 8)
+		pdf.SetTextColor(90, 98, 117)
+		pdf.CellFormat(0, 5, table.PDFText(pdf, fmt.Sprintf("%s | Cluster %s", title, clusterName)), "", 0, "L", false, 0, "")
+		pdf.SetX(left)
+		pdf.CellFormat(0, 5, "Generated "+generated, "", 0, "R", false, 0, "")
+		pdf.SetDrawColor(d.primary[0], d.primary[1], d.primary[2])
+		pageWidth, _ := pdf.GetPageSize()
+		pdf.Line(left, 14, pageWidth-right, 14)
+		pdf.SetDrawColor(0, 0, 0)
+		pdf.SetTextColor(0, 0, 0)
+	}, true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		table.SetPDFFont(pdf, "", 8)
+		pdf.SetTextColor(90, 98, 117)
+		if theme.Footer != "" {
+			pdf.CellFormat(0, 5, table.PDFText(pdf, theme.Footer), "", 0, "L", false, 0, "")
+			pdf.SetX(left)
+		}
+		if pdf.PageNo() > 1 {
+			align := "C"
+			if theme.Footer != "" {
+				align = "R"
+			}
+			pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, align, false, 0, "")
+		}
+		pdf.SetTextColor(0, 0, 0)
+	})
+
+	// Cover page
+	pdf.AddPage()
+	if theme.logo != nil {
+		pdf.RegisterImageOptionsReader("logo", gofpdf.ImageOptions{ImageType: theme.logoType}, bytes.NewReader(theme.logo))
+		pdf.ImageOptions("logo", left, pdfTopMargin, 0, 20, false, gofpdf.ImageOptions{ImageType: theme.logoType}, 0, "")
+	}
+	_, pageHeight := pdf.GetPageSize()
+	pdf.SetY(pageHeight / 3)
+	d.primaryText()
+	table.SetPDFFont(pdf, "B", 28)
+	pdf.CellFormat(0, 14, table.PDFText(pdf, theme.Brand), "", 1, "L", false, 0, "")
+	table.SetPDFFont(pdf, "B", 20)
+	pdf.MultiCell(0, 12, table.PDFText(pdf, title), "", "L", false)
+	d.plainText()
+	pdf.Ln(8)
+	table.SetPDFFont(pdf, "", 13)
+	pdf.CellFormat(0, 8, table.PDFText(pdf, "Cluster: "+clusterName), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 8, "Generated: "+generated, "", 1, "L", false, 0, "")
+	if !syncedAt.IsZero() {
+		pdf.CellFormat(0, 8, fmt.Sprintf("Rendered from watch cache, last listed %s", syncedAt.Format("02-01-2006 15:04")), "", 1, "L", false, 0, "")
+	}
+	d.coverEnd = pdf.GetY()
+
+	// Table of contents, filled in by close
+	d.tocPage = pdf.PageCount() + 1
+	d.tocPages = int(math.Ceil(float64(sections+1) / float64(d.tocEntriesPerPage())))
+	for i := 0; i < d.tocPages; i++ {
+		pdf.AddPage()
+	}
+	return d
+}
+
+// tocEntriesPerPage returns the number of entries that fit on a page of the table of contents.
+func (d *pdfDocument) tocEntriesPerPage() int {
+	_, pageHeight := d.pdf.GetPageSize()
+	return max(int((pageHeight-pdfTopMargin-pdfBottomMargin-pdfTOCHeading)/pdfTOCLineHeight), 1)
+}
+
+// heading draws the heading of a section at the current position, or at the top of the next
+// page if it would not fit with the start of the section below it, and adds the section to the
+// table of contents and to the document outline.
+func (d *pdfDocument) heading(name string) {
+	pdf := d.pdf
+	_, pageHeight := pdf.GetPageSize()
+	if _, margin := pdf.GetAutoPageBreak(); pdf.GetY()+pdfHeadingHeight+pdfHeadingKeep > pageHeight-margin {
+		pdf.AddPage()
+	}
+
+	title := table.PDFText(pdf, strings.TrimSpace(strings.Trim(strings.TrimSpace(name), "[]")))
+	link := pdf.AddLink()
+	pdf.SetLink(link, -1, -1)
+	pdf.Bookmark(title, 0, -1)
+	d.entries = append(d.entries, pdfEntry{title: title, page: pdf.PageNo(), link: link})
+
+	table.SetPDFFont(pdf, "B", 15)
+	d.primaryText()
+	pdf.Cell(0, pdfHeadingHeight, table.PDFText(pdf, name))
+	d.plainText()
+}
+
+// rule draws a thin line in the primary colour across the text width at the current position,
+// as under the page header, to close a section.
+func (d *pdfDocument) rule() {
+	pdf := d.pdf
+	left, _, right, _ := pdf.GetMargins()
+	pageWidth, _ := pdf.GetPageSize()
+	y := pdf.GetY()
+	pdf.SetDrawColor(d.primary[0], d.primary[1], d.primary[2])
+	pdf.Line(left, y, pageWidth-right, y)
+	pdf.SetDrawColor(0, 0, 0)
+}
+
+// close draws the table of contents into the pages kept for it, notes on the cover page when
+// characters were drawn as white squares, and saves the PDF to path.
+func (d *pdfDocument) close(path string) error {
+	pdf := d.pdf
+	last := pdf.PageCount()
+	auto, margin := pdf.GetAutoPageBreak()
+	pdf.SetAutoPageBreak(false, margin)
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	width := pageWidth - left - right
+	perPage := d.tocEntriesPerPage()
+	for i, e := range d.entries {
+		if i/perPage >= d.tocPages {
+			break
+		}
+		if i%perPage == 0 {
+			pdf.SetPage(d.tocPage + i/perPage)
+			pdf.SetXY(left, pdfTopMargin)
+			// gofpdf only selects a font on the page when it differs from the current one, which
+			// was set on another page, so the heading font is always switched to.
+			table.SetPDFFont(pdf, "", 11)
+			table.SetPDFFont(pdf, "B", 18)
+			d.primaryText()
+			pdf.CellFormat(0, 12, "Contents", "", 1, "L", false, 0, "")
+			d.plainText()
+			pdf.SetY(pdfTopMargin + pdfTOCHeading)
+		}
+
+		table.SetPDFFont(pdf, "", 11)
+		page := fmt.Sprintf("%d", e.page)
+		pageWidth := pdf.GetStringWidth(page) + 2
+		title := []rune(e.title)
+		for len(title) > 0 && pdf.GetStringWidth(string(title)+" ") > width-pageWidth-10 {
+			title = title[:len(title)-1]
+		}
+		dots := ""
+		if room := width - pageWidth - pdf.GetStringWidth(string(title)+" ") - 2; room > 0 {
+			dots = strings.Repeat(".", int(room/pdf.GetStringWidth(".")))
+		}
+
+		y := pdf.GetY()
+		pdf.SetX(left)
+		pdf.CellFormat(width-pageWidth, pdfTOCLineHeight, string(title)+" "+dots, "", 0, "L", false, 0, "")
+		pdf.CellFormat(pageWidth, pdfTOCLineHeight, page, "", 1, "R", false, 0, "")
+		pdf.Link(left, y, width, pdfTOCLineHeight, e.link)
+	}
+
+	if pdf.Replaced() {
+		pdf.SetPage(1)
+		pdf.SetXY(left, d.coverEnd+4)
+		table.SetPDFFont(pdf, "I", 10)
+		pdf.SetTextColor(90, 98, 117)
+		pdf.MultiCell(0, 5, "Characters the PDF font cannot encode, such as most emoji, are drawn as \u25A1. The other report formats keep them as written.", "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	pdf.SetPage(last)
+	pdf.SetAutoPageBreak(auto, margin)
+	return pdf.OutputFileAndClose(path)
+}
+
+// primaryText draws text in the primary colour, outlined in it too when bold is faux.
+func (d *pdfDocument) primaryText() {
+	d.pdf.SetTextColor(d.primary[0], d.primary[1], d.primary[2])
+	d.pdf.SetDrawColor(d.primary[0], d.primary[1], d.primary[2])
+}
+
+// plainText draws text and lines in black again.
+func (d *pdfDocument) plainText() {
+	d.pdf.SetTextColor(0, 0, 0)
+	d.pdf.SetDrawColor(0, 0, 0)
+}
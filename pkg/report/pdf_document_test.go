@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPDFDocumentContents(t *testing.T) {
+	perPage := newPDFDocument(DefaultTheme(), false, "test", time.Now(), time.Time{}, 0).tocEntriesPerPage()
+	tests := []struct {
+		name         string
+		sections     int
+		wantTOCPages int
+	}{
+		{name: "one section", sections: 1, wantTOCPages: 1},
+		{name: "contents fill a page with the errors entry", sections: perPage - 1, wantTOCPages: 1},
+		{name: "errors entry on a second page", sections: perPage, wantTOCPages: 2},
+		{name: "many sections", sections: 2*perPage + 5, wantTOCPages: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newPDFDocument(DefaultTheme(), false, "test", time.Now(), time.Time{}, tt.sections)
+			if d.tocPage != 2 || d.tocPages != tt.wantTOCPages {
+				t.Fatalf("contents on %d pages from page %d, want %d from page 2", d.tocPages, d.tocPage, tt.wantTOCPages)
+			}
+
+			for i := 0; i < tt.sections; i++ {
+				d.pdf.AddPage()
+				d.heading(fmt.Sprintf("[ SECTION %d ]", i))
+			}
+			for i, e := range d.entries {
+				if want := 1 + tt.wantTOCPages + i + 1; e.page != want {
+					t.Errorf("entry %q on page %d, want %d", e.title, e.page, want)
+				}
+				if want := fmt.Sprintf("SECTION %d", i); e.title != want {
+					t.Errorf("entry %d titled %q, want %q", i, e.title, want)
+				}
+			}
+
+			path := filepath.Join(t.TempDir(), "report.pdf")
+			if err := d.close(path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.Count(data, []byte("/Subtype /Link")); got != tt.sections {
+				t.Errorf("%d contents links, want %d", got, tt.sections)
+			}
+			if got := bytes.Count(data, []byte("/Parent")) - bytes.Count(data, []byte("/Type /Page\n")); got != tt.sections {
+				t.Errorf("%d bookmarks, want %d", got, tt.sections)
+			}
+		})
+	}
+}
+
+func TestPDFDocumentHeadingKeepsSectionStart(t *testing.T) {
+	d := newPDFDocument(DefaultTheme(), false, "test", time.Now(), time.Time{}, 1)
+	d.pdf.AddPage()
+	_, pageHeight := d.pdf.GetPageSize()
+	d.pdf.SetY(pageHeight - pdfBottomMargin - pdfHeadingHeight)
+	page := d.pdf.PageNo()
+
+	d.heading("Late section")
+	if got := d.entries[0].page; got != page+1 {
+		t.Errorf("heading near the bottom margin drawn on page %d, want %d", got, page+1)
+	}
+}
+
+func TestPDFDocumentNotesReplacedCharacters(t *testing.T) {
+	// utf16 returns text as the UTF-16 gofpdf writes text in an embedded font with.
+	utf16 := func(text string) []byte {
+		var b []byte
+		for _, r := range text {
+			b = append(b, byte(r>>8), byte(r))
+		}
+		return b
+	}
+	tests := []struct {
+		name     string
+		heading  string
+		wantNote bool
+	}{
+		{name: "plain headings", heading: "Pods"},
+		{name: "emoji in a heading", heading: "Pods 🚀", wantNote: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newPDFDocument(DefaultTheme(), false, "test", time.Now(), time.Time{}, 1)
+			d.pdf.SetCompression(false)
+			d.pdf.AddPage()
+			d.heading(tt.heading)
+			if got := d.pdf.Replaced(); got != tt.wantNote {
+				t.Errorf("Replaced() = %v, want %v", got, tt.wantNote)
+			}
+
+			path := filepath.Join(t.TempDir(), "report.pdf")
+			if err := d.close(path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.Contains(data, utf16("such as most emoji")); got != tt.wantNote {
+				t.Errorf("cover note drawn = %v, want %v", got, tt.wantNote)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package section
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+)
+
+// Result is what a section's Collect step returned.
+type Result struct {
+	Data any
+	Err  error
+}
+
+// CollectAll runs the Collect step of every section over a bounded pool of workers, each under
+// its own timeout (zero means none). Results are returned in the order of sections.
+func CollectAll(ctx context.Context, sections []Section, clients Clients, snap *snapshot.ClusterSnapshot, workers int, timeout time.Duration) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]Result, len(sections))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				results[idx] = collect(ctx, sections[idx], clients, snap, timeout)
+			}
+		}()
+	}
+
+	for idx := range sections {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func collect(ctx context.Context, s Section, clients Clients, snap *snapshot.ClusterSnapshot, timeout time.Duration) Result {
+	if err := ctx.Err(); err != nil {
+		return Result{Err: err}
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	data, err := s.Collect(ctx, clients, snap)
+	if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return Result{Data: data, Err: err}
+}
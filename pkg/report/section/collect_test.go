@@ -0,0 +1,145 @@
+package section
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+)
+
+// collectSection is a section whose Collect step is a function.
+type collectSection struct {
+	name    string
+	collect func(ctx context.Context) (any, error)
+}
+
+func (s collectSection) Name() string                 { return s.name }
+func (collectSection) Dependencies() []Dependency     { return nil }
+func (collectSection) Resources() []snapshot.Resource { return nil }
+func (s collectSection) Collect(ctx context.Context, _ Clients, _ *snapshot.ClusterSnapshot) (any, error) {
+	return s.collect(ctx)
+}
+
+func TestCollectAll(t *testing.T) {
+	errFailed := errors.New("failed")
+	returns := func(data any, err error) func(context.Context) (any, error) {
+		return func(context.Context) (any, error) { return data, err }
+	}
+	blocks := func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	tests := []struct {
+		name     string
+		collects []func(context.Context) (any, error)
+		workers  int
+		timeout  time.Duration
+		want     []Result
+		wantErrs []string
+	}{
+		{
+			name:     "results in section order",
+			collects: []func(context.Context) (any, error){returns(1, nil), returns(2, nil), returns(3, nil)},
+			workers:  3,
+			want:     []Result{{Data: 1}, {Data: 2}, {Data: 3}},
+		},
+		{
+			name:     "a failure leaves the others",
+			collects: []func(context.Context) (any, error){returns(1, nil), returns(nil, errFailed), returns(3, nil)},
+			workers:  2,
+			want:     []Result{{Data: 1}, {Err: errFailed}, {Data: 3}},
+		},
+		{
+			name:     "no workers means one",
+			collects: []func(context.Context) (any, error){returns(1, nil), returns(2, nil)},
+			want:     []Result{{Data: 1}, {Data: 2}},
+		},
+		{
+			name:     "timeout per section",
+			collects: []func(context.Context) (any, error){blocks, returns(2, nil)},
+			workers:  2,
+			timeout:  10 * time.Millisecond,
+			wantErrs: []string{"timed out after 10ms", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sections []Section
+			for i, c := range tt.collects {
+				sections = append(sections, collectSection{name: fmt.Sprint(i), collect: c})
+			}
+			results := CollectAll(context.Background(), sections, Clients{}, nil, tt.workers, tt.timeout)
+			if len(results) != len(sections) {
+				t.Fatalf("%d results for %d sections", len(results), len(sections))
+			}
+			for i, r := range results {
+				if tt.want != nil && (r.Data != tt.want[i].Data || !errors.Is(r.Err, tt.want[i].Err)) {
+					t.Errorf("result %d = %+v, want %+v", i, r, tt.want[i])
+				}
+				if tt.wantErrs != nil {
+					var got string
+					if r.Err != nil {
+						got = r.Err.Error()
+					}
+					if got != tt.wantErrs[i] {
+						t.Errorf("result %d error = %q, want %q", i, got, tt.wantErrs[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCollectAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	sections := []Section{collectSection{name: "a", collect: func(context.Context) (any, error) {
+		called = true
+		return nil, nil
+	}}}
+	results := CollectAll(ctx, sections, Clients{}, nil, 1, 0)
+	if !errors.Is(results[0].Err, context.Canceled) {
+		t.Errorf("error = %v, want %v", results[0].Err, context.Canceled)
+	}
+	if called {
+		t.Error("Collect was called after the run was cancelled")
+	}
+}
+
+func TestCollectAllBoundsWorkers(t *testing.T) {
+	const workers = 3
+	var (
+		mu            sync.Mutex
+		running, peak int
+	)
+	collect := func(context.Context) (any, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil, nil
+	}
+
+	var sections []Section
+	for i := 0; i < 12; i++ {
+		sections = append(sections, collectSection{name: fmt.Sprint(i), collect: collect})
+	}
+	CollectAll(context.Background(), sections, Clients{}, nil, workers, 0)
+	if peak > workers {
+		t.Errorf("%d sections collected at once, want at most %d", peak, workers)
+	}
+	if peak < 2 {
+		t.Errorf("sections were not collected concurrently")
+	}
+}
@@ -0,0 +1,66 @@
+package section
+
+import (
+	"context"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/chart"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+// Func adapts a function that builds tables from the shared snapshot alone to a TableSection.
+// A nil function renders nothing but the section title. Sections that collect data of their
+// own implement the interfaces directly.
+type Func struct {
+	Title string
+	// Reads lists the snapshot resources the section is rendered from.
+	Reads []snapshot.Resource
+
+	Build func(snap *snapshot.ClusterSnapshot) ([]*table.Table, error)
+	// Charts returns the charts drawn below the tables in PDF reports. Nil draws none.
+	Charts func(snap *snapshot.ClusterSnapshot) []chart.Chart
+}
+
+func (f Func) Name() string { return f.Title }
+
+func (f Func) Resources() []snapshot.Resource { return f.Reads }
+
+// Dependencies returns the clients the snapshot resources in Reads are listed with.
+func (f Func) Dependencies() []Dependency { return readDependencies(f.Reads) }
+
+func (f Func) Collect(context.Context, Clients, *snapshot.ClusterSnapshot) (any, error) {
+	return nil, nil
+}
+
+func (f Func) Tables(snap *snapshot.ClusterSnapshot, _ any) ([]*table.Table, error) {
+	if f.Build == nil {
+		return nil, nil
+	}
+	return f.Build(snap)
+}
+
+// RenderPDF draws the tables of the section followed by its charts.
+func (f Func) RenderPDF(pdf *table.PDF, snap *snapshot.ClusterSnapshot, data any) error {
+	tables, err := f.Tables(snap, data)
+	if err != nil {
+		return err
+	}
+	table.WritePDF(pdf, tables...)
+	if f.Charts != nil {
+		chart.WritePDF(pdf, f.Charts(snap)...)
+	}
+	return nil
+}
+
+// readDependencies returns the clients the given snapshot resources are listed with.
+func readDependencies(reads []snapshot.Resource) []Dependency {
+	var deps []Dependency
+	seen := make(map[Dependency]bool)
+	for _, resource := range reads {
+		if dep := ResourceDependency(resource); !seen[dep] {
+			seen[dep] = true
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
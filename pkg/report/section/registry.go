@@ -0,0 +1,57 @@
+package section
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Report identifies the report a section belongs to.
+type Report string
+
+const (
+	// General is the summary report rendered as PDF.
+	General Report = "general"
+	// Detailed is the per-resource report rendered as CSV.
+	Detailed Report = "detailed"
+)
+
+type entry struct {
+	order   int
+	section Section
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[Report][]entry)
+)
+
+// Register adds a section to a report. Sections are rendered by ascending order, and in
+// registration order when two share the same value; the built-in sections use multiples of ten.
+// Register panics if the report already has a section with the same name.
+func Register(report Report, order int, s Section) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, e := range registry[report] {
+		if e.section.Name() == s.Name() {
+			panic(fmt.Sprintf("section: %q registered twice in the %s report", s.Name(), report))
+		}
+	}
+
+	entries := append(registry[report], entry{order, s})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].order < entries[j].order })
+	registry[report] = entries
+}
+
+// For returns the sections of a report in render order.
+func For(report Report) []Section {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	sections := make([]Section, 0, len(registry[report]))
+	for _, e := range registry[report] {
+		sections = append(sections, e.section)
+	}
+	return sections
+}
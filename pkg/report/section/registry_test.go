@@ -0,0 +1,125 @@
+package section
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRegister(t *testing.T) {
+	type registration struct {
+		order int
+		name  string
+	}
+	tests := []struct {
+		name          string
+		registrations []registration
+		want          []string
+	}{
+		{
+			name:          "ascending order",
+			registrations: []registration{{30, "c"}, {10, "a"}, {20, "b"}},
+			want:          []string{"a", "b", "c"},
+		},
+		{
+			name:          "ties keep registration order",
+			registrations: []registration{{10, "first"}, {10, "second"}, {5, "before"}, {10, "third"}},
+			want:          []string{"before", "first", "second", "third"},
+		},
+		{
+			name:          "between built-in sections",
+			registrations: []registration{{10, "nodes"}, {20, "pods"}, {15, "custom"}},
+			want:          []string{"nodes", "custom", "pods"},
+		},
+		{name: "empty report"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Report("test " + tt.name)
+			for _, r := range tt.registrations {
+				Register(report, r.order, Func{Title: r.name})
+			}
+			var got []string
+			for _, s := range For(report) {
+				got = append(got, s.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("For() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	report := Report("test twice")
+	Register(report, 10, Func{Title: "pods"})
+	Register(Report("test other"), 10, Func{Title: "pods"})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a section name twice in a report did not panic")
+		}
+	}()
+	Register(report, 20, Func{Title: "pods"})
+}
+
+func TestResourceDependency(t *testing.T) {
+	tests := []struct {
+		resource snapshot.Resource
+		want     Dependency
+	}{
+		{snapshot.Pods, CoreClient},
+		{snapshot.NodeMetrics, MetricsClient},
+		{snapshot.Deployments, CoreClient},
+		{snapshot.MetadataOf(snapshot.Deployments), MetadataClient},
+		{snapshot.MetadataOf(snapshot.Secrets), CoreClient},
+		{snapshot.MetadataOf(snapshot.ConfigMaps), CoreClient},
+	}
+	for _, tt := range tests {
+		if got := ResourceDependency(tt.resource); got != tt.want {
+			t.Errorf("ResourceDependency(%s) = %s, want %s", tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	core := Clients{Core: fake.NewSimpleClientset()}
+	tests := []struct {
+		name    string
+		clients Clients
+		deps    []Dependency
+		wantErr string
+	}{
+		{name: "nothing required", clients: Clients{}},
+		{name: "available", clients: core, deps: []Dependency{CoreClient}},
+		{name: "missing", clients: core, deps: []Dependency{CoreClient, MetricsClient}, wantErr: "metrics client is not available"},
+		{name: "offline", clients: Clients{}, deps: []Dependency{MetadataClient}, wantErr: "metadata client is not available"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.clients.Require(tt.deps...)
+			var got string
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.wantErr {
+				t.Errorf("Require() = %q, want %q", got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFuncDependencies(t *testing.T) {
+	f := Func{Title: "pods", Reads: []snapshot.Resource{snapshot.Pods, snapshot.Nodes, snapshot.NodeMetrics}}
+	want := []Dependency{CoreClient, MetricsClient}
+	if got := f.Dependencies(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependencies() = %v, want %v", got, want)
+	}
+	if data, err := f.Collect(context.Background(), Clients{}, nil); data != nil || err != nil {
+		t.Errorf("Collect() = %v, %v, want nothing", data, err)
+	}
+}
@@ -0,0 +1,78 @@
+package section
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+// Rows adapts a section made of a single table, whose rows are produced one at a time from the
+// shared snapshot, to a StreamSection. Sections listing one row per object of a large collection
+// use it so that the detailed report can be written without holding their tables in memory.
+type Rows struct {
+	Title string
+	// Reads lists the snapshot resources the section is rendered from.
+	Reads   []snapshot.Resource
+	Columns []table.Column
+	// Order sorts the rows; nil keeps them in snapshot order.
+	Order table.Less
+
+	// Each passes the values of every row to emit, stopping at the first error emit returns.
+	Each func(snap *snapshot.ClusterSnapshot, emit func(values ...any) error) error
+}
+
+func (r Rows) Name() string { return r.Title }
+
+func (r Rows) Resources() []snapshot.Resource { return r.Reads }
+
+// Dependencies returns the clients the snapshot resources in Reads are listed with.
+func (r Rows) Dependencies() []Dependency { return readDependencies(r.Reads) }
+
+func (r Rows) Collect(context.Context, Clients, *snapshot.ClusterSnapshot) (any, error) {
+	return nil, nil
+}
+
+// Tables builds the whole table in memory and sorts it.
+func (r Rows) Tables(snap *snapshot.ClusterSnapshot, _ any) ([]*table.Table, error) {
+	t := table.New("", r.Columns...)
+	err := r.Each(snap, func(values ...any) error {
+		t.AddRow(values...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if r.Order != nil {
+		sort.SliceStable(t.Rows, func(i, j int) bool { return r.Order(t.Rows[i], t.Rows[j]) })
+	}
+	return []*table.Table{t}, nil
+}
+
+// Stream hands the rows to w as they are produced, through a Sorter holding at most sortBuffer
+// rows in memory when the section has an Order. A sortBuffer of zero leaves the rows unsorted.
+func (r Rows) Stream(snap *snapshot.ClusterSnapshot, _ any, w table.RowWriter, sortBuffer int) error {
+	if r.Order != nil && sortBuffer > 0 {
+		sorter := table.NewSorter(w, r.Order, sortBuffer)
+		// The rows spilled to disk are removed however the table ends.
+		defer sorter.Close()
+		w = sorter
+	}
+
+	t := table.New("", r.Columns...)
+	if err := w.Begin(t); err != nil {
+		return err
+	}
+	row := table.New("", r.Columns...)
+	err := r.Each(snap, func(values ...any) error {
+		row.AddRow(values...)
+		next := row.Rows[0]
+		row.Rows = row.Rows[:0]
+		return w.Row(next)
+	})
+	if err != nil {
+		return err
+	}
+	return w.End(t)
+}
@@ -0,0 +1,92 @@
+package section
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+func TestRowsStreamRemovesRunsOnError(t *testing.T) {
+	errEach := errors.New("each failed")
+	errRow := errors.New("row failed")
+
+	tests := []struct {
+		name    string
+		failAt  int
+		w       table.RowWriter
+		wantErr error
+	}{
+		{name: "rows fail", failAt: 5, w: &table.Collector{}, wantErr: errEach},
+		{name: "writer fails", failAt: -1, w: &failingWriter{err: errRow}, wantErr: errRow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			r := Rows{
+				Columns: []table.Column{{Header: "N", Kind: table.Integer}},
+				Order:   table.ByColumn(0, true),
+				Each: func(_ *snapshot.ClusterSnapshot, emit func(values ...any) error) error {
+					for i := 0; i < 10; i++ {
+						if i == tt.failAt {
+							return errEach
+						}
+						if err := emit(i); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+			}
+			if err := r.Stream(&snapshot.ClusterSnapshot{}, nil, tt.w, 2); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Stream() error = %v, want %v", err, tt.wantErr)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("%d run files left in %s", len(entries), dir)
+			}
+		})
+	}
+}
+
+func TestRowsStreamSorts(t *testing.T) {
+	r := Rows{
+		Columns: []table.Column{{Header: "N", Kind: table.Integer}},
+		Order:   table.ByColumn(0, true),
+		Each: func(_ *snapshot.ClusterSnapshot, emit func(values ...any) error) error {
+			for _, n := range []int{3, 1, 4, 1, 5} {
+				if err := emit(n); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	for _, sortBuffer := range []int{0, 2, 100} {
+		c := &table.Collector{}
+		if err := r.Stream(&snapshot.ClusterSnapshot{}, nil, c, sortBuffer); err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		want := []int64{5, 4, 3, 1, 1}
+		if sortBuffer == 0 {
+			want = []int64{3, 1, 4, 1, 5}
+		}
+		for i, row := range c.Tables[0].Rows {
+			if row[0] != want[i] {
+				t.Errorf("sortBuffer %d: row %d = %v, want %d", sortBuffer, i, row[0], want[i])
+			}
+		}
+	}
+}
+
+// failingWriter is a RowWriter that fails on the first row, which a Sorter only passes on once
+// its rows have been spilled and are being merged.
+type failingWriter struct {
+	table.Collector
+	err error
+}
+
+func (w *failingWriter) Row(table.Row) error { return w.err }
@@ -0,0 +1,171 @@
+// Package section defines the building blocks of a report and the registry they are kept in.
+package section
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// Dependency names an API client a section needs.
+type Dependency string
+
+const (
+	CoreClient     Dependency = "core"
+	MetricsClient  Dependency = "metrics"
+	DynamicClient  Dependency = "dynamic"
+	MetadataClient Dependency = "metadata"
+)
+
+// ResourceDependency returns the client used to list a snapshot resource.
+func ResourceDependency(resource snapshot.Resource) Dependency {
+	if resource == snapshot.NodeMetrics {
+		return MetricsClient
+	}
+	if base, ok := snapshot.MetadataBase(resource); ok && !snapshot.ListedAsTable(base) {
+		return MetadataClient
+	}
+	return CoreClient
+}
+
+// Clients holds the API clients a run was able to create. Fields for dependencies no section
+// declared, and all of them when rendering offline, are nil.
+type Clients struct {
+	Core     kubernetes.Interface
+	Metrics  metricsv.Interface
+	Dynamic  dynamic.Interface
+	Metadata metadata.Interface
+}
+
+// Require returns an error unless every given client is available.
+func (c Clients) Require(deps ...Dependency) error {
+	for _, dep := range deps {
+		var ok bool
+		switch dep {
+		case CoreClient:
+			ok = c.Core != nil
+		case MetricsClient:
+			ok = c.Metrics != nil
+		case DynamicClient:
+			ok = c.Dynamic != nil
+		case MetadataClient:
+			ok = c.Metadata != nil
+		}
+		if !ok {
+			return fmt.Errorf("%s client is not available", dep)
+		}
+	}
+	return nil
+}
+
+// Section is one titled part of a report.
+type Section interface {
+	// Name is the title the section is rendered under. It must be unique within a report.
+	Name() string
+	// Dependencies lists the API clients the section needs.
+	Dependencies() []Dependency
+	// Resources lists what the section reads from the shared snapshot. They are collected once
+	// per run for all sections, and the section is rendered as unavailable if any of them fails.
+	Resources() []snapshot.Resource
+	// Collect gathers anything the section needs beyond the snapshot. Whatever it returns is
+	// handed back to the render step.
+	Collect(ctx context.Context, clients Clients, snap *snapshot.ClusterSnapshot) (any, error)
+}
+
+// TableSection is a section whose content is a set of tables. It can be rendered in every
+// report format.
+type TableSection interface {
+	Section
+	Tables(snap *snapshot.ClusterSnapshot, data any) ([]*table.Table, error)
+}
+
+// StreamSection is a table section that can hand its rows to a RowWriter one at a time instead
+// of building its tables in memory. sortBuffer bounds the number of rows held in memory to sort
+// them; zero leaves them in the order they are produced.
+type StreamSection interface {
+	TableSection
+	Stream(snap *snapshot.ClusterSnapshot, data any, w table.RowWriter, sortBuffer int) error
+}
+
+// PDFSection is a section that draws itself into PDF reports. When a section is also a
+// TableSection, RenderPDF is used for PDF output instead of the generic table renderer.
+type PDFSection interface {
+	Section
+	RenderPDF(pdf *table.PDF, snap *snapshot.ClusterSnapshot, data any) error
+}
+
+// CSVSection is a section that writes itself into CSV reports. When a section is also a
+// TableSection, RenderCSV is used for CSV output instead of the generic table renderer. It
+// writes through the table writer of the report, with Record or table.Write, so that its cells
+// are escaped the way the rest of the report is.
+type CSVSection interface {
+	Section
+	RenderCSV(writer *table.CSVWriter, snap *snapshot.ClusterSnapshot, data any) error
+}
+
+// RenderPDF draws a section into a PDF, through its own RenderPDF if it has one and from its
+// tables otherwise.
+func RenderPDF(s Section, pdf *table.PDF, snap *snapshot.ClusterSnapshot, data any) error {
+	if ps, ok := s.(PDFSection); ok {
+		return ps.RenderPDF(pdf, snap, data)
+	}
+	ts, ok := s.(TableSection)
+	if !ok {
+		return fmt.Errorf("section %q cannot be rendered as PDF", s.Name())
+	}
+	tables, err := ts.Tables(snap, data)
+	if err != nil {
+		return err
+	}
+	table.WritePDF(pdf, tables...)
+	return nil
+}
+
+// RenderCSV writes a section into a CSV, through its own RenderCSV if it has one and from its
+// tables otherwise.
+func RenderCSV(s Section, writer *table.CSVWriter, snap *snapshot.ClusterSnapshot, data any) error {
+	if cs, ok := s.(CSVSection); ok {
+		return cs.RenderCSV(writer, snap, data)
+	}
+	ts, ok := s.(TableSection)
+	if !ok {
+		return fmt.Errorf("section %q cannot be rendered as CSV", s.Name())
+	}
+	tables, err := ts.Tables(snap, data)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := table.Write(writer, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TablesOf returns the tables of a section, for formats that can only render tables.
+func TablesOf(s Section, snap *snapshot.ClusterSnapshot, data any) ([]*table.Table, error) {
+	ts, ok := s.(TableSection)
+	if !ok {
+		return nil, fmt.Errorf("section %q has no tables", s.Name())
+	}
+	return ts.Tables(snap, data)
+}
+
+// ResourceErr returns the collection error of the first snapshot resource the section reads
+// that could not be collected, or nil if all of them were.
+func ResourceErr(s Section, snap *snapshot.ClusterSnapshot) error {
+	for _, resource := range s.Resources() {
+		if err := snap.Err(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
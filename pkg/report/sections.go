@@ -0,0 +1,52 @@
+package report
+
+import (
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+)
+
+// Section, Clients and the render interfaces are re-exported so that code importing this
+// package can add its own sections to the built-in reports.
+type (
+	Section      = section.Section
+	TableSection = section.TableSection
+	PDFSection   = section.PDFSection
+	CSVSection   = section.CSVSection
+	Clients      = section.Clients
+	SectionFunc  = section.Func
+)
+
+// Reports a section can be registered in.
+const (
+	GeneralReport  = section.General
+	DetailedReport = section.Detailed
+)
+
+// RegisterSection adds a section to the general (PDF) or detailed (CSV) report. Sections are
+// rendered by ascending order; the built-in ones use multiples of ten, so a section registered
+// with order 15 is rendered between the first and second built-in section.
+func RegisterSection(report section.Report, order int, s Section) {
+	section.Register(report, order, s)
+}
+
+// renderable returns the sections that either build tables or implement the format-specific
+// render interface T.
+func renderable[T section.Section](sections []section.Section) []section.Section {
+	var out []section.Section
+	for _, s := range sections {
+		_, tables := s.(section.TableSection)
+		_, custom := s.(T)
+		if tables || custom {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// reportSections returns the sections rendered in a report: the detailed report as CSV and the
+// general report as PDF.
+func reportSections(report section.Report) []section.Section {
+	if report == section.Detailed {
+		return renderable[section.CSVSection](section.For(section.Detailed))
+	}
+	return renderable[section.PDFSection](section.For(section.General))
+}
@@ -0,0 +1,277 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// cachedTypes holds the type each resource a Cache watches is decoded into.
+var cachedTypes = map[Resource]runtime.Object{
+	Nodes:                    &corev1.Node{},
+	Namespaces:               &corev1.Namespace{},
+	Pods:                     &corev1.Pod{},
+	Services:                 &corev1.Service{},
+	Endpoints:                &corev1.Endpoints{},
+	ConfigMaps:               &corev1.ConfigMap{},
+	Secrets:                  &corev1.Secret{},
+	ServiceAccounts:          &corev1.ServiceAccount{},
+	PersistentVolumes:        &corev1.PersistentVolume{},
+	PersistentVolumeClaims:   &corev1.PersistentVolumeClaim{},
+	ResourceQuotas:           &corev1.ResourceQuota{},
+	LimitRanges:              &corev1.LimitRange{},
+	Deployments:              &appsv1.Deployment{},
+	ReplicaSets:              &appsv1.ReplicaSet{},
+	StatefulSets:             &appsv1.StatefulSet{},
+	DaemonSets:               &appsv1.DaemonSet{},
+	Jobs:                     &batchv1.Job{},
+	CronJobs:                 &batchv1.CronJob{},
+	HorizontalPodAutoscalers: &autoscalingv1.HorizontalPodAutoscaler{},
+	Ingresses:                &networkingv1.Ingress{},
+	NetworkPolicies:          &networkingv1.NetworkPolicy{},
+	StorageClasses:           &storagev1.StorageClass{},
+	Roles:                    &rbacv1.Role{},
+	RoleBindings:             &rbacv1.RoleBinding{},
+	ClusterRoles:             &rbacv1.ClusterRole{},
+	ClusterRoleBindings:      &rbacv1.ClusterRoleBinding{},
+}
+
+// Cache keeps the resources of a snapshot current with shared informers, so that a process
+// rendering reports on a schedule lists the cluster once and then only follows watch events.
+// An informer lists its resource again only when its watch breaks and cannot be resumed.
+//
+// Node metrics, which cannot be watched, and the metadata of ConfigMaps and Secrets, which is
+// listed as a server-side table, are listed afresh by every call to Snapshot. Those lists cost
+// as much on every run as without a cache, growing with the number of nodes, ConfigMaps and
+// Secrets.
+type Cache struct {
+	clientset     kubernetes.Interface
+	metricsClient metricsv.Interface
+	opts          CollectOptions
+
+	informers map[Resource]cache.SharedIndexInformer
+	listed    []Resource
+
+	mu       sync.Mutex
+	listedAt map[Resource]time.Time
+}
+
+// NewCache returns a cache of the given resources. It does nothing until Start is called.
+func NewCache(clientset kubernetes.Interface, metricsClient metricsv.Interface, metadataClient metadata.Interface, opts CollectOptions, resources ...Resource) (*Cache, error) {
+	c := &Cache{
+		clientset:     clientset,
+		metricsClient: metricsClient,
+		opts:          opts,
+		informers:     make(map[Resource]cache.SharedIndexInformer),
+		listedAt:      make(map[Resource]time.Time),
+	}
+
+	requested := make(map[Resource]bool)
+	for _, resource := range resources {
+		requested[resource] = true
+	}
+
+	for resource := range requested {
+		var (
+			lw      *cache.ListWatch
+			example runtime.Object
+		)
+		if base, ok := MetadataBase(resource); ok {
+			gvr, known := metadataGVRs[base]
+			switch {
+			case requested[base]:
+				// The full objects are cached; the metadata is taken from them.
+				continue
+			case ListedAsTable(base):
+				c.listed = append(c.listed, resource)
+				continue
+			case !known:
+				return nil, fmt.Errorf("unknown resource %q", resource)
+			case metadataClient == nil:
+				return nil, fmt.Errorf("metadata client is not available")
+			}
+			client := metadataClient.Resource(gvr).Namespace(metav1.NamespaceAll)
+			lw = &cache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					return client.List(context.TODO(), opts)
+				},
+				WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+					return client.Watch(context.TODO(), opts)
+				},
+			}
+			example = &metav1.PartialObjectMetadata{}
+		} else if resource == NodeMetrics {
+			c.listed = append(c.listed, resource)
+			continue
+		} else {
+			gvr, known := metadataGVRs[resource]
+			if !known {
+				return nil, fmt.Errorf("unknown resource %q", resource)
+			}
+			if clientset == nil {
+				return nil, fmt.Errorf("core client is not available")
+			}
+			lw = cache.NewListWatchFromClient(restClientFor(clientset, gvr.Group), gvr.Resource, metav1.NamespaceAll, fields.Everything())
+			example = cachedTypes[resource]
+		}
+
+		resource := resource
+		list := lw.ListFunc
+		lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+			obj, err := list(opts)
+			if err != nil {
+				return nil, err
+			}
+			// A list is complete once its last page arrives.
+			if accessor, err := apimeta.ListAccessor(obj); err == nil && accessor.GetContinue() == "" {
+				c.mu.Lock()
+				c.listedAt[resource] = time.Now()
+				c.mu.Unlock()
+			}
+			return obj, nil
+		}
+
+		// No periodic resync: the lists are only replaced when a watch cannot be resumed.
+		informer := cache.NewSharedIndexInformer(lw, example, 0, cache.Indexers{})
+		if err := informer.SetTransform(c.transform(resource)); err != nil {
+			return nil, fmt.Errorf("failed to set up %s informer: %v", resource, err)
+		}
+		c.informers[resource] = informer
+	}
+
+	return c, nil
+}
+
+// Start runs the informers until ctx is done and waits for their first lists to complete,
+// for at most CollectOptions.Timeout if one is set. Resources still listing when Start returns
+// are reported as failed by Snapshot until they complete.
+func (c *Cache) Start(ctx context.Context) error {
+	synced := make([]cache.InformerSynced, 0, len(c.informers))
+	for _, informer := range c.informers {
+		go informer.Run(ctx.Done())
+		synced = append(synced, informer.HasSynced)
+	}
+
+	waitCtx := ctx
+	if c.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, c.opts.Timeout)
+		defer cancel()
+	}
+	cache.WaitForCacheSync(waitCtx.Done(), synced...)
+	return ctx.Err()
+}
+
+// Snapshot returns a snapshot of the cached resources as they are now, together with the
+// resources the cache does not watch, which are listed as Collect would.
+func (c *Cache) Snapshot(ctx context.Context) (*ClusterSnapshot, error) {
+	s := &ClusterSnapshot{CollectedAt: time.Now()}
+
+	for resource, informer := range c.informers {
+		if !informer.HasSynced() {
+			err := fmt.Errorf("error fetching %s: watch cache has not synced", resource)
+			if c.opts.FailFast {
+				return nil, err
+			}
+			s.SetErr(resource, err)
+			continue
+		}
+
+		c.mu.Lock()
+		listedAt := c.listedAt[resource]
+		c.mu.Unlock()
+		if s.SyncedAt.IsZero() || listedAt.Before(s.SyncedAt) {
+			s.SyncedAt = listedAt
+		}
+
+		objs := informer.GetStore().List()
+		sort.Slice(objs, func(i, j int) bool {
+			a, _ := cache.MetaNamespaceKeyFunc(objs[i])
+			b, _ := cache.MetaNamespaceKeyFunc(objs[j])
+			return a < b
+		})
+
+		if base, ok := MetadataBase(resource); ok {
+			items := make([]ObjectMetadata, 0, len(objs))
+			for _, obj := range objs {
+				if m, ok := obj.(*metav1.PartialObjectMetadata); ok {
+					items = append(items, ObjectMetadata{ObjectMeta: *m.ObjectMeta.DeepCopy()})
+				}
+			}
+			s.setMetadata(base, items)
+			continue
+		}
+		// The store's objects are shared with the informer and must not be modified; the
+		// snapshot gets copies, which its sections are free to change.
+		for _, obj := range objs {
+			if o, ok := obj.(runtime.Object); ok {
+				s.Add(o.DeepCopyObject())
+			}
+		}
+	}
+
+	if err := collect(ctx, s, c.clientset, c.metricsClient, nil, c.opts, c.listed); err != nil {
+		return nil, err
+	}
+	s.BuildIndexes()
+	return s, nil
+}
+
+// transform returns the function that trims the objects of a resource before they are stored,
+// the way Collect does.
+func (c *Cache) transform(resource Resource) cache.TransformFunc {
+	base, _ := MetadataBase(resource)
+	return func(obj interface{}) (interface{}, error) {
+		switch o := obj.(type) {
+		case *metav1.PartialObjectMetadata:
+			compactMetadata(base, &o.ObjectMeta)
+		case runtime.Object:
+			if c.opts.Compact {
+				Compact(o)
+			} else {
+				stripManagedFields(o)
+			}
+		}
+		return obj, nil
+	}
+}
+
+// restClientFor returns the REST client of the clientset for an API group.
+func restClientFor(cs kubernetes.Interface, group string) rest.Interface {
+	switch group {
+	case "apps":
+		return cs.AppsV1().RESTClient()
+	case "batch":
+		return cs.BatchV1().RESTClient()
+	case "autoscaling":
+		return cs.AutoscalingV1().RESTClient()
+	case "networking.k8s.io":
+		return cs.NetworkingV1().RESTClient()
+	case "storage.k8s.io":
+		return cs.StorageV1().RESTClient()
+	case "rbac.authorization.k8s.io":
+		return cs.RbacV1().RESTClient()
+	default:
+		return cs.CoreV1().RESTClient()
+	}
+}
@@ -0,0 +1,200 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewCache(t *testing.T) {
+	md := metadatafake.NewSimpleMetadataClient(metadatafake.NewTestScheme())
+	tests := []struct {
+		name          string
+		resources     []Resource
+		wantInformers []Resource
+		wantListed    []Resource
+		wantErr       bool
+	}{
+		{
+			name:          "watched resources",
+			resources:     []Resource{Pods, Nodes, Pods},
+			wantInformers: []Resource{Nodes, Pods},
+		},
+		{
+			name:       "node metrics listed on every snapshot",
+			resources:  []Resource{NodeMetrics},
+			wantListed: []Resource{NodeMetrics},
+		},
+		{
+			name:          "metadata watched on its own",
+			resources:     []Resource{MetadataOf(Deployments)},
+			wantInformers: []Resource{MetadataOf(Deployments)},
+		},
+		{
+			name:          "metadata taken from the full objects",
+			resources:     []Resource{MetadataOf(Deployments), Deployments},
+			wantInformers: []Resource{Deployments},
+		},
+		{
+			name:       "metadata listed as a table",
+			resources:  []Resource{MetadataOf(Secrets)},
+			wantListed: []Resource{MetadataOf(Secrets)},
+		},
+		{name: "unknown resource", resources: []Resource{"widgets"}, wantErr: true},
+		{name: "unknown metadata", resources: []Resource{MetadataOf("widgets")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCache(fake.NewSimpleClientset(), nil, md, CollectOptions{}, tt.resources...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewCache succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var informers []Resource
+			for resource := range c.informers {
+				informers = append(informers, resource)
+			}
+			sort.Slice(informers, func(i, j int) bool { return informers[i] < informers[j] })
+			if !reflect.DeepEqual(informers, tt.wantInformers) {
+				t.Errorf("informers for %v, want %v", informers, tt.wantInformers)
+			}
+			if !reflect.DeepEqual(c.listed, tt.wantListed) {
+				t.Errorf("listed %v, want %v", c.listed, tt.wantListed)
+			}
+		})
+	}
+}
+
+func TestNewCacheWithoutClients(t *testing.T) {
+	if _, err := NewCache(nil, nil, nil, CollectOptions{}, Nodes); err == nil {
+		t.Error("NewCache of nodes without a core client succeeded")
+	}
+	if _, err := NewCache(fake.NewSimpleClientset(), nil, nil, CollectOptions{}, MetadataOf(Deployments)); err == nil {
+		t.Error("NewCache of metadata without a metadata client succeeded")
+	}
+}
+
+// nodeServer serves the nodes of a cluster to a list and then the events sent on its channel
+// to a watch.
+type nodeServer struct {
+	nodes  []corev1.Node
+	events chan corev1.Node
+	lists  atomic.Int32
+}
+
+func (s *nodeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v1/nodes" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("watch") != "true" {
+		s.lists.Add(1)
+		json.NewEncoder(w).Encode(corev1.NodeList{
+			TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"},
+			ListMeta: metav1.ListMeta{ResourceVersion: "1"},
+			Items:    s.nodes,
+		})
+		return
+	}
+
+	w.(http.Flusher).Flush()
+	for {
+		select {
+		case node := <-s.events:
+			node.TypeMeta = metav1.TypeMeta{Kind: "Node", APIVersion: "v1"}
+			json.NewEncoder(w).Encode(map[string]any{"type": "ADDED", "object": node})
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func TestCacheFollowsWatch(t *testing.T) {
+	node := func(name, version string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			ResourceVersion: version,
+			ManagedFields:   []metav1.ManagedFieldsEntry{{Manager: "kubelet"}},
+		}}
+	}
+	ns := &nodeServer{nodes: []corev1.Node{node("node-2", "1"), node("node-1", "1")}, events: make(chan corev1.Node)}
+	server := httptest.NewServer(ns)
+	defer server.Close()
+
+	cs, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCache(cs, nil, nil, CollectOptions{Timeout: 10 * time.Second}, Nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(snap *ClusterSnapshot) []string {
+		var names []string
+		for _, n := range snap.Nodes {
+			names = append(names, n.Name)
+			if n.ManagedFields != nil {
+				t.Errorf("%s kept its managed fields", n.Name)
+			}
+		}
+		return names
+	}
+
+	snap, err := c.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(snap), []string{"node-1", "node-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nodes %q, want %q", got, want)
+	}
+	if snap.SyncedAt.IsZero() {
+		t.Error("SyncedAt is not set")
+	}
+
+	ns.events <- node("node-3", "2")
+	want := []string{"node-1", "node-2", "node-3"}
+	for deadline := time.Now().Add(10 * time.Second); ; {
+		snap, err := c.Snapshot(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := names(snap)
+		if reflect.DeepEqual(got, want) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("nodes %q, want %q after the watch event", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ns.lists.Load(); got != 1 {
+		t.Errorf("nodes listed %d times, want once", got)
+	}
+}
@@ -0,0 +1,326 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/pager"
+	metricsv "k8s.io/metrics/pkg/client/clientset/versioned"
+)
+
+// lister returns the function that lists one page of a resource kind.
+type lister func(cs kubernetes.Interface, mc metricsv.Interface) pager.ListPageFunc
+
+var listers = map[Resource]lister{
+	Nodes: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().Nodes().List(ctx, opts)
+		}
+	},
+	NodeMetrics: func(_ kubernetes.Interface, mc metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return mc.MetricsV1beta1().NodeMetricses().List(ctx, opts)
+		}
+	},
+	Namespaces: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().Namespaces().List(ctx, opts)
+		}
+	},
+	Pods: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().Pods(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	Services: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().Services(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	Endpoints: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().Endpoints(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	ConfigMaps: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().ConfigMaps(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	Secrets: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().Secrets(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	ServiceAccounts: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().ServiceAccounts(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	PersistentVolumes: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().PersistentVolumes().List(ctx, opts)
+		}
+	},
+	PersistentVolumeClaims: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	ResourceQuotas: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().ResourceQuotas(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	LimitRanges: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CoreV1().LimitRanges(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	Deployments: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	ReplicaSets: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.AppsV1().ReplicaSets(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	StatefulSets: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	DaemonSets: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	Jobs: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.BatchV1().Jobs(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	CronJobs: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.BatchV1().CronJobs(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	HorizontalPodAutoscalers: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.AutoscalingV1().HorizontalPodAutoscalers(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	Ingresses: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.NetworkingV1().Ingresses(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	NetworkPolicies: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.NetworkingV1().NetworkPolicies(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	StorageClasses: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.StorageV1().StorageClasses().List(ctx, opts)
+		}
+	},
+	Roles: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.RbacV1().Roles(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	RoleBindings: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.RbacV1().RoleBindings(metav1.NamespaceAll).List(ctx, opts)
+		}
+	},
+	ClusterRoles: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.RbacV1().ClusterRoles().List(ctx, opts)
+		}
+	},
+	ClusterRoleBindings: func(cs kubernetes.Interface, _ metricsv.Interface) pager.ListPageFunc {
+		return func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.RbacV1().ClusterRoleBindings().List(ctx, opts)
+		}
+	},
+}
+
+// CollectOptions bounds how a snapshot is collected.
+type CollectOptions struct {
+	// Workers is the number of resources listed concurrently. Values below one mean one.
+	Workers int
+	// Timeout limits how long listing a single resource may take. Zero means no limit
+	// beyond the deadline of the context passed to Collect.
+	Timeout time.Duration
+	// FailFast makes the first failed resource abort the whole collection. Otherwise the failure
+	// is recorded with SetErr and the remaining resources are still collected.
+	FailFast bool
+	// PageSize is the number of objects requested per list call; the rest of a list is fetched
+	// with continue tokens. Zero uses DefaultPageSize.
+	PageSize int64
+	// Compact drops the object fields no built-in section reads as each page arrives, so that
+	// the snapshot of a very large cluster takes a fraction of the memory. The snapshot still
+	// holds every listed object until the reports are rendered, so its memory still grows with
+	// the number of objects. See Compact.
+	Compact bool
+}
+
+// DefaultPageSize is the number of objects requested per list call when CollectOptions.PageSize is zero.
+const DefaultPageSize = 500
+
+// Collect lists each of the requested resources exactly once, spread over a bounded pool of
+// workers, and returns an indexed snapshot. Resources that are not requested are left empty.
+// The metadata of a resource is not listed on its own when the resource itself is requested.
+// Transient API errors are retried with backoff before a resource is considered failed.
+func Collect(ctx context.Context, clientset kubernetes.Interface, metricsClient metricsv.Interface, metadataClient metadata.Interface, opts CollectOptions, resources ...Resource) (*ClusterSnapshot, error) {
+	s := &ClusterSnapshot{CollectedAt: time.Now()}
+	if err := collect(ctx, s, clientset, metricsClient, metadataClient, opts, resources); err != nil {
+		return nil, err
+	}
+	s.BuildIndexes()
+	return s, nil
+}
+
+// collect lists the given resources into s, as Collect does, without building its indexes.
+func collect(ctx context.Context, s *ClusterSnapshot, clientset kubernetes.Interface, metricsClient metricsv.Interface, metadataClient metadata.Interface, opts CollectOptions, resources []Resource) error {
+	requested := make(map[Resource]bool)
+	for _, resource := range resources {
+		requested[resource] = true
+	}
+
+	var pending []Resource
+	seen := make(map[Resource]bool)
+	for _, resource := range resources {
+		if seen[resource] {
+			continue
+		}
+		seen[resource] = true
+
+		if base, ok := MetadataBase(resource); ok {
+			if _, known := metadataGVRs[base]; !known && !ListedAsTable(base) {
+				return fmt.Errorf("unknown resource %q", resource)
+			}
+			if requested[base] {
+				continue
+			}
+		} else if _, ok := listers[resource]; !ok {
+			return fmt.Errorf("unknown resource %q", resource)
+		}
+		pending = append(pending, resource)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	jobs := make(chan Resource)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resource := range jobs {
+				// Each lister fills a different field of the snapshot, so no locking is needed
+				// beyond the one guarding the metadata lists.
+				if err := listResource(ctx, s, clientset, metricsClient, metadataClient, resource, opts); err != nil {
+					mu.Lock()
+					if !opts.FailFast {
+						s.SetErr(resource, err)
+					} else if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, resource := range pending {
+		select {
+		case jobs <- resource:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// listResource pages through a single resource under its own deadline, retrying transient errors.
+// A retry starts the list over, so the objects added by the failed attempt are discarded first.
+func listResource(ctx context.Context, s *ClusterSnapshot, cs kubernetes.Interface, mc metricsv.Interface, md metadata.Interface, resource Resource, opts CollectOptions) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	var list func() error
+	if base, ok := MetadataBase(resource); ok {
+		list = func() error {
+			items := []ObjectMetadata{}
+			err := listMetadata(ctx, cs, md, base, pageSize, func(item ObjectMetadata) {
+				compactMetadata(base, &item.ObjectMeta)
+				items = append(items, item)
+			})
+			if err != nil {
+				return err
+			}
+			s.setMetadata(base, items)
+			return nil
+		}
+	} else {
+		p := pager.New(listers[resource](cs, mc))
+		p.PageSize = pageSize
+		list = func() error {
+			s.clear(resource)
+			return p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+				if opts.Compact {
+					Compact(obj)
+				} else {
+					stripManagedFields(obj)
+				}
+				s.Add(obj)
+				return nil
+			})
+		}
+	}
+
+	if err := withRetry(ctx, list); err != nil {
+		s.clear(resource)
+		if opts.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("error fetching %s: timed out after %s", resource, opts.Timeout)
+		}
+		return fmt.Errorf("error fetching %s: %v", resource, err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package snapshot
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// clusterObjects returns a small cluster: two nodes, a namespace and three pods, two of them
+// on node-1 and one mounting a claim.
+func clusterObjects() []runtime.Object {
+	pod := func(namespace, name, node, claim string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+		if claim != "" {
+			p.Spec.Volumes = []corev1.Volume{{
+				Name:         "data",
+				VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+			}}
+		}
+		return p
+	}
+	return []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+		pod("web", "frontend", "node-1", ""),
+		pod("web", "db", "node-1", "db-data"),
+		pod("batch", "report", "node-2", ""),
+	}
+}
+
+// lists counts the list calls made through cs, by resource.
+func lists(cs *fake.Clientset) map[string]int {
+	counts := make(map[string]int)
+	for _, action := range cs.Actions() {
+		if action.GetVerb() == "list" {
+			counts[action.GetResource().Resource]++
+		}
+	}
+	return counts
+}
+
+func TestCollect(t *testing.T) {
+	tests := []struct {
+		name       string
+		resources  []Resource
+		wantLists  map[string]int
+		wantNodes  int
+		wantPods   int
+		wantSpaces int
+	}{
+		{
+			name:      "each resource listed once",
+			resources: []Resource{Pods, Nodes, Pods, Nodes},
+			wantLists: map[string]int{"pods": 1, "nodes": 1},
+			wantNodes: 2,
+			wantPods:  3,
+		},
+		{
+			name:       "resources not requested are left empty",
+			resources:  []Resource{Namespaces},
+			wantLists:  map[string]int{"namespaces": 1},
+			wantSpaces: 1,
+		},
+		{
+			name:      "nothing requested",
+			wantLists: map[string]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(clusterObjects()...)
+			snap, err := Collect(context.Background(), cs, metricsfake.NewSimpleClientset(), nil, CollectOptions{Workers: 4}, tt.resources...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := lists(cs); !reflect.DeepEqual(got, tt.wantLists) {
+				t.Errorf("list calls = %v, want %v", got, tt.wantLists)
+			}
+			if len(snap.Nodes) != tt.wantNodes || len(snap.Pods) != tt.wantPods || len(snap.Namespaces) != tt.wantSpaces {
+				t.Errorf("collected %d nodes, %d pods and %d namespaces, want %d, %d and %d",
+					len(snap.Nodes), len(snap.Pods), len(snap.Namespaces), tt.wantNodes, tt.wantPods, tt.wantSpaces)
+			}
+		})
+	}
+}
+
+func TestCollectUnknownResource(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	if _, err := Collect(context.Background(), cs, nil, nil, CollectOptions{}, Pods, Resource("widgets")); err == nil {
+		t.Fatal("Collect of an unknown resource succeeded")
+	}
+	if got := lists(cs); len(got) != 0 {
+		t.Errorf("list calls = %v, want none before the resources are checked", got)
+	}
+}
+
+func TestBuildIndexes(t *testing.T) {
+	cs := fake.NewSimpleClientset(clusterObjects()...)
+	snap, err := Collect(context.Background(), cs, nil, nil, CollectOptions{}, Nodes, Pods)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap.NodeMetrics = []metricsapi.NodeMetrics{{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}}}
+	snap.BuildIndexes()
+
+	names := func(pods []*corev1.Pod) []string {
+		var ns []string
+		for _, p := range pods {
+			ns = append(ns, p.Name)
+		}
+		return ns
+	}
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{name: "pods on node-1", got: names(snap.PodsOnNode("node-1")), want: []string{"db", "frontend"}},
+		{name: "pods on node-2", got: names(snap.PodsOnNode("node-2")), want: []string{"report"}},
+		{name: "pods on an unknown node", got: names(snap.PodsOnNode("node-3"))},
+		{name: "pods in web", got: names(snap.PodsInNamespace("web")), want: []string{"db", "frontend"}},
+		{name: "pods using db-data", got: names(snap.PodsUsingClaim("web", "db-data")), want: []string{"db"}},
+		{name: "claim in another namespace", got: names(snap.PodsUsingClaim("batch", "db-data"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+
+	if m := snap.MetricsForNode("node-2"); m == nil || m.Name != "node-2" {
+		t.Errorf("MetricsForNode(node-2) = %v", m)
+	}
+	if m := snap.MetricsForNode("node-1"); m != nil {
+		t.Errorf("MetricsForNode(node-1) = %v, want nil", m)
+	}
+	if got := snap.CountInNamespace(Pods, "web"); got != 2 {
+		t.Errorf("CountInNamespace(pods, web) = %d, want 2", got)
+	}
+}
+
+func TestCollectPartialFailure(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", nil)
+	tests := []struct {
+		name       string
+		failFast   bool
+		wantErr    bool
+		wantFailed []Resource
+	}{
+		{name: "failure recorded", wantFailed: []Resource{Secrets}},
+		{name: "fail fast", failFast: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(clusterObjects()...)
+			cs.PrependReactor("list", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, forbidden
+			})
+
+			snap, err := Collect(context.Background(), cs, nil, nil, CollectOptions{Workers: 1, FailFast: tt.failFast}, Secrets, Nodes)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Collect succeeded, want the error of the failed resource")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := snap.Failed(); !reflect.DeepEqual(got, tt.wantFailed) {
+				t.Errorf("Failed() = %v, want %v", got, tt.wantFailed)
+			}
+			if err := snap.Err(Secrets); err == nil || !strings.Contains(err.Error(), "forbidden") {
+				t.Errorf("Err(secrets) = %v, want the list error", err)
+			}
+			if len(snap.Secrets) != 0 {
+				t.Errorf("%d secrets kept from a failed list", len(snap.Secrets))
+			}
+			if snap.Err(Nodes) != nil || len(snap.Nodes) != 2 {
+				t.Errorf("nodes: %d collected, error %v; want 2 and no error", len(snap.Nodes), snap.Err(Nodes))
+			}
+		})
+	}
+}
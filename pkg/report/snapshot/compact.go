@@ -0,0 +1,67 @@
+package snapshot
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// lastAppliedAnnotation holds a full copy of the object as last applied by kubectl, often the
+// largest part of its metadata.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// stripManagedFields drops the server-side apply bookkeeping of an object, which no section
+// reads, and the copy of the data of a ConfigMap or Secret kept in its last-applied-configuration
+// annotation.
+func stripManagedFields(obj runtime.Object) {
+	if accessor, err := apimeta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+	}
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		delete(o.Annotations, lastAppliedAnnotation)
+	case *corev1.Secret:
+		delete(o.Annotations, lastAppliedAnnotation)
+	}
+}
+
+// Compact drops the fields of an object that no built-in section reads: managed fields and,
+// for pods, everything in the containers but their names and resources, volumes other than
+// PersistentVolumeClaims, and container statuses but their restart counts and readiness.
+// Annotations are kept, since the detailed report shows them. Sections registered by other
+// programs should not rely on the dropped fields when the snapshot was collected with
+// CollectOptions.Compact.
+func Compact(obj runtime.Object) {
+	stripManagedFields(obj)
+
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	containers := make([]corev1.Container, len(pod.Spec.Containers))
+	for i, c := range pod.Spec.Containers {
+		containers[i] = corev1.Container{Name: c.Name, Resources: c.Resources}
+	}
+	pod.Spec.Containers = containers
+	pod.Spec.InitContainers = nil
+	pod.Spec.EphemeralContainers = nil
+
+	var volumes []corev1.Volume
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil {
+			volumes = append(volumes, corev1.Volume{Name: v.Name, VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: v.PersistentVolumeClaim}})
+		}
+	}
+	pod.Spec.Volumes = volumes
+	pod.Spec.Affinity = nil
+	pod.Spec.Tolerations = nil
+
+	statuses := make([]corev1.ContainerStatus, len(pod.Status.ContainerStatuses))
+	for i, cs := range pod.Status.ContainerStatuses {
+		statuses[i] = corev1.ContainerStatus{Name: cs.Name, RestartCount: cs.RestartCount, Ready: cs.Ready}
+	}
+	pod.Status.ContainerStatuses = statuses
+	pod.Status.InitContainerStatuses = nil
+	pod.Status.EphemeralContainerStatuses = nil
+}
@@ -0,0 +1,405 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FileAPIVersion and FileKind identify a snapshot written by Save.
+	FileAPIVersion = "kubereport.kubesuite.org/v1"
+	FileKind       = "ClusterSnapshot"
+)
+
+var (
+	fileScheme  = runtime.NewScheme()
+	fileDecoder runtime.Decoder
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(fileScheme))
+	utilruntime.Must(metricsapi.AddToScheme(fileScheme))
+	fileDecoder = serializer.NewCodecFactory(fileScheme).UniversalDeserializer()
+}
+
+// snapshotFile is the on-disk envelope written by Save.
+type snapshotFile struct {
+	APIVersion  string            `json:"apiVersion"`
+	Kind        string            `json:"kind"`
+	ClusterName string            `json:"clusterName,omitempty"`
+	CollectedAt metav1.Time       `json:"collectedAt"`
+	SyncedAt    *metav1.Time      `json:"syncedAt,omitempty"`
+	Errors      map[string]string `json:"errors,omitempty"`
+	Items       []json.RawMessage `json:"items"`
+	// Metadata holds the resources only the metadata of which was collected.
+	Metadata map[string][]ObjectMetadata `json:"metadata,omitempty"`
+}
+
+// Save writes every object in the snapshot to path so it can be rendered later with Load.
+// The file is YAML when path ends in .yaml or .yml and JSON otherwise; a trailing .gz compresses it.
+// Resources that failed to collect are recorded too, so an offline render reports them the same way.
+// ConfigMaps and Secrets are written as the metadata the sections read, without their data.
+func Save(path string, s *ClusterSnapshot) error {
+	file := snapshotFile{
+		APIVersion:  FileAPIVersion,
+		Kind:        FileKind,
+		ClusterName: s.ClusterName,
+		CollectedAt: metav1.NewTime(s.CollectedAt),
+	}
+	if !s.SyncedAt.IsZero() {
+		syncedAt := metav1.NewTime(s.SyncedAt)
+		file.SyncedAt = &syncedAt
+	}
+	for _, resource := range s.Failed() {
+		if file.Errors == nil {
+			file.Errors = make(map[string]string)
+		}
+		file.Errors[string(resource)] = s.Err(resource).Error()
+	}
+
+	s.metadataMu.Lock()
+	resources := make([]Resource, 0, len(s.metadata)+2)
+	for resource := range s.metadata {
+		resources = append(resources, resource)
+	}
+	// Full ConfigMaps and Secrets, such as those of a kubectl dump, are saved as their metadata.
+	if _, ok := s.metadata[ConfigMaps]; !ok && len(s.ConfigMaps) > 0 {
+		resources = append(resources, ConfigMaps)
+	}
+	if _, ok := s.metadata[Secrets]; !ok && len(s.Secrets) > 0 {
+		resources = append(resources, Secrets)
+	}
+	s.metadataMu.Unlock()
+	for _, resource := range resources {
+		if file.Metadata == nil {
+			file.Metadata = make(map[string][]ObjectMetadata)
+		}
+		items := s.Metadata(resource)
+		if holdsDataCopy(resource) {
+			items = append([]ObjectMetadata(nil), items...)
+			for i := range items {
+				items[i].ObjectMeta = withoutDataCopy(resource, items[i].ObjectMeta)
+			}
+		}
+		file.Metadata[string(resource)] = items
+	}
+
+	for _, obj := range s.Objects() {
+		switch obj.(type) {
+		case *corev1.ConfigMap, *corev1.Secret:
+			continue
+		}
+		gvks, _, err := fileScheme.ObjectKinds(obj)
+		if err != nil {
+			return fmt.Errorf("failed to determine kind of %T: %v", obj, err)
+		}
+		obj = obj.DeepCopyObject()
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %v", gvks[0].Kind, err)
+		}
+		file.Items = append(file.Items, raw)
+	}
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %v", err)
+	}
+	if isYAML(path) {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return fmt.Errorf("failed to convert snapshot to YAML: %v", err)
+		}
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %v", err)
+	}
+	defer out.Close()
+
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(out)
+		if _, err := gz.Write(data); err != nil {
+			return fmt.Errorf("failed to write snapshot file: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to write snapshot file: %v", err)
+		}
+	} else if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %v", err)
+	}
+	return nil
+}
+
+// Load builds a snapshot from files on disk instead of a live cluster.
+// Each path may be a snapshot written by Save, a YAML or JSON manifest (including the List output
+// of "kubectl get -A -o yaml"), a tar or tar.gz archive such as a must-gather, or a directory that
+// is searched recursively for any of these. Objects of kinds a snapshot does not hold are skipped.
+// Objects are trimmed as Collect trims them: managed fields and the last-applied copy of the
+// data of ConfigMaps and Secrets are dropped as each object is decoded.
+func Load(paths ...string) (*ClusterSnapshot, error) {
+	return load(false, paths)
+}
+
+// LoadCompact is Load with every object compacted as it is decoded, as Collect does with
+// CollectOptions.Compact. See Compact.
+func LoadCompact(paths ...string) (*ClusterSnapshot, error) {
+	return load(true, paths)
+}
+
+// loader decodes files into a snapshot.
+type loader struct {
+	*ClusterSnapshot
+	// compact applies Compact to every object instead of only stripping its managed fields.
+	compact bool
+}
+
+func load(compact bool, paths []string) (*ClusterSnapshot, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshot files given")
+	}
+
+	snap := &ClusterSnapshot{}
+	s := &loader{ClusterSnapshot: snap, compact: compact}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		if !info.IsDir() {
+			if err := s.loadFile(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !(isManifest(p) || isArchive(p)) {
+				return nil
+			}
+			return s.loadFile(p)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// kubectl dumps carry neither a cluster name nor a collection time.
+	if snap.ClusterName == "" {
+		snap.ClusterName = trimExtensions(filepath.Base(filepath.Clean(paths[0])))
+	}
+	if snap.CollectedAt.IsZero() {
+		if info, err := os.Stat(paths[0]); err == nil {
+			snap.CollectedAt = info.ModTime()
+		}
+	}
+
+	snap.BuildIndexes()
+	return snap, nil
+}
+
+func (s *loader) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	if isArchive(path) {
+		return s.loadArchive(path, r)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	return s.decodeDocuments(path, data)
+}
+
+func (s *loader) loadArchive(path string, r io.Reader) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive %s: %v", path, err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isManifest(hdr.Name) {
+			continue
+		}
+
+		var entry io.Reader = tr
+		if strings.HasSuffix(hdr.Name, ".gz") {
+			gz, err := gzip.NewReader(tr)
+			if err != nil {
+				return fmt.Errorf("failed to decompress %s in %s: %v", hdr.Name, path, err)
+			}
+			entry = gz
+		}
+
+		data, err := io.ReadAll(entry)
+		if err != nil {
+			return fmt.Errorf("failed to read %s in %s: %v", hdr.Name, path, err)
+		}
+		if err := s.decodeDocuments(path+":"+hdr.Name, data); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeDocuments decodes every YAML or JSON document in data.
+func (s *loader) decodeDocuments(source string, data []byte) error {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", source, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if err := s.decode(doc); err != nil {
+			return fmt.Errorf("failed to decode %s: %v", source, err)
+		}
+	}
+}
+
+// decode adds the object in doc to the snapshot, unwrapping lists and snapshot envelopes.
+func (s *loader) decode(doc []byte) error {
+	var typeMeta metav1.TypeMeta
+	if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+		return err
+	}
+	if typeMeta.Kind == "" {
+		return nil
+	}
+
+	if typeMeta.APIVersion == FileAPIVersion && typeMeta.Kind == FileKind {
+		var file snapshotFile
+		if err := yaml.Unmarshal(doc, &file); err != nil {
+			return err
+		}
+		s.ClusterName = file.ClusterName
+		s.CollectedAt = file.CollectedAt.Time
+		if file.SyncedAt != nil {
+			s.SyncedAt = file.SyncedAt.Time
+		}
+		for resource, reason := range file.Errors {
+			s.SetErr(Resource(resource), errors.New(reason))
+		}
+		for resource, items := range file.Metadata {
+			s.setMetadata(Resource(resource), items)
+		}
+		for _, item := range file.Items {
+			if err := s.decode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	obj, _, err := fileDecoder.Decode(doc, nil, nil)
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !apimeta.IsListType(obj) {
+		s.add(obj)
+		return nil
+	}
+
+	items, err := apimeta.ExtractList(obj)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if unknown, ok := item.(*runtime.Unknown); ok {
+			if err := s.decode(unknown.Raw); err != nil {
+				return err
+			}
+			continue
+		}
+		s.add(item)
+	}
+	return nil
+}
+
+// add trims obj the way Collect does and adds it to the snapshot.
+func (s *loader) add(obj runtime.Object) {
+	if s.compact {
+		Compact(obj)
+	} else {
+		stripManagedFields(obj)
+	}
+	s.Add(obj)
+}
+
+func isYAML(path string) bool {
+	ext := filepath.Ext(strings.TrimSuffix(path, ".gz"))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+func isManifest(path string) bool {
+	ext := filepath.Ext(strings.TrimSuffix(path, ".gz"))
+	return ext == ".yaml" || ext == ".yml" || ext == ".json"
+}
+
+func isArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar") || strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// trimExtensions strips the manifest and archive extensions Load understands from name.
+func trimExtensions(name string) string {
+	for _, ext := range []string{".gz", ".tgz", ".tar", ".yaml", ".yml", ".json"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
@@ -0,0 +1,386 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const podList = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web-1
+    namespace: default
+  spec:
+    nodeName: node-a
+- apiVersion: example.com/v1
+  kind: Widget
+  metadata:
+    name: unknown
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web-2
+    namespace: default
+  spec:
+    nodeName: node-a
+`
+
+const node = `---
+apiVersion: v1
+kind: Node
+metadata:
+  name: node-a
+---
+`
+
+func testSnapshot() *ClusterSnapshot {
+	s := &ClusterSnapshot{
+		ClusterName: "prod",
+		CollectedAt: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		SyncedAt:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Nodes:       []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}},
+		Pods: []corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+			Spec:       corev1.PodSpec{NodeName: "node-a"},
+		}},
+		ConfigMaps: []corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "settings",
+				Namespace: "default",
+				Annotations: map[string]string{
+					lastAppliedAnnotation: `{"data":{"password":"SECRETPAYLOAD"}}`,
+					"team":                "web",
+				},
+			},
+			Data: map[string]string{"password": "SECRETPAYLOAD", "mode": "fast"},
+		}},
+	}
+	s.SetErr(Jobs, errors.New("jobs is forbidden"))
+	s.setMetadata(Secrets, []ObjectMetadata{{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"},
+		Type:       "Opaque",
+		DataItems:  2,
+	}})
+	return s
+}
+
+func TestSaveLoad(t *testing.T) {
+	for _, name := range []string{"snapshot.json", "snapshot.yaml", "snapshot.json.gz", "snapshot.yml.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			want := testSnapshot()
+			if err := Save(path, want); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasSuffix(name, ".gz") {
+				gz, err := gzip.NewReader(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("snapshot is not gzipped: %v", err)
+				}
+				var buf bytes.Buffer
+				if _, err := buf.ReadFrom(gz); err != nil {
+					t.Fatal(err)
+				}
+				data = buf.Bytes()
+			}
+			if bytes.Contains(data, []byte("SECRETPAYLOAD")) {
+				t.Errorf("saved snapshot holds ConfigMap data")
+			}
+
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got.ClusterName != want.ClusterName {
+				t.Errorf("ClusterName = %q, want %q", got.ClusterName, want.ClusterName)
+			}
+			if !got.CollectedAt.Equal(want.CollectedAt) {
+				t.Errorf("CollectedAt = %v, want %v", got.CollectedAt, want.CollectedAt)
+			}
+			if !got.SyncedAt.Equal(want.SyncedAt) {
+				t.Errorf("SyncedAt = %v, want %v", got.SyncedAt, want.SyncedAt)
+			}
+			if len(got.Nodes) != 1 || len(got.Pods) != 1 || got.Pods[0].Spec.NodeName != "node-a" {
+				t.Errorf("got %d nodes and pods %v, want node-a and web-1 on it", len(got.Nodes), got.Pods)
+			}
+			if pods := got.PodsOnNode("node-a"); len(pods) != 1 {
+				t.Errorf("PodsOnNode() = %d pods, want 1: indexes not built", len(pods))
+			}
+			if err := got.Err(Jobs); err == nil || err.Error() != "jobs is forbidden" {
+				t.Errorf("Err(Jobs) = %v, want the saved error", err)
+			}
+			if len(got.ConfigMaps) != 0 {
+				t.Errorf("got %d full ConfigMaps, want their metadata only", len(got.ConfigMaps))
+			}
+
+			configMaps := got.Metadata(ConfigMaps)
+			if len(configMaps) != 1 {
+				t.Fatalf("got %d ConfigMaps, want 1", len(configMaps))
+			}
+			cm := configMaps[0]
+			if cm.Name != "settings" || cm.DataItems != 2 {
+				t.Errorf("ConfigMap = %s with %d items, want settings with 2", cm.Name, cm.DataItems)
+			}
+			if _, ok := cm.Annotations[lastAppliedAnnotation]; ok || cm.Annotations["team"] != "web" {
+				t.Errorf("ConfigMap annotations = %v, want team only", cm.Annotations)
+			}
+			if _, ok := want.ConfigMaps[0].Annotations[lastAppliedAnnotation]; !ok {
+				t.Errorf("Save removed the annotation from the snapshot it was given")
+			}
+
+			secrets := got.Metadata(Secrets)
+			if len(secrets) != 1 || secrets[0].Type != "Opaque" || secrets[0].DataItems != 2 {
+				t.Errorf("Secrets = %+v, want token of type Opaque with 2 items", secrets)
+			}
+		})
+	}
+}
+
+func TestLoadManifests(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		pods  int
+		nodes int
+	}{
+		{
+			name:  "list",
+			files: map[string]string{"dump.yaml": podList},
+			pods:  2,
+		},
+		{
+			name:  "documents",
+			files: map[string]string{"dump.yaml": node + podList},
+			pods:  2,
+			nodes: 1,
+		},
+		{
+			name:  "json",
+			files: map[string]string{"node.json": `{"apiVersion":"v1","kind":"Node","metadata":{"name":"node-a"}}`},
+			nodes: 1,
+		},
+		{
+			name: "directory",
+			files: map[string]string{
+				"cluster/nodes.yaml":        node,
+				"cluster/default/pods.yaml": podList,
+				"cluster/README.md":         "not a manifest",
+			},
+			pods:  2,
+			nodes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var path string
+			for name, content := range tt.files {
+				p := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				path = p
+			}
+			if len(tt.files) > 1 {
+				path = filepath.Join(dir, "cluster")
+			}
+
+			s, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(s.Pods) != tt.pods || len(s.Nodes) != tt.nodes {
+				t.Errorf("got %d pods and %d nodes, want %d and %d", len(s.Pods), len(s.Nodes), tt.pods, tt.nodes)
+			}
+			if want := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".yaml"), ".json"); s.ClusterName != want {
+				t.Errorf("ClusterName = %q, want %q", s.ClusterName, want)
+			}
+			if s.CollectedAt.IsZero() {
+				t.Errorf("CollectedAt is zero, want the modification time")
+			}
+		})
+	}
+}
+
+func TestLoadArchive(t *testing.T) {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(node))
+	gz.Close()
+
+	entries := []struct {
+		name    string
+		content []byte
+	}{
+		{"must-gather/namespaces/default/core/pods.yaml", []byte(podList)},
+		{"must-gather/cluster-scoped-resources/core/nodes.yaml.gz", gzipped.Bytes()},
+		{"must-gather/namespaces/default/pods/web-1/logs/current.log", []byte("kind: Pod\n")},
+	}
+
+	for _, name := range []string{"must-gather.tar", "must-gather.tar.gz", "must-gather.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var gw *gzip.Writer
+			tw := tar.NewWriter(&buf)
+			if name != "must-gather.tar" {
+				gw = gzip.NewWriter(&buf)
+				tw = tar.NewWriter(gw)
+			}
+			tw.WriteHeader(&tar.Header{Name: "must-gather/", Typeflag: tar.TypeDir, Mode: 0o755})
+			for _, e := range entries {
+				hdr := &tar.Header{Name: e.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e.content))}
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write(e.content); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if gw != nil {
+				if err := gw.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(s.Pods) != 2 || len(s.Nodes) != 1 {
+				t.Errorf("got %d pods and %d nodes, want 2 and 1", len(s.Pods), len(s.Nodes))
+			}
+			if s.ClusterName != "must-gather" {
+				t.Errorf("ClusterName = %q, want must-gather", s.ClusterName)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(broken, []byte("apiVersion: v1\nkind: Pod\nspec: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{name: "no paths"},
+		{name: "missing file", paths: []string{filepath.Join(dir, "missing.yaml")}},
+		{name: "invalid YAML", paths: []string{broken}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.paths...); err == nil {
+				t.Errorf("Load(%v) succeeded, want an error", tt.paths)
+			}
+		})
+	}
+}
+
+const appliedDump = `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web-1
+    namespace: default
+    annotations:
+      kubectl.kubernetes.io/last-applied-configuration: '{"kind":"Pod"}'
+    managedFields:
+    - manager: kubectl
+      operation: Update
+  spec:
+    containers:
+    - name: web
+      image: nginx
+      resources:
+        requests:
+          cpu: 100m
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: settings
+    namespace: default
+    annotations:
+      kubectl.kubernetes.io/last-applied-configuration: '{"data":{"password":"SECRETPAYLOAD"}}'
+    managedFields:
+    - manager: kubectl
+      operation: Update
+  data:
+    password: SECRETPAYLOAD
+`
+
+func TestLoadTrimsObjects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.yaml")
+	if err := os.WriteFile(path, []byte(appliedDump), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		load      func(...string) (*ClusterSnapshot, error)
+		wantImage string
+	}{
+		{name: "Load", load: Load, wantImage: "nginx"},
+		{name: "LoadCompact", load: LoadCompact, wantImage: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.load(path)
+			if err != nil {
+				t.Fatalf("%s() error = %v", tt.name, err)
+			}
+			if len(s.Pods) != 1 || len(s.ConfigMaps) != 1 {
+				t.Fatalf("got %d pods and %d ConfigMaps, want 1 of each", len(s.Pods), len(s.ConfigMaps))
+			}
+			pod, cm := s.Pods[0], s.ConfigMaps[0]
+			if pod.ManagedFields != nil || cm.ManagedFields != nil {
+				t.Errorf("managed fields kept")
+			}
+			if _, ok := cm.Annotations[lastAppliedAnnotation]; ok {
+				t.Errorf("ConfigMap kept its last-applied copy")
+			}
+			if _, ok := pod.Annotations[lastAppliedAnnotation]; !ok {
+				t.Errorf("pod annotations dropped, want them kept as Collect does")
+			}
+			if got := pod.Spec.Containers[0].Image; got != tt.wantImage {
+				t.Errorf("container image = %q, want %q", got, tt.wantImage)
+			}
+			if got := pod.Spec.Containers[0].Resources.Requests.Cpu().MilliValue(); got != 100 {
+				t.Errorf("CPU request = %dm, want 100m", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/tools/pager"
+)
+
+// metadataSuffix marks a resource listed without the spec, status or data of its objects.
+const metadataSuffix = "/metadata"
+
+// MetadataOf returns the resource that lists only the metadata of the objects of another, for
+// sections that read nothing but names, namespaces, labels and counts. When the full objects
+// are collected or loaded as well, the metadata is taken from them instead.
+func MetadataOf(resource Resource) Resource {
+	return resource + metadataSuffix
+}
+
+// MetadataBase returns the resource a metadata-only resource lists the objects of, and whether
+// resource is one.
+func MetadataBase(resource Resource) (Resource, bool) {
+	base, ok := strings.CutSuffix(string(resource), metadataSuffix)
+	return Resource(base), ok
+}
+
+// ObjectMetadata is an object listed without its spec, status or data. For ConfigMaps and
+// Secrets it also holds the figures the reports show about their data.
+type ObjectMetadata struct {
+	metav1.ObjectMeta `json:"metadata"`
+	// Type is the type of a Secret.
+	Type string `json:"type,omitempty"`
+	// DataItems is the number of keys held by a Secret, or by a ConfigMap in data and binaryData.
+	DataItems int `json:"dataItems,omitempty"`
+}
+
+// metadataGVRs holds the API resource each snapshot resource is listed from by the metadata client.
+var metadataGVRs = map[Resource]schema.GroupVersionResource{
+	Nodes:                    {Version: "v1", Resource: "nodes"},
+	Namespaces:               {Version: "v1", Resource: "namespaces"},
+	Pods:                     {Version: "v1", Resource: "pods"},
+	Services:                 {Version: "v1", Resource: "services"},
+	Endpoints:                {Version: "v1", Resource: "endpoints"},
+	ServiceAccounts:          {Version: "v1", Resource: "serviceaccounts"},
+	PersistentVolumes:        {Version: "v1", Resource: "persistentvolumes"},
+	PersistentVolumeClaims:   {Version: "v1", Resource: "persistentvolumeclaims"},
+	ResourceQuotas:           {Version: "v1", Resource: "resourcequotas"},
+	LimitRanges:              {Version: "v1", Resource: "limitranges"},
+	Deployments:              {Group: "apps", Version: "v1", Resource: "deployments"},
+	ReplicaSets:              {Group: "apps", Version: "v1", Resource: "replicasets"},
+	StatefulSets:             {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	DaemonSets:               {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	Jobs:                     {Group: "batch", Version: "v1", Resource: "jobs"},
+	CronJobs:                 {Group: "batch", Version: "v1", Resource: "cronjobs"},
+	HorizontalPodAutoscalers: {Group: "autoscaling", Version: "v1", Resource: "horizontalpodautoscalers"},
+	Ingresses:                {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	NetworkPolicies:          {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+	StorageClasses:           {Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"},
+	Roles:                    {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+	RoleBindings:             {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+	ClusterRoles:             {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"},
+	ClusterRoleBindings:      {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+}
+
+// ListedAsTable reports whether the metadata of a resource is listed as a server-side table
+// through the core clientset rather than by the metadata client. ConfigMaps and Secrets are,
+// because the table counts their data items without sending the data itself.
+func ListedAsTable(resource Resource) bool {
+	return resource == ConfigMaps || resource == Secrets
+}
+
+// Metadata returns the metadata of every object of a resource: the metadata-only list collected
+// for it or, when the full objects were collected or loaded instead, their metadata.
+func (s *ClusterSnapshot) Metadata(resource Resource) []ObjectMetadata {
+	s.metadataMu.Lock()
+	items, ok := s.metadata[resource]
+	s.metadataMu.Unlock()
+	if ok {
+		return items
+	}
+
+	switch resource {
+	case Nodes:
+		return metadataOf(s.Nodes)
+	case Namespaces:
+		return metadataOf(s.Namespaces)
+	case Pods:
+		return metadataOf(s.Pods)
+	case Services:
+		return metadataOf(s.Services)
+	case Endpoints:
+		return metadataOf(s.Endpoints)
+	case ConfigMaps:
+		items := metadataOf(s.ConfigMaps)
+		for i, cm := range s.ConfigMaps {
+			items[i].DataItems = len(cm.Data) + len(cm.BinaryData)
+		}
+		return items
+	case Secrets:
+		items := metadataOf(s.Secrets)
+		for i, secret := range s.Secrets {
+			items[i].Type = string(secret.Type)
+			items[i].DataItems = len(secret.Data)
+		}
+		return items
+	case ServiceAccounts:
+		return metadataOf(s.ServiceAccounts)
+	case PersistentVolumes:
+		return metadataOf(s.PersistentVolumes)
+	case PersistentVolumeClaims:
+		return metadataOf(s.PersistentVolumeClaims)
+	case ResourceQuotas:
+		return metadataOf(s.ResourceQuotas)
+	case LimitRanges:
+		return metadataOf(s.LimitRanges)
+	case Deployments:
+		return metadataOf(s.Deployments)
+	case ReplicaSets:
+		return metadataOf(s.ReplicaSets)
+	case StatefulSets:
+		return metadataOf(s.StatefulSets)
+	case DaemonSets:
+		return metadataOf(s.DaemonSets)
+	case Jobs:
+		return metadataOf(s.Jobs)
+	case CronJobs:
+		return metadataOf(s.CronJobs)
+	case HorizontalPodAutoscalers:
+		return metadataOf(s.HorizontalPodAutoscalers)
+	case Ingresses:
+		return metadataOf(s.Ingresses)
+	case NetworkPolicies:
+		return metadataOf(s.NetworkPolicies)
+	case StorageClasses:
+		return metadataOf(s.StorageClasses)
+	case Roles:
+		return metadataOf(s.Roles)
+	case RoleBindings:
+		return metadataOf(s.RoleBindings)
+	case ClusterRoles:
+		return metadataOf(s.ClusterRoles)
+	case ClusterRoleBindings:
+		return metadataOf(s.ClusterRoleBindings)
+	}
+	return nil
+}
+
+// setMetadata stores the metadata-only list of a resource, or drops it if items is nil.
+// Lists of different resources may be set concurrently.
+func (s *ClusterSnapshot) setMetadata(resource Resource, items []ObjectMetadata) {
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+	if items == nil {
+		delete(s.metadata, resource)
+		return
+	}
+	if s.metadata == nil {
+		s.metadata = make(map[Resource][]ObjectMetadata)
+	}
+	s.metadata[resource] = items
+}
+
+func metadataOf[T any, P interface {
+	*T
+	GetObjectMeta() metav1.Object
+}](objs []T) []ObjectMetadata {
+	items := make([]ObjectMetadata, len(objs))
+	for i := range objs {
+		if meta, ok := P(&objs[i]).GetObjectMeta().(*metav1.ObjectMeta); ok {
+			items[i].ObjectMeta = *meta
+		}
+	}
+	return items
+}
+
+// compactMetadata drops the managed fields of the listed metadata of a resource, the way
+// stripManagedFields does for full objects, and the last-applied-configuration annotation of
+// ConfigMaps and Secrets; see holdsDataCopy.
+func compactMetadata(resource Resource, meta *metav1.ObjectMeta) {
+	meta.ManagedFields = nil
+	if holdsDataCopy(resource) {
+		delete(meta.Annotations, lastAppliedAnnotation)
+	}
+}
+
+// holdsDataCopy reports whether the last-applied-configuration annotation of the objects of a
+// resource holds a copy of their data: for a ConfigMap or Secret created with kubectl apply it
+// holds the whole object, so that Secret payloads would reach memory and saved snapshots
+// through it. It is never kept for them.
+func holdsDataCopy(resource Resource) bool {
+	return resource == ConfigMaps || resource == Secrets
+}
+
+// withoutDataCopy returns meta without the last-applied-configuration annotation if the objects
+// of resource keep a copy of their data in it, leaving the annotations of meta itself alone.
+func withoutDataCopy(resource Resource, meta metav1.ObjectMeta) metav1.ObjectMeta {
+	if _, ok := meta.Annotations[lastAppliedAnnotation]; !ok || !holdsDataCopy(resource) {
+		return meta
+	}
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != lastAppliedAnnotation {
+			annotations[k] = v
+		}
+	}
+	meta.Annotations = annotations
+	return meta
+}
+
+// listMetadata pages through the metadata of a resource, passing each object to add.
+func listMetadata(ctx context.Context, cs kubernetes.Interface, mc metadata.Interface, resource Resource, pageSize int64, add func(ObjectMetadata)) error {
+	if ListedAsTable(resource) {
+		return listTable(ctx, cs, resource, pageSize, add)
+	}
+
+	gvr, ok := metadataGVRs[resource]
+	if !ok {
+		return fmt.Errorf("metadata of %s cannot be listed", resource)
+	}
+	if mc == nil {
+		return fmt.Errorf("metadata client is not available")
+	}
+
+	p := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return mc.Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, opts)
+	})
+	p.PageSize = pageSize
+	return p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		if m, ok := obj.(*metav1.PartialObjectMetadata); ok {
+			add(ObjectMetadata{ObjectMeta: m.ObjectMeta})
+		}
+		return nil
+	})
+}
+
+// tableAccept asks the API server to print a list as a Table, the way kubectl get does.
+const tableAccept = "application/json;as=Table;v=v1;g=meta.k8s.io"
+
+// listTable pages through a core resource printed as a server-side Table whose rows carry the
+// metadata of each object, reading the Type and Data columns the metadata alone lacks.
+func listTable(ctx context.Context, cs kubernetes.Interface, resource Resource, pageSize int64, add func(ObjectMetadata)) error {
+	if cs == nil {
+		return fmt.Errorf("core client is not available")
+	}
+
+	opts := metav1.ListOptions{Limit: pageSize}
+	for {
+		raw, err := cs.CoreV1().RESTClient().Get().
+			Resource(string(resource)).
+			VersionedParams(&opts, scheme.ParameterCodec).
+			Param("includeObject", string(metav1.IncludeMetadata)).
+			SetHeader("Accept", tableAccept).
+			DoRaw(ctx)
+		if err != nil {
+			return err
+		}
+
+		var t metav1.Table
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return fmt.Errorf("failed to decode %s table: %v", resource, err)
+		}
+
+		typeColumn, dataColumn := -1, -1
+		for i, column := range t.ColumnDefinitions {
+			switch column.Name {
+			case "Type":
+				typeColumn = i
+			case "Data":
+				dataColumn = i
+			}
+		}
+
+		for _, row := range t.Rows {
+			var m metav1.PartialObjectMetadata
+			if err := json.Unmarshal(row.Object.Raw, &m); err != nil {
+				return fmt.Errorf("failed to decode %s metadata: %v", resource, err)
+			}
+			item := ObjectMetadata{ObjectMeta: m.ObjectMeta}
+			compactMetadata(resource, &item.ObjectMeta)
+			if typeColumn >= 0 && typeColumn < len(row.Cells) {
+				item.Type, _ = row.Cells[typeColumn].(string)
+			}
+			if dataColumn >= 0 && dataColumn < len(row.Cells) {
+				// Numbers in JSON cells decode as float64.
+				if n, ok := row.Cells[dataColumn].(float64); ok {
+					item.DataItems = int(n)
+				}
+			}
+			add(item)
+		}
+
+		if t.Continue == "" {
+			return nil
+		}
+		opts.Continue = t.Continue
+	}
+}
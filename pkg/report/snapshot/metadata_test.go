@@ -0,0 +1,152 @@
+package snapshot
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	metadatafake "k8s.io/client-go/metadata/fake"
+)
+
+func TestMetadataOf(t *testing.T) {
+	tests := []struct {
+		resource Resource
+		wantBase Resource
+		wantOK   bool
+	}{
+		{resource: MetadataOf(Deployments), wantBase: Deployments, wantOK: true},
+		{resource: MetadataOf(Secrets), wantBase: Secrets, wantOK: true},
+		{resource: Deployments, wantBase: Deployments},
+		{resource: "deployments/metadata/metadata", wantBase: "deployments/metadata", wantOK: true},
+	}
+	for _, tt := range tests {
+		base, ok := MetadataBase(tt.resource)
+		if base != tt.wantBase || ok != tt.wantOK {
+			t.Errorf("MetadataBase(%s) = %s, %v, want %s, %v", tt.resource, base, ok, tt.wantBase, tt.wantOK)
+		}
+	}
+}
+
+func TestMetadataFromObjects(t *testing.T) {
+	applied := map[string]string{lastAppliedAnnotation: `{"data":{"password":"c2VjcmV0"}}`, "team": "web"}
+	snap := &ClusterSnapshot{
+		ConfigMaps: []corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "settings"},
+			Data:       map[string]string{"a": "1", "b": "2"},
+			BinaryData: map[string][]byte{"c": nil},
+		}},
+		Secrets: []corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "db", Annotations: applied},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"password": []byte("secret")},
+		}},
+		Deployments: []appsv1.Deployment{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "frontend"}}},
+	}
+
+	tests := []struct {
+		resource Resource
+		want     []ObjectMetadata
+	}{
+		{
+			resource: ConfigMaps,
+			want:     []ObjectMetadata{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "settings"}, DataItems: 3}},
+		},
+		{
+			resource: Secrets,
+			want:     []ObjectMetadata{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "db", Annotations: applied}, Type: "Opaque", DataItems: 1}},
+		},
+		{
+			resource: Deployments,
+			want:     []ObjectMetadata{{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "frontend"}}},
+		},
+		{resource: Jobs, want: []ObjectMetadata{}},
+	}
+	for _, tt := range tests {
+		if got := snap.Metadata(tt.resource); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Metadata(%s) = %+v, want %+v", tt.resource, got, tt.want)
+		}
+	}
+}
+
+func TestWithoutDataCopy(t *testing.T) {
+	applied := map[string]string{lastAppliedAnnotation: "{}", "team": "web"}
+	tests := []struct {
+		resource Resource
+		want     map[string]string
+	}{
+		{resource: Secrets, want: map[string]string{"team": "web"}},
+		{resource: ConfigMaps, want: map[string]string{"team": "web"}},
+		{resource: Deployments, want: applied},
+	}
+	for _, tt := range tests {
+		meta := metav1.ObjectMeta{Annotations: applied}
+		if got := withoutDataCopy(tt.resource, meta).Annotations; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("withoutDataCopy(%s) annotations = %v, want %v", tt.resource, got, tt.want)
+		}
+		if len(meta.Annotations) != 2 {
+			t.Errorf("withoutDataCopy(%s) changed the annotations it was given", tt.resource)
+		}
+	}
+}
+
+func TestCollectMetadata(t *testing.T) {
+	partial := func(namespace, name string) *metav1.PartialObjectMetadata {
+		return &metav1.PartialObjectMetadata{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:     namespace,
+				Name:          name,
+				ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+			},
+		}
+	}
+	scheme := metadatafake.NewTestScheme()
+	if err := metav1.AddMetaToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		resources []Resource
+		wantCalls int
+	}{
+		{name: "metadata listed on its own", resources: []Resource{MetadataOf(Deployments)}, wantCalls: 1},
+		{name: "metadata taken from the full objects", resources: []Resource{MetadataOf(Deployments), Deployments}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadatafake.NewSimpleMetadataClient(scheme, partial("web", "frontend"), partial("batch", "worker"))
+			cs := fake.NewSimpleClientset(
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "frontend"}},
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: "worker"}},
+			)
+			snap, err := Collect(context.Background(), cs, nil, md, CollectOptions{}, tt.resources...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(md.Actions()); got != tt.wantCalls {
+				t.Errorf("%d metadata calls, want %d", got, tt.wantCalls)
+			}
+
+			var names []string
+			for _, item := range snap.Metadata(Deployments) {
+				names = append(names, item.Namespace+"/"+item.Name)
+				if item.ManagedFields != nil {
+					t.Errorf("%s/%s kept its managed fields", item.Namespace, item.Name)
+				}
+			}
+			sort.Strings(names)
+			if want := []string{"batch/worker", "web/frontend"}; !reflect.DeepEqual(names, want) {
+				t.Errorf("metadata of %q, want %q", names, want)
+			}
+			if got := snap.CountInNamespace(Deployments, "web"); got != 1 {
+				t.Errorf("CountInNamespace(deployments, web) = %d, want 1", got)
+			}
+		})
+	}
+}
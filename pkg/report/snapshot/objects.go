@@ -0,0 +1,229 @@
+package snapshot
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// Add appends a single decoded object to the matching list of the snapshot.
+// It reports false for object types a snapshot does not hold.
+func (s *ClusterSnapshot) Add(obj runtime.Object) bool {
+	switch o := obj.(type) {
+	case *corev1.Node:
+		s.Nodes = append(s.Nodes, *o)
+	case *metricsapi.NodeMetrics:
+		s.NodeMetrics = append(s.NodeMetrics, *o)
+	case *corev1.Namespace:
+		s.Namespaces = append(s.Namespaces, *o)
+	case *corev1.Pod:
+		s.Pods = append(s.Pods, *o)
+	case *corev1.Service:
+		s.Services = append(s.Services, *o)
+	case *corev1.Endpoints:
+		s.Endpoints = append(s.Endpoints, *o)
+	case *corev1.ConfigMap:
+		s.ConfigMaps = append(s.ConfigMaps, *o)
+	case *corev1.Secret:
+		s.Secrets = append(s.Secrets, *o)
+	case *corev1.ServiceAccount:
+		s.ServiceAccounts = append(s.ServiceAccounts, *o)
+	case *corev1.PersistentVolume:
+		s.PersistentVolumes = append(s.PersistentVolumes, *o)
+	case *corev1.PersistentVolumeClaim:
+		s.PersistentVolumeClaims = append(s.PersistentVolumeClaims, *o)
+	case *corev1.ResourceQuota:
+		s.ResourceQuotas = append(s.ResourceQuotas, *o)
+	case *corev1.LimitRange:
+		s.LimitRanges = append(s.LimitRanges, *o)
+	case *appsv1.Deployment:
+		s.Deployments = append(s.Deployments, *o)
+	case *appsv1.ReplicaSet:
+		s.ReplicaSets = append(s.ReplicaSets, *o)
+	case *appsv1.StatefulSet:
+		s.StatefulSets = append(s.StatefulSets, *o)
+	case *appsv1.DaemonSet:
+		s.DaemonSets = append(s.DaemonSets, *o)
+	case *batchv1.Job:
+		s.Jobs = append(s.Jobs, *o)
+	case *batchv1.CronJob:
+		s.CronJobs = append(s.CronJobs, *o)
+	case *autoscalingv1.HorizontalPodAutoscaler:
+		s.HorizontalPodAutoscalers = append(s.HorizontalPodAutoscalers, *o)
+	case *networkingv1.Ingress:
+		s.Ingresses = append(s.Ingresses, *o)
+	case *networkingv1.NetworkPolicy:
+		s.NetworkPolicies = append(s.NetworkPolicies, *o)
+	case *storagev1.StorageClass:
+		s.StorageClasses = append(s.StorageClasses, *o)
+	case *rbacv1.Role:
+		s.Roles = append(s.Roles, *o)
+	case *rbacv1.RoleBinding:
+		s.RoleBindings = append(s.RoleBindings, *o)
+	case *rbacv1.ClusterRole:
+		s.ClusterRoles = append(s.ClusterRoles, *o)
+	case *rbacv1.ClusterRoleBinding:
+		s.ClusterRoleBindings = append(s.ClusterRoleBindings, *o)
+	default:
+		return false
+	}
+	return true
+}
+
+// Objects returns every object held by the snapshot, in AllResources order.
+func (s *ClusterSnapshot) Objects() []runtime.Object {
+	var objs []runtime.Object
+	for i := range s.Nodes {
+		objs = append(objs, &s.Nodes[i])
+	}
+	for i := range s.NodeMetrics {
+		objs = append(objs, &s.NodeMetrics[i])
+	}
+	for i := range s.Namespaces {
+		objs = append(objs, &s.Namespaces[i])
+	}
+	for i := range s.Pods {
+		objs = append(objs, &s.Pods[i])
+	}
+	for i := range s.Services {
+		objs = append(objs, &s.Services[i])
+	}
+	for i := range s.Endpoints {
+		objs = append(objs, &s.Endpoints[i])
+	}
+	for i := range s.ConfigMaps {
+		objs = append(objs, &s.ConfigMaps[i])
+	}
+	for i := range s.Secrets {
+		objs = append(objs, &s.Secrets[i])
+	}
+	for i := range s.ServiceAccounts {
+		objs = append(objs, &s.ServiceAccounts[i])
+	}
+	for i := range s.PersistentVolumes {
+		objs = append(objs, &s.PersistentVolumes[i])
+	}
+	for i := range s.PersistentVolumeClaims {
+		objs = append(objs, &s.PersistentVolumeClaims[i])
+	}
+	for i := range s.ResourceQuotas {
+		objs = append(objs, &s.ResourceQuotas[i])
+	}
+	for i := range s.LimitRanges {
+		objs = append(objs, &s.LimitRanges[i])
+	}
+	for i := range s.Deployments {
+		objs = append(objs, &s.Deployments[i])
+	}
+	for i := range s.ReplicaSets {
+		objs = append(objs, &s.ReplicaSets[i])
+	}
+	for i := range s.StatefulSets {
+		objs = append(objs, &s.StatefulSets[i])
+	}
+	for i := range s.DaemonSets {
+		objs = append(objs, &s.DaemonSets[i])
+	}
+	for i := range s.Jobs {
+		objs = append(objs, &s.Jobs[i])
+	}
+	for i := range s.CronJobs {
+		objs = append(objs, &s.CronJobs[i])
+	}
+	for i := range s.HorizontalPodAutoscalers {
+		objs = append(objs, &s.HorizontalPodAutoscalers[i])
+	}
+	for i := range s.Ingresses {
+		objs = append(objs, &s.Ingresses[i])
+	}
+	for i := range s.NetworkPolicies {
+		objs = append(objs, &s.NetworkPolicies[i])
+	}
+	for i := range s.StorageClasses {
+		objs = append(objs, &s.StorageClasses[i])
+	}
+	for i := range s.Roles {
+		objs = append(objs, &s.Roles[i])
+	}
+	for i := range s.RoleBindings {
+		objs = append(objs, &s.RoleBindings[i])
+	}
+	for i := range s.ClusterRoles {
+		objs = append(objs, &s.ClusterRoles[i])
+	}
+	for i := range s.ClusterRoleBindings {
+		objs = append(objs, &s.ClusterRoleBindings[i])
+	}
+	return objs
+}
+
+// clear empties the list of a resource.
+func (s *ClusterSnapshot) clear(resource Resource) {
+	if base, ok := MetadataBase(resource); ok {
+		s.setMetadata(base, nil)
+		return
+	}
+
+	switch resource {
+	case Nodes:
+		s.Nodes = nil
+	case NodeMetrics:
+		s.NodeMetrics = nil
+	case Namespaces:
+		s.Namespaces = nil
+	case Pods:
+		s.Pods = nil
+	case Services:
+		s.Services = nil
+	case Endpoints:
+		s.Endpoints = nil
+	case ConfigMaps:
+		s.ConfigMaps = nil
+	case Secrets:
+		s.Secrets = nil
+	case ServiceAccounts:
+		s.ServiceAccounts = nil
+	case PersistentVolumes:
+		s.PersistentVolumes = nil
+	case PersistentVolumeClaims:
+		s.PersistentVolumeClaims = nil
+	case ResourceQuotas:
+		s.ResourceQuotas = nil
+	case LimitRanges:
+		s.LimitRanges = nil
+	case Deployments:
+		s.Deployments = nil
+	case ReplicaSets:
+		s.ReplicaSets = nil
+	case StatefulSets:
+		s.StatefulSets = nil
+	case DaemonSets:
+		s.DaemonSets = nil
+	case Jobs:
+		s.Jobs = nil
+	case CronJobs:
+		s.CronJobs = nil
+	case HorizontalPodAutoscalers:
+		s.HorizontalPodAutoscalers = nil
+	case Ingresses:
+		s.Ingresses = nil
+	case NetworkPolicies:
+		s.NetworkPolicies = nil
+	case StorageClasses:
+		s.StorageClasses = nil
+	case Roles:
+		s.Roles = nil
+	case RoleBindings:
+		s.RoleBindings = nil
+	case ClusterRoles:
+		s.ClusterRoles = nil
+	case ClusterRoleBindings:
+		s.ClusterRoleBindings = nil
+	}
+}
@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// retryBackoff spaces out attempts at a failing list: 0.5s, 1s, 2s and 4s before giving up.
+var retryBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    4,
+}
+
+// withRetry calls fn until it succeeds, fails with a permanent error, the backoff is exhausted
+// or ctx is done, and returns the last error seen. When the API server asks for a longer delay
+// with Retry-After, as API Priority and Fairness does when it rejects a request, that delay is
+// waited instead.
+func withRetry(ctx context.Context, fn func() error) error {
+	backoff := retryBackoff
+	for {
+		err := fn()
+		if err == nil || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+
+		if backoff.Steps < 1 {
+			return err
+		}
+
+		delay := backoff.Step()
+		if seconds, ok := apierrors.SuggestsClientDelay(err); ok && time.Duration(seconds)*time.Second > delay {
+			delay = time.Duration(seconds) * time.Second
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransient reports whether err is worth retrying: server-side timeouts, throttling,
+// temporary unavailability and dropped connections.
+func isTransient(err error) bool {
+	switch {
+	case apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsTooManyRequests(err),
+		apierrors.IsServiceUnavailable(err),
+		apierrors.IsInternalError(err),
+		apierrors.IsUnexpectedServerError(err):
+		return true
+	case utilnet.IsConnectionReset(err), utilnet.IsConnectionRefused(err), utilnet.IsProbableEOF(err):
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,87 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestWithRetry(t *testing.T) {
+	defer func(b wait.Backoff) { retryBackoff = b }(retryBackoff)
+	retryBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+
+	pods := schema.GroupResource{Resource: "pods"}
+	throttled := apierrors.NewTooManyRequests("too many requests", 1)
+	tests := []struct {
+		name     string
+		errs     []error
+		calls    int
+		wantErr  bool
+		minDelay time.Duration
+	}{
+		{name: "success", errs: []error{nil}, calls: 1},
+		{name: "permanent error", errs: []error{apierrors.NewNotFound(pods, "web")}, calls: 1, wantErr: true},
+		{name: "transient then success", errs: []error{apierrors.NewServiceUnavailable("down"), io.EOF, nil}, calls: 3},
+		{name: "backoff exhausted", errs: []error{apierrors.NewInternalError(errors.New("boom"))}, calls: 4, wantErr: true},
+		{name: "retry after", errs: []error{throttled, nil}, calls: 2, minDelay: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			start := time.Now()
+			err := withRetry(context.Background(), func() error {
+				err := tt.errs[min(calls, len(tt.errs)-1)]
+				calls++
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("withRetry() error = %v, want error %v", err, tt.wantErr)
+			}
+			if calls != tt.calls {
+				t.Errorf("fn called %d times, want %d", calls, tt.calls)
+			}
+			if elapsed := time.Since(start); elapsed < tt.minDelay {
+				t.Errorf("retried after %v, want at least %v", elapsed, tt.minDelay)
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := withRetry(ctx, func() error {
+		calls++
+		cancel()
+		return apierrors.NewServiceUnavailable("down")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("withRetry() = %v after %d calls, want the error after 1", err, calls)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{apierrors.NewTooManyRequests("slow down", 1), true},
+		{apierrors.NewServerTimeout(schema.GroupResource{Resource: "pods"}, "list", 1), true},
+		{apierrors.NewServiceUnavailable("down"), true},
+		{io.ErrUnexpectedEOF, true},
+		{apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("denied")), false},
+		{apierrors.NewBadRequest("bad"), false},
+		{errors.New("something else"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
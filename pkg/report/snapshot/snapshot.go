@@ -0,0 +1,251 @@
+package snapshot
+
+import (
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metricsapi "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+)
+
+// Resource identifies a kind of object held in a ClusterSnapshot.
+type Resource string
+
+const (
+	Nodes                    Resource = "nodes"
+	NodeMetrics              Resource = "nodes.metrics.k8s.io"
+	Namespaces               Resource = "namespaces"
+	Pods                     Resource = "pods"
+	Services                 Resource = "services"
+	Endpoints                Resource = "endpoints"
+	ConfigMaps               Resource = "configmaps"
+	Secrets                  Resource = "secrets"
+	ServiceAccounts          Resource = "serviceaccounts"
+	PersistentVolumes        Resource = "persistentvolumes"
+	PersistentVolumeClaims   Resource = "persistentvolumeclaims"
+	ResourceQuotas           Resource = "resourcequotas"
+	LimitRanges              Resource = "limitranges"
+	Deployments              Resource = "deployments"
+	ReplicaSets              Resource = "replicasets"
+	StatefulSets             Resource = "statefulsets"
+	DaemonSets               Resource = "daemonsets"
+	Jobs                     Resource = "jobs"
+	CronJobs                 Resource = "cronjobs"
+	HorizontalPodAutoscalers Resource = "horizontalpodautoscalers"
+	Ingresses                Resource = "ingresses"
+	NetworkPolicies          Resource = "networkpolicies"
+	StorageClasses           Resource = "storageclasses"
+	Roles                    Resource = "roles"
+	RoleBindings             Resource = "rolebindings"
+	ClusterRoles             Resource = "clusterroles"
+	ClusterRoleBindings      Resource = "clusterrolebindings"
+)
+
+// AllResources lists every resource a snapshot can hold, in collection order.
+var AllResources = []Resource{
+	Nodes,
+	NodeMetrics,
+	Namespaces,
+	Pods,
+	Services,
+	Endpoints,
+	ConfigMaps,
+	Secrets,
+	ServiceAccounts,
+	PersistentVolumes,
+	PersistentVolumeClaims,
+	ResourceQuotas,
+	LimitRanges,
+	Deployments,
+	ReplicaSets,
+	StatefulSets,
+	DaemonSets,
+	Jobs,
+	CronJobs,
+	HorizontalPodAutoscalers,
+	Ingresses,
+	NetworkPolicies,
+	StorageClasses,
+	Roles,
+	RoleBindings,
+	ClusterRoles,
+	ClusterRoleBindings,
+}
+
+// ClusterSnapshot holds every object a report is rendered from, read once per run
+// so that all sections describe the cluster at the same point in time.
+type ClusterSnapshot struct {
+	ClusterName string
+	CollectedAt time.Time
+	// SyncedAt is, for a snapshot taken from a Cache, the time its least recently listed resource
+	// was last listed in full; watches have kept the lists current since. It is zero otherwise.
+	SyncedAt time.Time
+
+	Nodes                    []corev1.Node
+	NodeMetrics              []metricsapi.NodeMetrics
+	Namespaces               []corev1.Namespace
+	Pods                     []corev1.Pod
+	Services                 []corev1.Service
+	Endpoints                []corev1.Endpoints
+	ConfigMaps               []corev1.ConfigMap
+	Secrets                  []corev1.Secret
+	ServiceAccounts          []corev1.ServiceAccount
+	PersistentVolumes        []corev1.PersistentVolume
+	PersistentVolumeClaims   []corev1.PersistentVolumeClaim
+	ResourceQuotas           []corev1.ResourceQuota
+	LimitRanges              []corev1.LimitRange
+	Deployments              []appsv1.Deployment
+	ReplicaSets              []appsv1.ReplicaSet
+	StatefulSets             []appsv1.StatefulSet
+	DaemonSets               []appsv1.DaemonSet
+	Jobs                     []batchv1.Job
+	CronJobs                 []batchv1.CronJob
+	HorizontalPodAutoscalers []autoscalingv1.HorizontalPodAutoscaler
+	Ingresses                []networkingv1.Ingress
+	NetworkPolicies          []networkingv1.NetworkPolicy
+	StorageClasses           []storagev1.StorageClass
+	Roles                    []rbacv1.Role
+	RoleBindings             []rbacv1.RoleBinding
+	ClusterRoles             []rbacv1.ClusterRole
+	ClusterRoleBindings      []rbacv1.ClusterRoleBinding
+
+	// metadata holds the metadata-only lists, keyed by the resource they list the objects of.
+	metadata   map[Resource][]ObjectMetadata
+	metadataMu sync.Mutex
+
+	podsByNode        map[string][]*corev1.Pod
+	podsByNamespace   map[string][]*corev1.Pod
+	podsByClaim       map[string][]*corev1.Pod
+	nodeMetricsByName map[string]*metricsapi.NodeMetrics
+	namespaceCounts   map[Resource]map[string]int
+
+	errs map[Resource]error
+}
+
+// BuildIndexes (re)builds the lookup tables used by the accessor methods.
+// It must be called after the object lists are populated or modified.
+func (s *ClusterSnapshot) BuildIndexes() {
+	s.podsByNode = make(map[string][]*corev1.Pod)
+	s.podsByNamespace = make(map[string][]*corev1.Pod)
+	s.podsByClaim = make(map[string][]*corev1.Pod)
+	for i := range s.Pods {
+		pod := &s.Pods[i]
+		s.podsByNode[pod.Spec.NodeName] = append(s.podsByNode[pod.Spec.NodeName], pod)
+		s.podsByNamespace[pod.Namespace] = append(s.podsByNamespace[pod.Namespace], pod)
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				key := claimKey(pod.Namespace, volume.PersistentVolumeClaim.ClaimName)
+				s.podsByClaim[key] = append(s.podsByClaim[key], pod)
+			}
+		}
+	}
+
+	s.nodeMetricsByName = make(map[string]*metricsapi.NodeMetrics)
+	for i := range s.NodeMetrics {
+		s.nodeMetricsByName[s.NodeMetrics[i].Name] = &s.NodeMetrics[i]
+	}
+
+	s.namespaceCounts = make(map[Resource]map[string]int)
+	for _, resource := range namespacedResources {
+		counts := make(map[string]int)
+		for _, item := range s.Metadata(resource) {
+			counts[item.Namespace]++
+		}
+		s.namespaceCounts[resource] = counts
+	}
+}
+
+// namespacedResources lists the resources CountInNamespace counts.
+var namespacedResources = []Resource{
+	Pods,
+	Services,
+	Endpoints,
+	ConfigMaps,
+	Secrets,
+	ServiceAccounts,
+	PersistentVolumeClaims,
+	ResourceQuotas,
+	LimitRanges,
+	Deployments,
+	ReplicaSets,
+	StatefulSets,
+	DaemonSets,
+	Jobs,
+	CronJobs,
+	HorizontalPodAutoscalers,
+	Ingresses,
+	NetworkPolicies,
+	Roles,
+	RoleBindings,
+}
+
+// PodsOnNode returns the pods scheduled to the named node.
+func (s *ClusterSnapshot) PodsOnNode(nodeName string) []*corev1.Pod {
+	return s.podsByNode[nodeName]
+}
+
+// PodsInNamespace returns the pods in the named namespace.
+func (s *ClusterSnapshot) PodsInNamespace(namespace string) []*corev1.Pod {
+	return s.podsByNamespace[namespace]
+}
+
+// PodsUsingClaim returns the pods mounting the given PersistentVolumeClaim.
+func (s *ClusterSnapshot) PodsUsingClaim(namespace, claimName string) []*corev1.Pod {
+	return s.podsByClaim[claimKey(namespace, claimName)]
+}
+
+// MetricsForNode returns the usage metrics of the named node, or nil if none were collected.
+func (s *ClusterSnapshot) MetricsForNode(nodeName string) *metricsapi.NodeMetrics {
+	return s.nodeMetricsByName[nodeName]
+}
+
+// CountInNamespace returns the number of namespaced objects of the given resource in a namespace,
+// counted from the full objects or from their metadata, whichever was collected.
+func (s *ClusterSnapshot) CountInNamespace(resource Resource, namespace string) int {
+	return s.namespaceCounts[resource][namespace]
+}
+
+// SetErr records that the given resource could not be collected. Its list is left empty.
+func (s *ClusterSnapshot) SetErr(resource Resource, err error) {
+	if s.errs == nil {
+		s.errs = make(map[Resource]error)
+	}
+	s.errs[resource] = err
+}
+
+// Err returns the reason the given resource could not be collected, or nil if it was. The
+// metadata of a resource is reported as failed when its full objects are.
+func (s *ClusterSnapshot) Err(resource Resource) error {
+	if err := s.errs[resource]; err != nil {
+		return err
+	}
+	if base, ok := MetadataBase(resource); ok {
+		return s.errs[base]
+	}
+	return nil
+}
+
+// Failed returns the resources that could not be collected, in AllResources order, each
+// followed by its metadata if that was listed on its own and failed.
+func (s *ClusterSnapshot) Failed() []Resource {
+	var failed []Resource
+	for _, resource := range AllResources {
+		if s.errs[resource] != nil {
+			failed = append(failed, resource)
+		}
+		if s.errs[MetadataOf(resource)] != nil {
+			failed = append(failed, MetadataOf(resource))
+		}
+	}
+	return failed
+}
+
+func claimKey(namespace, claimName string) string {
+	return namespace + "/" + claimName
+}
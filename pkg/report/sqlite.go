@@ -0,0 +1,405 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the tables describing the runs held in a database, if they do not exist.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	report TEXT NOT NULL,
+	cluster TEXT NOT NULL,
+	generated_at TEXT NOT NULL,
+	collected_at TEXT,
+	synced_at TEXT,
+	tool_version TEXT
+);
+CREATE TABLE IF NOT EXISTS report_tables (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	name TEXT NOT NULL,
+	section TEXT NOT NULL,
+	title TEXT,
+	row_count INTEGER NOT NULL,
+	notes TEXT,
+	PRIMARY KEY (run_id, name)
+);
+CREATE TABLE IF NOT EXISTS collection_errors (
+	run_id INTEGER NOT NULL REFERENCES runs (id),
+	source TEXT NOT NULL,
+	reason TEXT NOT NULL
+);
+`
+
+// GenerateSQLite writes every table of a report to a table of a SQLite database, with typed
+// columns and indexes on the namespace, name and node of the objects their rows describe. Each
+// row carries the id of its run, which the runs table describes. The database is a new file
+// named after the timestamp unless appendTo names one, created if missing, that the run is
+// added to so that runs accumulate. Collection stops when ctx is cancelled or its deadline
+// passes.
+func GenerateSQLite(ctx context.Context, opts Options, report section.Report, appendTo string) (string, string, error) {
+	if logger != nil {
+		logger.Println("Starting SQLite report generation...")
+	}
+
+	sections := renderable[section.TableSection](section.For(report))
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	outputPath := appendTo
+	if outputPath == "" {
+		formattedTime := currentTime.Format("02-01-2006-15-04")
+		outputPath = fmt.Sprintf("kubernetes_cluster_report_%s.db", formattedTime)
+		// A new database is written from scratch, even over one left by an earlier run.
+		if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+			return "", "", fmt.Errorf("failed to replace SQLite database: %v", err)
+		}
+	}
+
+	if err := writeSQLite(ctx, opts, report, outputPath, currentTime, snap, sections, results); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save SQLite database: %v\n", err)
+		}
+		if appendTo == "" {
+			os.Remove(outputPath)
+		}
+		return "", "", err
+	}
+
+	if logger != nil {
+		logger.Println("SQLite report generated successfully.")
+	}
+	return clusterName, outputPath, nil
+}
+
+// writeSQLite adds a run to the database at path in a single transaction, so that a run that
+// fails leaves the database as it was.
+func writeSQLite(ctx context.Context, opts Options, report section.Report, path string, generatedAt time.Time, snap *snapshot.ClusterSnapshot, sections []section.Section, results []section.Result) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite database: %v", err)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to write SQLite database: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create SQLite schema: %v", err)
+	}
+
+	var syncedAt any
+	if !snap.SyncedAt.IsZero() {
+		syncedAt = sqliteTime(snap.SyncedAt)
+	}
+	res, err := tx.Exec(`INSERT INTO runs (report, cluster, generated_at, collected_at, synced_at, tool_version) VALUES (?, ?, ?, ?, ?, ?)`,
+		string(report), snap.ClusterName, sqliteTime(generatedAt), sqliteTime(snap.CollectedAt), syncedAt, opts.ToolVersion)
+	if err != nil {
+		return fmt.Errorf("failed to record run in SQLite database: %v", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to record run in SQLite database: %v", err)
+	}
+
+	// The tables describing the runs are never used for the tables of a section.
+	used := map[string]bool{"runs": true, "report_tables": true, "collection_errors": true}
+	var tables []sqliteTable
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+		sw := &sqliteWriter{tx: tx, runID: runID, report: report, section: title, used: used}
+
+		// A section that fails part way through is rolled back to where it started.
+		if _, err := tx.Exec("SAVEPOINT section"); err != nil {
+			return fmt.Errorf("failed to write SQLite database: %v", err)
+		}
+		var failure error
+		if err := section.ResourceErr(s, snap); err != nil {
+			failure = err
+		} else if err := results[i].Err; err != nil {
+			failure = err
+		} else if err := renderSQLite(s, sw, snap, results[i].Data, opts); err != nil {
+			sw.close()
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			failure = err
+		}
+		if failure != nil {
+			if _, err := tx.Exec("ROLLBACK TO section"); err != nil {
+				return fmt.Errorf("failed to write SQLite database: %v", err)
+			}
+			errs = append(errs, collectionError{s.Name(), failure.Error()})
+		} else {
+			tables = append(tables, sw.tables...)
+		}
+		if _, err := tx.Exec("RELEASE section"); err != nil {
+			return fmt.Errorf("failed to write SQLite database: %v", err)
+		}
+	}
+
+	for _, t := range tables {
+		var notes any
+		if len(t.notes) > 0 {
+			notes = strings.Join(t.notes, "\n")
+		}
+		var title any
+		if t.title != "" {
+			title = t.title
+		}
+		if _, err := tx.Exec(`INSERT INTO report_tables (run_id, name, section, title, row_count, notes) VALUES (?, ?, ?, ?, ?, ?)`,
+			runID, t.name, t.section, title, t.rows, notes); err != nil {
+			return fmt.Errorf("failed to record %s in SQLite database: %v", t.name, err)
+		}
+	}
+	for _, e := range errs {
+		if _, err := tx.Exec(`INSERT INTO collection_errors (run_id, source, reason) VALUES (?, ?, ?)`, runID, e.Source, e.Reason); err != nil {
+			return fmt.Errorf("failed to record collection errors in SQLite database: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save SQLite database: %v", err)
+	}
+	return nil
+}
+
+// renderSQLite inserts the tables of a section, a row at a time when streaming.
+func renderSQLite(s section.Section, sw *sqliteWriter, snap *snapshot.ClusterSnapshot, data any, opts Options) error {
+	if ss, ok := s.(section.StreamSection); ok && opts.Stream {
+		return ss.Stream(snap, data, sw, opts.SortBuffer)
+	}
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := table.Write(sw, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqliteTable is a row of the report_tables table.
+type sqliteTable struct {
+	name    string
+	section string
+	title   string
+	rows    int
+	notes   []string
+}
+
+// sqliteWriter is a RowWriter that inserts each table of a section into its own database
+// table, named like the files of a CSV bundle after the report, such as detailed_pod_details.
+// Totals are left out, since they can be computed with SQL.
+type sqliteWriter struct {
+	tx      *sql.Tx
+	runID   int64
+	report  section.Report
+	section string
+	used    map[string]bool
+
+	columns []table.StructuredColumn
+	insert  *sql.Stmt
+	tables  []sqliteTable
+}
+
+func (sw *sqliteWriter) Begin(t *table.Table) error {
+	// The name starts with the report, since both have sections with the same title.
+	name := tableName(string(sw.report)+" "+sw.section, t, len(sw.tables) == 0, sw.used)
+	sw.columns = table.StructuredColumns(t)
+
+	columns := []string{"run_id INTEGER NOT NULL REFERENCES runs (id)"}
+	for i, c := range sw.columns {
+		columns = append(columns, sqliteIdent(c.Key)+" "+sqliteType(t.Columns[i].Kind))
+	}
+	if _, err := sw.tx.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", sqliteIdent(name), strings.Join(columns, ", "))); err != nil {
+		return fmt.Errorf("failed to create table %s: %v", name, err)
+	}
+	// A table written by an earlier version may lack some columns, which are added.
+	if err := sw.addColumns(name, t); err != nil {
+		return err
+	}
+	for _, index := range sqliteIndexes(sw.columns) {
+		keys := make([]string, len(index.columns))
+		for i, key := range index.columns {
+			keys[i] = sqliteIdent(key)
+		}
+		stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", sqliteIdent(name+"_"+index.suffix), sqliteIdent(name), strings.Join(keys, ", "))
+		if _, err := sw.tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to index table %s: %v", name, err)
+		}
+	}
+
+	keys := []string{"run_id"}
+	for _, c := range sw.columns {
+		keys = append(keys, sqliteIdent(c.Key))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+	insert, err := sw.tx.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", sqliteIdent(name), strings.Join(keys, ", "), placeholders))
+	if err != nil {
+		return fmt.Errorf("failed to write table %s: %v", name, err)
+	}
+	sw.insert = insert
+	sw.tables = append(sw.tables, sqliteTable{name: name, section: sw.section, title: t.Title})
+	return nil
+}
+
+func (sw *sqliteWriter) Row(row table.Row) error {
+	values := make([]any, 0, len(sw.columns)+1)
+	values = append(values, sw.runID)
+	for i := range sw.columns {
+		var v any
+		if i < len(row) {
+			v = table.StructuredValue(row[i])
+		}
+		if b, ok := v.(bool); ok {
+			// SQLite has no boolean type; booleans are stored as 0 and 1.
+			v = 0
+			if b {
+				v = 1
+			}
+		}
+		values = append(values, v)
+	}
+	if _, err := sw.insert.Exec(values...); err != nil {
+		return fmt.Errorf("failed to insert row into %s: %v", sw.tables[len(sw.tables)-1].name, err)
+	}
+	sw.tables[len(sw.tables)-1].rows++
+	return nil
+}
+
+func (sw *sqliteWriter) End(t *table.Table) error {
+	sw.tables[len(sw.tables)-1].notes = t.Notes
+	return sw.close()
+}
+
+// close releases the insert statement of the table being written, if any.
+func (sw *sqliteWriter) close() error {
+	if sw.insert == nil {
+		return nil
+	}
+	err := sw.insert.Close()
+	sw.insert = nil
+	return err
+}
+
+// addColumns adds the columns of t that the database table name does not have yet.
+func (sw *sqliteWriter) addColumns(name string, t *table.Table) error {
+	rows, err := sw.tx.Query(fmt.Sprintf("SELECT name FROM pragma_table_info(%s)", sqliteString(name)))
+	if err != nil {
+		return fmt.Errorf("failed to read columns of table %s: %v", name, err)
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read columns of table %s: %v", name, err)
+		}
+		existing[column] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read columns of table %s: %v", name, err)
+	}
+
+	for i, c := range sw.columns {
+		if existing[c.Key] {
+			continue
+		}
+		if _, err := sw.tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", sqliteIdent(name), sqliteIdent(c.Key), sqliteType(t.Columns[i].Kind))); err != nil {
+			return fmt.Errorf("failed to add column %s to table %s: %v", c.Key, name, err)
+		}
+	}
+	return nil
+}
+
+// sqliteIndex is an index of a section table, named after the table and its suffix.
+type sqliteIndex struct {
+	suffix  string
+	columns []string
+}
+
+// sqliteIndexes returns the indexes of a section table: on the run, on the run, namespace and
+// name of the objects described when the first column is a name, and on the node when the
+// table refers to nodes in a later column.
+func sqliteIndexes(columns []table.StructuredColumn) []sqliteIndex {
+	indexes := []sqliteIndex{{suffix: "run", columns: []string{"run_id"}}}
+	if len(columns) == 0 {
+		return indexes
+	}
+
+	name := columns[0].Key
+	if name == "name" || strings.HasSuffix(name, "_name") {
+		key := []string{"run_id"}
+		for _, c := range columns {
+			if c.Key == "namespace" {
+				key = append(key, c.Key)
+			}
+		}
+		indexes = append(indexes, sqliteIndex{suffix: "key", columns: append(key, name)})
+	}
+	for _, c := range columns[1:] {
+		if c.Key == "node_name" || c.Key == "node" {
+			indexes = append(indexes, sqliteIndex{suffix: "node", columns: []string{"run_id", c.Key}})
+			break
+		}
+	}
+	return indexes
+}
+
+// sqliteType returns the SQLite type a column of the given kind is declared with. Durations
+// are whole seconds and booleans 0 or 1.
+func sqliteType(kind table.Kind) string {
+	switch kind {
+	case table.Integer, table.Bool, table.Duration:
+		return "INTEGER"
+	case table.Decimal, table.Percent:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteIdent quotes a table, column or index name.
+func sqliteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// sqliteString quotes a string literal.
+func sqliteString(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
+
+// sqliteTime formats a time as SQLite's date and time functions read it.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
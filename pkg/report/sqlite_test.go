@@ -0,0 +1,95 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+func TestSQLiteIndexes(t *testing.T) {
+	columns := func(keys ...string) []table.StructuredColumn {
+		var cs []table.StructuredColumn
+		for _, key := range keys {
+			cs = append(cs, table.StructuredColumn{Key: key})
+		}
+		return cs
+	}
+	run := sqliteIndex{suffix: "run", columns: []string{"run_id"}}
+
+	tests := []struct {
+		name    string
+		columns []table.StructuredColumn
+		want    []sqliteIndex
+	}{
+		{name: "no columns", want: []sqliteIndex{run}},
+		{name: "no name", columns: columns("metric", "value"), want: []sqliteIndex{run}},
+		{
+			name:    "cluster-scoped name",
+			columns: columns("name", "status"),
+			want:    []sqliteIndex{run, {suffix: "key", columns: []string{"run_id", "name"}}},
+		},
+		{
+			name:    "namespaced name",
+			columns: columns("pod_name", "namespace", "node_name"),
+			want: []sqliteIndex{
+				run,
+				{suffix: "key", columns: []string{"run_id", "namespace", "pod_name"}},
+				{suffix: "node", columns: []string{"run_id", "node_name"}},
+			},
+		},
+		{
+			name:    "node column without a name",
+			columns: columns("namespace", "node"),
+			want:    []sqliteIndex{run, {suffix: "node", columns: []string{"run_id", "node"}}},
+		},
+		{
+			name:    "node as the first column is the key only",
+			columns: columns("node_name", "cpu"),
+			want:    []sqliteIndex{run, {suffix: "key", columns: []string{"run_id", "node_name"}}},
+		},
+		{
+			name:    "name not first",
+			columns: columns("namespace", "name"),
+			want:    []sqliteIndex{run},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqliteIndexes(tt.columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sqliteIndexes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSQLiteType(t *testing.T) {
+	tests := []struct {
+		kind table.Kind
+		want string
+	}{
+		{table.Text, "TEXT"},
+		{table.Integer, "INTEGER"},
+		{table.Bool, "INTEGER"},
+		{table.Duration, "INTEGER"},
+		{table.Decimal, "REAL"},
+		{table.Percent, "REAL"},
+	}
+	for _, tt := range tests {
+		if got := sqliteType(tt.kind); got != tt.want {
+			t.Errorf("sqliteType(%v) = %s, want %s", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteIdent(t *testing.T) {
+	for name, want := range map[string]string{
+		"pod_details":  `"pod_details"`,
+		`say "hello"`:  `"say ""hello"""`,
+		"idx_pods_run": `"idx_pods_run"`,
+	} {
+		if got := sqliteIdent(name); got != want {
+			t.Errorf("sqliteIdent(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package table
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formulaPrefixes are the characters that make a spreadsheet read a cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// EscapeCSV prefixes a cell that a spreadsheet would run as a formula, one beginning with =, +,
+// -, @, a tab or a carriage return, with a single quote so that it is shown as text. Plain
+// numbers such as -5 are left alone.
+func EscapeCSV(s string) string {
+	if s == "" || !strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return s
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return "'" + s
+}
+
+// WriteCSV writes the tables one after another, separated by an empty row. Each is written as
+// its title, a header row, its rows and totals, and a row per note. Text that could run as a
+// formula is escaped with EscapeCSV.
+func WriteCSV(writer *csv.Writer, tables ...*Table) error {
+	w := NewCSVWriter(writer)
+	for _, t := range tables {
+		if err := Write(w, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CSVWriter is a RowWriter that writes tables the way WriteCSV does, a row at a time.
+type CSVWriter struct {
+	writer *csv.Writer
+	table  *Table
+	count  int
+
+	// Escape passes titles, headers, notes and text cells through EscapeCSV. It is set by
+	// NewCSVWriter.
+	Escape bool
+}
+
+// NewCSVWriter returns a CSVWriter writing to writer, escaping text that could run as a formula.
+func NewCSVWriter(writer *csv.Writer) *CSVWriter {
+	return &CSVWriter{writer: writer, Escape: true}
+}
+
+// Record writes a row of free text outside of any table, such as a report heading.
+func (w *CSVWriter) Record(fields ...string) error {
+	return w.writer.Write(w.escape(fields))
+}
+
+func (w *CSVWriter) Begin(t *Table) error {
+	if w.count > 0 {
+		if err := w.writer.Write([]string{}); err != nil {
+			return fmt.Errorf("error writing empty row to CSV: %v", err)
+		}
+	}
+	w.count++
+	w.table = t
+
+	if t.Title != "" {
+		if err := w.Record(t.Title); err != nil {
+			return fmt.Errorf("error writing %s title to CSV: %v", t.Title, err)
+		}
+	}
+
+	if err := w.Record(t.Headers()...); err != nil {
+		return fmt.Errorf("error writing headers to CSV: %v", err)
+	}
+	return nil
+}
+
+func (w *CSVWriter) Row(row Row) error {
+	if err := w.writer.Write(w.strings(w.table, row)); err != nil {
+		return fmt.Errorf("error writing record to CSV: %v", err)
+	}
+	return nil
+}
+
+func (w *CSVWriter) End(t *Table) error {
+	if t.Totals != nil {
+		if err := w.writer.Write(w.strings(t, t.Totals)); err != nil {
+			return fmt.Errorf("error writing totals to CSV: %v", err)
+		}
+	}
+
+	for _, note := range t.Notes {
+		if err := w.Record(note); err != nil {
+			return fmt.Errorf("error writing note to CSV: %v", err)
+		}
+	}
+
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %v", err)
+	}
+	return nil
+}
+
+// strings returns the text of every cell of a row, escaping the text values. Numbers are
+// formatted by the table and cannot run as formulas.
+func (w *CSVWriter) strings(t *Table, row Row) []string {
+	cells := t.Strings(row)
+	if w.Escape {
+		for i := range cells {
+			if i < len(row) {
+				if _, ok := row[i].(string); ok {
+					cells[i] = EscapeCSV(cells[i])
+				}
+			}
+		}
+	}
+	return cells
+}
+
+func (w *CSVWriter) escape(fields []string) []string {
+	if !w.Escape {
+		return fields
+	}
+	escaped := make([]string, len(fields))
+	for i, f := range fields {
+		escaped[i] = EscapeCSV(f)
+	}
+	return escaped
+}
+
+// DataCSVWriter is a RowWriter that writes a single table as plain data for loaders such as
+// pandas or DuckDB: one header row of column keys, then one row per row with the structured
+// value of every cell, so numbers carry no units and durations are whole seconds. Titles,
+// totals and notes are left out; Columns and Notes describe the table once it is written.
+type DataCSVWriter struct {
+	writer *csv.Writer
+
+	// Escape passes text cells through EscapeCSV. It is set by NewDataCSVWriter.
+	Escape bool
+
+	Columns []StructuredColumn
+	Rows    int
+	Notes   []string
+}
+
+// NewDataCSVWriter returns a DataCSVWriter writing to writer.
+func NewDataCSVWriter(writer *csv.Writer) *DataCSVWriter {
+	return &DataCSVWriter{writer: writer, Escape: true}
+}
+
+func (w *DataCSVWriter) Begin(t *Table) error {
+	w.Columns = StructuredColumns(t)
+	keys := make([]string, len(w.Columns))
+	for i, c := range w.Columns {
+		keys[i] = c.Key
+	}
+	if err := w.writer.Write(keys); err != nil {
+		return fmt.Errorf("error writing headers to CSV: %v", err)
+	}
+	return nil
+}
+
+func (w *DataCSVWriter) Row(row Row) error {
+	record := make([]string, len(w.Columns))
+	for i := range record {
+		if i < len(row) {
+			record[i] = dataText(StructuredValue(row[i]))
+			if _, ok := row[i].(string); ok && w.Escape {
+				record[i] = EscapeCSV(record[i])
+			}
+		}
+	}
+	if err := w.writer.Write(record); err != nil {
+		return fmt.Errorf("error writing record to CSV: %v", err)
+	}
+	w.Rows++
+	return nil
+}
+
+func (w *DataCSVWriter) End(t *Table) error {
+	w.Notes = t.Notes
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return fmt.Errorf("error flushing CSV writer: %v", err)
+	}
+	return nil
+}
+
+// dataText renders a structured value as CSV text.
+func dataText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
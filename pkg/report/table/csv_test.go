@@ -0,0 +1,98 @@
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+func TestEscapeCSV(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"nginx", "nginx"},
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+1-555", "'+1-555"},
+		{"-cmd", "'-cmd"},
+		{"@import", "'@import"},
+		{"\tindented", "'\tindented"},
+		{"\rreturn", "'\rreturn"},
+		{"-5", "-5"},
+		{"+3.25", "+3.25"},
+		{"-1e3", "-1e3"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+	}
+	for _, tt := range tests {
+		if got := EscapeCSV(tt.in); got != tt.want {
+			t.Errorf("EscapeCSV(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	tbl := New("=Title",
+		Column{Header: "NAME"},
+		Column{Header: "COUNT", Kind: Integer},
+	)
+	tbl.AddRow("=cmd|' /C calc'!A0", -3)
+	tbl.AddRow("-5", 7)
+	tbl.SetTotals("TOTAL", 4)
+	tbl.AddNote("@note")
+
+	tests := []struct {
+		name   string
+		escape bool
+		want   string
+	}{
+		{
+			name:   "escaped",
+			escape: true,
+			want: "'=Title\n" +
+				"NAME,COUNT\n" +
+				"'=cmd|' /C calc'!A0,-3\n" +
+				"-5,7\n" +
+				"TOTAL,4\n" +
+				"'@note\n",
+		},
+		{
+			name: "not escaped",
+			want: "=Title\n" +
+				"NAME,COUNT\n" +
+				"=cmd|' /C calc'!A0,-3\n" +
+				"-5,7\n" +
+				"TOTAL,4\n" +
+				"@note\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewCSVWriter(csv.NewWriter(&buf))
+			w.Escape = tt.escape
+			if err := Write(w, tbl); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCSVSeparatesTables(t *testing.T) {
+	first := New("", Column{Header: "A"})
+	first.AddRow("1")
+	second := New("Second", Column{Header: "B"})
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := WriteCSV(writer, first, second); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	want := "A\n1\n\nSecond\nB\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+}
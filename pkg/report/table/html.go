@@ -0,0 +1,106 @@
+package table
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlLongCell is the length above which a cell is collapsed to its first characters.
+const htmlLongCell = 80
+
+var htmlTemplate = template.Must(template.New("table").Parse(`{{range .}}
+{{- if .Title}}<h3>{{.Title}}</h3>
+{{end -}}
+<div class="table">
+<input type="search" class="filter" placeholder="Filter rows">
+<table>
+<thead><tr>{{range .Columns}}<th{{if .Numeric}} class="num"{{end}}>{{.Header}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{template "cells" .}}</tr>
+{{- end}}
+</tbody>
+{{- if .Totals}}
+<tfoot><tr>{{template "cells" .Totals}}</tr></tfoot>
+{{- end}}
+</table>
+{{- range .Notes}}
+<p class="note">{{.}}</p>
+{{- end}}
+</div>
+{{end}}
+{{- define "cells"}}{{range .}}<td{{if .Numeric}} class="num" data-sort="{{.Sort}}"{{end}}>
+{{- if .Long}}<details><summary>{{.Short}}…</summary>{{.Text}}</details>{{else}}{{.Text}}{{end}}</td>{{end}}{{end}}`))
+
+type htmlTable struct {
+	Title   string
+	Columns []htmlColumn
+	Rows    [][]htmlCell
+	Totals  []htmlCell
+	Notes   []string
+}
+
+type htmlColumn struct {
+	Header  string
+	Numeric bool
+}
+
+type htmlCell struct {
+	Text    string
+	Short   string
+	Long    bool
+	Numeric bool
+	// Sort is the value numeric cells are sorted by.
+	Sort string
+}
+
+// WriteHTML writes the tables as HTML table elements, each preceded by a filter box. Numeric
+// cells carry their raw value in a data-sort attribute, durations in seconds, and cells longer
+// than a line are collapsed into a details element. The markup expects the page to provide
+// the styles and the script that sorts and filters the tables.
+func WriteHTML(w io.Writer, tables ...*Table) error {
+	var data []htmlTable
+	for _, t := range tables {
+		ht := htmlTable{Title: t.Title, Notes: t.Notes}
+		for _, c := range t.Columns {
+			ht.Columns = append(ht.Columns, htmlColumn{Header: c.Header, Numeric: c.Numeric() || c.Kind == Duration})
+		}
+		for _, row := range t.Rows {
+			ht.Rows = append(ht.Rows, htmlCells(t, row))
+		}
+		if t.Totals != nil {
+			ht.Totals = htmlCells(t, t.Totals)
+		}
+		data = append(data, ht)
+	}
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("error writing HTML table: %v", err)
+	}
+	return nil
+}
+
+func htmlCells(t *Table, row Row) []htmlCell {
+	cells := make([]htmlCell, len(t.Columns))
+	for i, text := range t.Strings(row) {
+		c := t.Columns[i]
+		cell := htmlCell{Text: text, Numeric: c.Numeric() || c.Kind == Duration}
+		if i < len(row) && cell.Numeric {
+			switch v := row[i].(type) {
+			case time.Duration:
+				cell.Sort = fmt.Sprint(int64(v / time.Second))
+			case nil:
+			default:
+				cell.Sort = fmt.Sprint(v)
+			}
+		}
+		if runes := []rune(text); len(runes) > htmlLongCell {
+			cell.Long = true
+			cell.Short = string(runes[:htmlLongCell])
+		}
+		cells[i] = cell
+	}
+	return cells
+}
@@ -0,0 +1,79 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTMLCells(t *testing.T) {
+	long := strings.Repeat("é", htmlLongCell+5)
+	tbl := New("",
+		Column{Header: "NAME"},
+		Column{Header: "CPU", Kind: Integer},
+		Column{Header: "USED", Kind: Percent},
+		Column{Header: "AGE", Kind: Duration},
+	)
+	tests := []struct {
+		name string
+		row  Row
+		want []htmlCell
+	}{
+		{
+			name: "numeric cells sort by their value",
+			row:  Row{"web", int64(250), 12.5, 3*time.Hour + 10*time.Minute},
+			want: []htmlCell{
+				{Text: "web"},
+				{Text: "250", Numeric: true, Sort: "250"},
+				{Text: "12.50%", Numeric: true, Sort: "12.5"},
+				{Text: "3h0m0s", Numeric: true, Sort: "11400"},
+			},
+		},
+		{
+			name: "empty cells have no sort value",
+			row:  Row{"db", nil, nil, nil},
+			want: []htmlCell{{Text: "db"}, {Numeric: true}, {Numeric: true}, {Numeric: true}},
+		},
+		{
+			name: "long text collapsed",
+			row:  Row{long, nil, nil, nil},
+			want: []htmlCell{
+				{Text: long, Short: long[:2*htmlLongCell], Long: true},
+				{Numeric: true}, {Numeric: true}, {Numeric: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := htmlCells(tbl, tt.row)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("cell %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	tbl := New("<b>Pods</b>", Column{Header: "NAME & NS"})
+	tbl.AddRow(`<script>alert("x")</script>`)
+	tbl.AddNote("<i>note</i>")
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, tbl); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, raw := range []string{"<b>", "<script>", "<i>"} {
+		if strings.Contains(out, raw) {
+			t.Errorf("output holds %s unescaped:\n%s", raw, out)
+		}
+	}
+	for _, escaped := range []string{"&lt;b&gt;Pods&lt;/b&gt;", "NAME &amp; NS", "&lt;script&gt;", "&lt;i&gt;note&lt;/i&gt;"} {
+		if !strings.Contains(out, escaped) {
+			t.Errorf("output lacks %s:\n%s", escaped, out)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// markdownLongCell is the length above which a Markdown cell is truncated, with the full text
+// moved to a footnote.
+const markdownLongCell = 60
+
+// markdownEscaper escapes the characters that would end a cell or break a table row, start
+// emphasis, code, links or strikethrough, or open an HTML tag.
+var markdownEscaper = strings.NewReplacer(
+	"|", `\|`, "\r\n", " ", "\n", " ", "\r", " ",
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "~", `\~`,
+	"&", "&amp;", "<", "&lt;", ">", "&gt;",
+)
+
+// EscapeMarkdown returns text as it must be written in Markdown to be shown as it is, on a
+// single line, whatever it holds: labels and annotations cannot add formatting, links or HTML
+// to a rendered report.
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// MarkdownWriter is a RowWriter that writes tables as GitHub-flavoured Markdown. Cells longer
+// than a line are truncated and refer to a numbered footnote holding their full text; the
+// footnotes are written by Footnotes, usually at the end of the document.
+type MarkdownWriter struct {
+	w         io.Writer
+	table     *Table
+	footnotes []string
+}
+
+// NewMarkdownWriter returns a MarkdownWriter writing to w.
+func NewMarkdownWriter(w io.Writer) *MarkdownWriter {
+	return &MarkdownWriter{w: w}
+}
+
+// WriteMarkdown writes the tables one after another, each as its title, the table itself with
+// its totals in bold, and its notes in italics.
+func (w *MarkdownWriter) WriteMarkdown(tables ...*Table) error {
+	for _, t := range tables {
+		if err := Write(w, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *MarkdownWriter) Begin(t *Table) error {
+	w.table = t
+
+	var b strings.Builder
+	if t.Title != "" {
+		fmt.Fprintf(&b, "### %s\n\n", markdownEscaper.Replace(t.Title))
+	}
+
+	b.WriteString("|")
+	for _, c := range t.Columns {
+		fmt.Fprintf(&b, " %s |", markdownEscaper.Replace(c.Header))
+	}
+	b.WriteString("\n|")
+	for _, c := range t.Columns {
+		if c.Numeric() {
+			b.WriteString(" ---: |")
+		} else {
+			b.WriteString(" --- |")
+		}
+	}
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(w.w, b.String()); err != nil {
+		return fmt.Errorf("error writing Markdown table header: %v", err)
+	}
+	return nil
+}
+
+func (w *MarkdownWriter) Row(row Row) error {
+	if _, err := io.WriteString(w.w, w.line(w.table.Strings(row), false)); err != nil {
+		return fmt.Errorf("error writing Markdown table row: %v", err)
+	}
+	return nil
+}
+
+func (w *MarkdownWriter) End(t *Table) error {
+	var b strings.Builder
+	if t.Totals != nil {
+		b.WriteString(w.line(t.Strings(t.Totals), true))
+	}
+	b.WriteString("\n")
+	for _, note := range t.Notes {
+		fmt.Fprintf(&b, "_%s_\n\n", markdownEscaper.Replace(note))
+	}
+
+	if _, err := io.WriteString(w.w, b.String()); err != nil {
+		return fmt.Errorf("error writing Markdown table end: %v", err)
+	}
+	return nil
+}
+
+// Footnotes writes the full text of every truncated cell written so far.
+func (w *MarkdownWriter) Footnotes() error {
+	var b strings.Builder
+	for i, text := range w.footnotes {
+		fmt.Fprintf(&b, "[^%d]: %s\n", i+1, text)
+	}
+	if _, err := io.WriteString(w.w, b.String()); err != nil {
+		return fmt.Errorf("error writing Markdown footnotes: %v", err)
+	}
+	return nil
+}
+
+// line renders a table row, truncating long cells.
+func (w *MarkdownWriter) line(cells []string, bold bool) string {
+	var b strings.Builder
+	b.WriteString("|")
+	for _, text := range cells {
+		text = markdownEscaper.Replace(text)
+		if runes := []rune(text); len(runes) > markdownLongCell {
+			w.footnotes = append(w.footnotes, text)
+			// Trimming also drops the backslash or the start of an entity the cut falls inside.
+			cut := strings.TrimRight(string(runes[:markdownLongCell]), `\ `)
+			if amp := strings.LastIndex(cut, "&"); amp >= 0 && !strings.Contains(cut[amp:], ";") {
+				cut = cut[:amp]
+			}
+			text = fmt.Sprintf("%s…[^%d]", cut, len(w.footnotes))
+		}
+		if bold && text != "" {
+			text = "**" + text + "**"
+		}
+		fmt.Fprintf(&b, " %s |", text)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
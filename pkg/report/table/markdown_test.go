@@ -0,0 +1,100 @@
+package table
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"nginx-7d9c", "nginx-7d9c"},
+		{"a|b", `a\|b`},
+		{"line\nbreak\r\nend", "line break end"},
+		{"*bold* _em_ ~strike~", `\*bold\* \_em\_ \~strike\~`},
+		{"`code`", "\\`code\\`"},
+		{"[link](http://x)", `\[link\](http://x)`},
+		{`C:\path`, `C:\\path`},
+		{"<script>&", "&lt;script&gt;&amp;"},
+	}
+	for _, tt := range tests {
+		if got := EscapeMarkdown(tt.in); got != tt.want {
+			t.Errorf("EscapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownWriter(t *testing.T) {
+	long := strings.Repeat("x", markdownLongCell+10)
+	tbl := New("Pods | all",
+		Column{Header: "NAME"},
+		Column{Header: "COUNT", Kind: Integer},
+	)
+	tbl.AddRow("web_1", 2)
+	tbl.AddRow(long, 3)
+	tbl.SetTotals("TOTAL", 5)
+	tbl.AddNote("*estimated*")
+
+	var buf bytes.Buffer
+	w := NewMarkdownWriter(&buf)
+	if err := w.WriteMarkdown(tbl); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Footnotes(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "### Pods \\| all\n\n" +
+		"| NAME | COUNT |\n" +
+		"| --- | ---: |\n" +
+		"| web\\_1 | 2 |\n" +
+		"| " + long[:markdownLongCell] + "…[^1] | 3 |\n" +
+		"| **TOTAL** | **5** |\n" +
+		"\n" +
+		"_\\*estimated\\*_\n\n" +
+		"[^1]: " + long + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdownTruncation(t *testing.T) {
+	tests := []struct {
+		name string
+		cell string
+		want string
+	}{
+		{
+			name: "cut after an escape",
+			cell: strings.Repeat("a", markdownLongCell-1) + "*" + "tail",
+			want: strings.Repeat("a", markdownLongCell-1) + "…[^1]",
+		},
+		{
+			name: "cut inside an entity",
+			cell: strings.Repeat("a", markdownLongCell-3) + "<tail",
+			want: strings.Repeat("a", markdownLongCell-3) + "…[^1]",
+		},
+		{
+			name: "cut after a space",
+			cell: strings.Repeat("a", markdownLongCell-1) + " tail",
+			want: strings.Repeat("a", markdownLongCell-1) + "…[^1]",
+		},
+		{
+			name: "not cut at the limit",
+			cell: strings.Repeat("a", markdownLongCell),
+			want: strings.Repeat("a", markdownLongCell),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewMarkdownWriter(nil)
+			got := w.line([]string{tt.cell}, false)
+			if want := "| " + tt.want + " |\n"; got != want {
+				t.Errorf("line = %q, want %q", got, want)
+			}
+		})
+	}
+}
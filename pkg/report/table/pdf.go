@@ -0,0 +1,359 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/jung-kurt/gofpdf/v2"
+)
+
+const (
+	pdfRowHeight = 8.0
+	// pdfNarrowColumns is the column count above which tables are drawn in a smaller font.
+	pdfNarrowColumns = 6
+	// pdfMaxLines is the number of lines a wrapped cell is cut to.
+	pdfMaxLines = 20
+)
+
+// PDFStyle is the look of the tables and charts of a PDF.
+type PDFStyle struct {
+	// Font is the font family text is drawn in. It must be a core font of gofpdf or have been
+	// added to the PDF in the regular, bold and italic styles.
+	Font string
+	// FauxBold is set when the bold style of Font is its regular face, so that SetPDFFont draws
+	// bold text outlined as well as filled to make it heavier.
+	FauxBold bool
+	// HeaderFill is the background of header rows, as RGB.
+	HeaderFill [3]int
+	// Accent is the main colour of charts, as RGB.
+	Accent [3]int
+}
+
+// PDF is a document being drawn together with the style its tables and charts are drawn in.
+// Every report creates its own, so renders that run at the same time do not share a style.
+type PDF struct {
+	*gofpdf.Fpdf
+	Style PDFStyle
+
+	// replaced is set once PDFText has drawn a character as a white square.
+	replaced bool
+}
+
+// NewPDF returns pdf drawn in style.
+func NewPDF(pdf *gofpdf.Fpdf, style PDFStyle) *PDF {
+	return &PDF{Fpdf: pdf, Style: style}
+}
+
+// Replaced reports whether PDFText has replaced a character of the text drawn into pdf, so
+// that the document can tell its reader.
+func (pdf *PDF) Replaced() bool {
+	return pdf.replaced
+}
+
+// SetPDFFont selects the font of the style of pdf in the given style, such as "B", and size.
+// Text drawn in PDFs whose style is FauxBold must select its font this way. Outlined bold text
+// is stroked in the current draw colour, which should then match the text colour.
+func SetPDFFont(pdf *PDF, style string, size float64) {
+	pdf.SetFont(pdf.Style.Font, style, size)
+	if !pdf.Style.FauxBold {
+		return
+	}
+	if strings.Contains(style, "B") {
+		pdf.SetTextRenderingMode(2)
+	} else {
+		pdf.SetTextRenderingMode(0)
+	}
+}
+
+// PDFText returns text with the characters gofpdf cannot encode, those beyond the Basic
+// Multilingual Plane such as most emoji, replaced by a white square, and the invisible
+// joiners and variation selectors of emoji sequences left out. pdf records the replacement,
+// see Replaced.
+func PDFText(pdf *PDF, text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r > 0xFFFF:
+			pdf.replaced = true
+			return '\u25A1'
+		case r == '\u200D' || r == '\uFE0E' || r == '\uFE0F':
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// WritePDF draws the tables one after another at the current position. The columns are sized
+// to their content within the page margins, text too long for its column wraps onto more
+// lines, every cell of a row takes the height of the tallest, and the header row is repeated
+// whenever a row would cross the bottom margin.
+func WritePDF(pdf *PDF, tables ...*Table) {
+	for i, t := range tables {
+		if i > 0 {
+			pdf.Ln(5)
+		}
+		writePDF(pdf, t)
+	}
+}
+
+func writePDF(pdf *PDF, t *Table) {
+	fontSize := 8.0
+	if len(t.Columns) > pdfNarrowColumns {
+		fontSize = 6
+	}
+	widths := pdfWidths(pdf, t, fontSize)
+
+	if t.Title != "" {
+		SetPDFFont(pdf, "B", 12)
+		pdf.Cell(0, 10, PDFText(pdf, t.Title))
+		pdf.Ln(10)
+	}
+
+	fill := pdf.Style.HeaderFill
+	printHeaders := func() {
+		SetPDFFont(pdf, "B", fontSize)
+		pdf.SetFillColor(fill[0], fill[1], fill[2])
+		drawPDFRow(pdf, widths, fontSize, t.Headers(), true, func(int) string { return "C" })
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+	rowHeight := func(cells []string, style string) float64 {
+		SetPDFFont(pdf, style, fontSize)
+		return pdfHeight(pdfLines(pdf, widths, cells), fontSize)
+	}
+
+	printRow := func(row Row, style string) {
+		cells := t.Strings(row)
+		if pdf.GetY()+rowHeight(cells, style) > pageHeight-bottomMargin {
+			pdf.AddPage()
+			printHeaders()
+		}
+		SetPDFFont(pdf, style, fontSize)
+
+		drawPDFRow(pdf, widths, fontSize, cells, false, func(i int) string {
+			if t.Columns[i].Kind != Text {
+				return "C"
+			}
+			return "L"
+		})
+	}
+
+	// The header row is kept on the same page as the first row below it.
+	height := rowHeight(t.Headers(), "B")
+	if len(t.Rows) > 0 {
+		height += rowHeight(t.Strings(t.Rows[0]), "")
+	}
+	if pdf.GetY()+height > pageHeight-bottomMargin {
+		pdf.AddPage()
+	}
+	printHeaders()
+	for _, row := range t.Rows {
+		printRow(row, "")
+	}
+	if t.Totals != nil {
+		printRow(t.Totals, "B")
+	}
+
+	if len(t.Notes) > 0 {
+		pdf.Ln(2)
+		SetPDFFont(pdf, "I", fontSize)
+		for _, note := range t.Notes {
+			pdf.MultiCell(0, 5, PDFText(pdf, note), "", "L", false)
+		}
+	}
+	SetPDFFont(pdf, "", 12)
+}
+
+// drawPDFRow draws a row of bordered cells at the current position in the current font,
+// filled with the current fill colour if fill is set, wrapping text that does not fit its
+// column, and moves below it. Every cell of the row takes the height of the tallest.
+func drawPDFRow(pdf *PDF, widths []float64, fontSize float64, cells []string, fill bool, align func(int) string) {
+	lines := pdfLines(pdf, widths, cells)
+	height := pdfHeight(lines, fontSize)
+	lineHeight := pdfLineHeight(fontSize)
+
+	// The page breaks between rows are decided by the caller; a line of a cell must not start
+	// another page on its own.
+	auto, margin := pdf.GetAutoPageBreak()
+	pdf.SetAutoPageBreak(false, margin)
+	defer pdf.SetAutoPageBreak(auto, margin)
+
+	style := "D"
+	if fill {
+		style = "FD"
+	}
+	left, y := pdf.GetXY()
+	x := left
+	for i, cellLines := range lines {
+		pdf.Rect(x, y, widths[i], height, style)
+		top := y + (height-float64(len(cellLines))*lineHeight)/2
+		for j, line := range cellLines {
+			pdf.SetXY(x, top+float64(j)*lineHeight)
+			pdf.CellFormat(widths[i], lineHeight, line, "", 0, align(i), false, 0, "")
+		}
+		x += widths[i]
+	}
+	pdf.SetXY(left, y+height)
+}
+
+// pdfLines splits the text of every cell into the lines that fit its column in the current
+// font, at most pdfMaxLines of them.
+func pdfLines(pdf *PDF, widths []float64, cells []string) [][]string {
+	lines := make([][]string, len(widths))
+	for i := range widths {
+		if i >= len(cells) || cells[i] == "" {
+			continue
+		}
+		cellLines := wrapPDFText(pdf, PDFText(pdf, cells[i]), widths[i]-2*pdf.GetCellMargin())
+		if len(cellLines) > pdfMaxLines {
+			cellLines = append(cellLines[:pdfMaxLines-1], "...")
+		}
+		lines[i] = cellLines
+	}
+	return lines
+}
+
+// wrapPDFText breaks text into lines no wider than width in the current font, between words
+// where it can and within words longer than a line. Line breaks in text are kept.
+func wrapPDFText(pdf *PDF, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if pdf.GetStringWidth(candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// A word wider than the column is broken wherever the line is full.
+			line = ""
+			for _, r := range word {
+				if line != "" && pdf.GetStringWidth(line+string(r)) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfHeight returns the height of a row whose cells hold the given lines: pdfRowHeight for a
+// single line, and enough for the tallest cell otherwise.
+func pdfHeight(lines [][]string, fontSize float64) float64 {
+	most := 1
+	for _, cellLines := range lines {
+		most = max(most, len(cellLines))
+	}
+	if most == 1 {
+		return pdfRowHeight
+	}
+	return float64(most)*pdfLineHeight(fontSize) + 2
+}
+
+// pdfLineHeight returns the height of a line of wrapped text in mm.
+func pdfLineHeight(fontSize float64) float64 {
+	return fontSize * 0.5
+}
+
+// pdfWidths shares the width between the page margins among the columns of a table by their
+// content. Every column is first given the width of its longest word, so that words are only
+// broken within a column when they take more than its share by Width, and the rest of the
+// width goes to the columns whose text still wraps, in proportion to how much more they need
+// and to their Width. Once every cell fits on a line, what is left is shared by Width.
+func pdfWidths(pdf *PDF, t *Table, fontSize float64) []float64 {
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	available := pageWidth - left - right
+	margin := 2 * pdf.GetCellMargin()
+	font := pdf.Style.Font
+
+	var total float64
+	for _, c := range t.Columns {
+		total += weight(c)
+	}
+
+	// The widest line of a column is its natural width, and its widest word the narrowest it
+	// can be without breaking words.
+	natural := make([]float64, len(t.Columns))
+	narrowest := make([]float64, len(t.Columns))
+	measure := func(cells []string, style string) {
+		pdf.SetFont(font, style, fontSize)
+		for i, cell := range cells {
+			for _, line := range strings.Split(PDFText(pdf, cell), "\n") {
+				natural[i] = max(natural[i], pdf.GetStringWidth(line)+margin)
+				for _, word := range strings.Fields(line) {
+					narrowest[i] = max(narrowest[i], pdf.GetStringWidth(word)+margin)
+				}
+			}
+		}
+	}
+	measure(t.Headers(), "B")
+	for _, row := range t.Rows {
+		measure(t.Strings(row), "")
+	}
+	if t.Totals != nil {
+		measure(t.Strings(t.Totals), "B")
+	}
+
+	widths := make([]float64, len(t.Columns))
+	var used, wanted float64
+	for i, c := range t.Columns {
+		widths[i] = min(narrowest[i], available*weight(c)/total)
+		used += widths[i]
+	}
+	if used > available {
+		for i := range widths {
+			widths[i] *= available / used
+		}
+		return widths
+	}
+	for i, c := range t.Columns {
+		wanted += (natural[i] - widths[i]) * weight(c)
+	}
+
+	room := available - used
+	if wanted > 0 {
+		// Each column takes its share of the room, but never more than it needs; the room it
+		// leaves is handed out again among the columns that still wrap.
+		for room > 0.01 && wanted > 0 {
+			share := room / wanted
+			room, wanted = 0, 0
+			for i, c := range t.Columns {
+				need := natural[i] - widths[i]
+				if need <= 0 {
+					continue
+				}
+				grow := min(need, need*weight(c)*share)
+				widths[i] += grow
+				if grow < need {
+					wanted += (need - grow) * weight(c)
+				}
+			}
+			room = available
+			for _, w := range widths {
+				room -= w
+			}
+		}
+	}
+	if room > 0.01 {
+		for i, c := range t.Columns {
+			widths[i] += room * weight(c) / total
+		}
+	}
+	return widths
+}
+
+func weight(c Column) float64 {
+	if c.Width <= 0 {
+		return 1
+	}
+	return c.Width
+}
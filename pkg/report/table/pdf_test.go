@@ -0,0 +1,180 @@
+package table
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf/v2"
+)
+
+func TestPDFText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		want     string
+		replaced bool
+	}{
+		{name: "plain", text: "kube-system", want: "kube-system"},
+		{name: "accents and kana", text: "café ノード", want: "café ノード"},
+		{name: "emoji", text: "team 🚀", want: "team □", replaced: true},
+		{name: "emoji sequence", text: "👩‍💻 ops", want: "□□ ops", replaced: true},
+		{name: "variation selector", text: "❤️", want: "❤"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := NewPDF(gofpdf.New("P", "mm", "A4", ""), PDFStyle{Font: "Arial"})
+			if got := PDFText(pdf, tt.text); got != tt.want {
+				t.Errorf("PDFText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if got := pdf.Replaced(); got != tt.replaced {
+				t.Errorf("Replaced() = %v, want %v", got, tt.replaced)
+			}
+		})
+	}
+}
+
+// newTestPDF returns a portrait A4 PDF with a page added and the regular font set.
+func newTestPDF() *PDF {
+	pdf := NewPDF(gofpdf.New("P", "mm", "A4", ""), PDFStyle{Font: "Arial"})
+	pdf.AddPage()
+	SetPDFFont(pdf, "", 8)
+	return pdf
+}
+
+func TestWrapPDFText(t *testing.T) {
+	pdf := newTestPDF()
+	word := pdf.GetStringWidth("node")
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		want  []string
+	}{
+		{name: "fits", text: "node node", width: 100, want: []string{"node node"}},
+		{name: "between words", text: "node node node", width: 1.5 * word, want: []string{"node", "node", "node"}},
+		{name: "line breaks kept", text: "node\nnode", width: 100, want: []string{"node", "node"}},
+		{name: "long word broken", text: "nodenode", width: word, want: []string{"node", "node"}},
+		{name: "empty", text: "", width: 100, want: []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapPDFText(pdf, tt.text, tt.width)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapPDFText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPDFLines(t *testing.T) {
+	pdf := newTestPDF()
+	widths := []float64{20, 20, 20}
+	long := strings.Repeat("container ", 3*pdfMaxLines)
+	lines := pdfLines(pdf, widths, []string{"pod", "", long})
+	if !reflect.DeepEqual(lines[0], []string{"pod"}) {
+		t.Errorf("lines[0] = %q, want [pod]", lines[0])
+	}
+	if lines[1] != nil {
+		t.Errorf("lines[1] = %q, want none for an empty cell", lines[1])
+	}
+	if len(lines[2]) != pdfMaxLines || lines[2][pdfMaxLines-1] != "..." {
+		t.Errorf("lines[2] has %d lines ending %q, want %d ending \"...\"", len(lines[2]), lines[2][len(lines[2])-1], pdfMaxLines)
+	}
+	if got := pdfHeight(lines[:2], 8); got != pdfRowHeight {
+		t.Errorf("pdfHeight of single lines = %v, want %v", got, pdfRowHeight)
+	}
+	if got, want := pdfHeight(lines, 8), float64(pdfMaxLines)*pdfLineHeight(8)+2; got != want {
+		t.Errorf("pdfHeight of wrapped lines = %v, want %v", got, want)
+	}
+}
+
+func TestPDFWidths(t *testing.T) {
+	long := strings.Repeat("kube-system-controller ", 20)
+	tests := []struct {
+		name    string
+		columns []Column
+		rows    []Row
+		// wider lists pairs of columns where the first must come out wider than the second.
+		wider [][2]int
+	}{
+		{
+			name:    "short content shared by width",
+			columns: []Column{{Header: "A", Width: 1}, {Header: "B", Width: 3}},
+			rows:    []Row{{"a", "b"}},
+			wider:   [][2]int{{1, 0}},
+		},
+		{
+			name:    "long text takes the room",
+			columns: []Column{{Header: "Name"}, {Header: "Count", Kind: Integer}, {Header: "Description"}},
+			rows:    []Row{{"pod-1", int64(3), long}},
+			wider:   [][2]int{{2, 0}, {2, 1}},
+		},
+		{
+			name:    "long word capped by width",
+			columns: []Column{{Header: "Name", Width: 1}, {Header: "Labels", Width: 1}},
+			rows:    []Row{{strings.Repeat("x", 400), "app=web"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := newTestPDF()
+			pageWidth, _ := pdf.GetPageSize()
+			left, _, right, _ := pdf.GetMargins()
+			available := pageWidth - left - right
+
+			widths := pdfWidths(pdf, &Table{Columns: tt.columns, Rows: tt.rows}, 8)
+			var sum float64
+			for _, w := range widths {
+				if w <= 0 {
+					t.Errorf("widths = %v, want every column wider than zero", widths)
+				}
+				sum += w
+			}
+			if math.Abs(sum-available) > 0.1 {
+				t.Errorf("widths = %v sum to %v, want %v", widths, sum, available)
+			}
+			for _, pair := range tt.wider {
+				if widths[pair[0]] <= widths[pair[1]] {
+					t.Errorf("widths = %v, want column %d wider than %d", widths, pair[0], pair[1])
+				}
+			}
+		})
+	}
+}
+
+func TestWritePDFRepeatsHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		rows      int
+		wantPages int
+	}{
+		{name: "one page", rows: 5, wantPages: 1},
+		{name: "several pages", rows: 100, wantPages: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := newTestPDF()
+			pdf.SetCompression(false)
+			tbl := &Table{Columns: []Column{{Header: "PodHeader"}, {Header: "Namespace"}}}
+			for i := 0; i < tt.rows; i++ {
+				tbl.Rows = append(tbl.Rows, Row{fmt.Sprintf("pod-%d", i), "default"})
+			}
+			WritePDF(pdf, tbl)
+
+			var out bytes.Buffer
+			if err := pdf.Output(&out); err != nil {
+				t.Fatal(err)
+			}
+			if got := pdf.PageCount(); got != tt.wantPages {
+				t.Errorf("PageCount() = %d, want %d", got, tt.wantPages)
+			}
+			if got := bytes.Count(out.Bytes(), []byte("(PodHeader)")); got != tt.wantPages {
+				t.Errorf("header drawn %d times, want once on each of %d pages", got, tt.wantPages)
+			}
+		})
+	}
+}
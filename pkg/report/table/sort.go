@@ -0,0 +1,200 @@
+package table
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+func init() {
+	gob.Register(time.Duration(0))
+}
+
+// DefaultSortBuffer is the number of rows a Sorter keeps in memory when no limit is given.
+const DefaultSortBuffer = 50000
+
+// Sorter is a RowWriter that passes rows on to another RowWriter in sorted order. It keeps at
+// most Limit rows in memory; beyond that, sorted runs are spilled to temporary files and merged
+// when the table ends, so memory stays bounded however many rows there are.
+type Sorter struct {
+	next  RowWriter
+	less  Less
+	limit int
+
+	buf  []Row
+	runs []*os.File
+}
+
+// NewSorter returns a Sorter writing to next. A limit below one uses DefaultSortBuffer.
+func NewSorter(next RowWriter, less Less, limit int) *Sorter {
+	if limit < 1 {
+		limit = DefaultSortBuffer
+	}
+	return &Sorter{next: next, less: less, limit: limit}
+}
+
+func (s *Sorter) Begin(t *Table) error {
+	s.cleanup()
+	return s.next.Begin(t)
+}
+
+func (s *Sorter) Row(row Row) error {
+	s.buf = append(s.buf, row)
+	if len(s.buf) >= s.limit {
+		return s.spill()
+	}
+	return nil
+}
+
+func (s *Sorter) End(t *Table) error {
+	defer s.cleanup()
+
+	sort.SliceStable(s.buf, func(i, j int) bool { return s.less(s.buf[i], s.buf[j]) })
+	if len(s.runs) == 0 {
+		for _, row := range s.buf {
+			if err := s.next.Row(row); err != nil {
+				return err
+			}
+		}
+		s.buf = s.buf[:0]
+		return s.next.End(t)
+	}
+
+	if err := s.merge(); err != nil {
+		return err
+	}
+	return s.next.End(t)
+}
+
+// spill sorts the buffered rows and writes them to a new temporary run file.
+func (s *Sorter) spill() error {
+	sort.SliceStable(s.buf, func(i, j int) bool { return s.less(s.buf[i], s.buf[j]) })
+
+	f, err := os.CreateTemp("", "kubereport-sort-*")
+	if err != nil {
+		return fmt.Errorf("failed to create sort run file: %v", err)
+	}
+	s.runs = append(s.runs, f)
+
+	w := bufio.NewWriter(f)
+	enc := gob.NewEncoder(w)
+	for _, row := range s.buf {
+		if err := enc.Encode(row); err != nil {
+			return fmt.Errorf("failed to write sort run file: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write sort run file: %v", err)
+	}
+	s.buf = s.buf[:0]
+	return nil
+}
+
+// merge passes the rows of every run, and of the in-memory buffer, to the next writer in order.
+func (s *Sorter) merge() error {
+	h := &runHeap{less: s.less}
+	for _, f := range s.runs {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to read sort run file: %v", err)
+		}
+		r := &run{dec: gob.NewDecoder(bufio.NewReader(f))}
+		if err := h.push(r); err != nil {
+			return err
+		}
+	}
+	if err := h.push(&run{mem: s.buf}); err != nil {
+		return err
+	}
+
+	for h.Len() > 0 {
+		r := h.runs[0]
+		if err := s.next.Row(r.head); err != nil {
+			return err
+		}
+		ok, err := r.advance()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// Close removes the run files of a table that was not ended, such as when producing its rows
+// failed. It does nothing once End has been called.
+func (s *Sorter) Close() error {
+	s.cleanup()
+	return nil
+}
+
+func (s *Sorter) cleanup() {
+	for _, f := range s.runs {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	s.runs = nil
+	s.buf = s.buf[:0]
+}
+
+// run is a sorted sequence of rows, read from a spill file or from memory.
+type run struct {
+	dec  *gob.Decoder
+	mem  []Row
+	head Row
+}
+
+// advance loads the next row of the run into head, reporting false at the end of the run.
+func (r *run) advance() (bool, error) {
+	if r.dec == nil {
+		if len(r.mem) == 0 {
+			return false, nil
+		}
+		r.head, r.mem = r.mem[0], r.mem[1:]
+		return true, nil
+	}
+
+	var row Row
+	if err := r.dec.Decode(&row); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read sort run file: %v", err)
+	}
+	r.head = row
+	return true, nil
+}
+
+// runHeap orders runs by their head row.
+type runHeap struct {
+	runs []*run
+	less Less
+}
+
+func (h *runHeap) push(r *run) error {
+	ok, err := r.advance()
+	if err != nil || !ok {
+		return err
+	}
+	heap.Push(h, r)
+	return nil
+}
+
+func (h *runHeap) Len() int           { return len(h.runs) }
+func (h *runHeap) Less(i, j int) bool { return h.less(h.runs[i].head, h.runs[j].head) }
+func (h *runHeap) Swap(i, j int)      { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+func (h *runHeap) Push(x any)         { h.runs = append(h.runs, x.(*run)) }
+func (h *runHeap) Pop() any {
+	last := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return last
+}
@@ -0,0 +1,163 @@
+package table
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestByColumn(t *testing.T) {
+	tests := []struct {
+		name string
+		desc bool
+		a, b any
+		want bool
+	}{
+		{name: "integers", a: int64(1), b: int64(2), want: true},
+		{name: "integers descending", desc: true, a: int64(1), b: int64(2), want: false},
+		{name: "decimals", a: 0.5, b: 1.5, want: true},
+		{name: "durations", a: time.Minute, b: time.Hour, want: true},
+		{name: "strings", a: "b", b: "a", want: false},
+		{name: "booleans", a: false, b: true, want: true},
+		{name: "equal", a: int64(3), b: int64(3), want: false},
+		{name: "empty last", a: nil, b: int64(1), want: false},
+		{name: "empty last descending", desc: true, a: int64(1), b: nil, want: true},
+		{name: "both empty", a: nil, b: nil, want: false},
+		{name: "mixed types by text", a: int64(10), b: "N/A", want: true},
+		{name: "mixed types by text reversed", a: "N/A", b: int64(10), want: false},
+		{name: "decimal and integer by text", a: 2.5, b: int64(10), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			less := ByColumn(1, tt.desc)
+			got := less(Row{"x", tt.a}, Row{"y", tt.b})
+			if got != tt.want {
+				t.Errorf("ByColumn(1, %v)(%v, %v) = %v, want %v", tt.desc, tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThen(t *testing.T) {
+	less := ByColumn(0, false).Then(ByColumn(1, true))
+	rows := []Row{{"a", int64(1)}, {"a", int64(2)}, {"b", int64(0)}}
+	if !less(rows[1], rows[0]) {
+		t.Errorf("rows equal in the first column are not ordered by the second")
+	}
+	if !less(rows[0], rows[2]) || less(rows[2], rows[1]) {
+		t.Errorf("rows are not ordered by the first column first")
+	}
+}
+
+func TestSorter(t *testing.T) {
+	rows := []Row{
+		{"e", int64(5)},
+		{"n/a", nil},
+		{"c", int64(3)},
+		{"a", int64(1)},
+		{"g", int64(7)},
+		{"d", int64(4)},
+		{"b", int64(2)},
+		{"f", int64(6)},
+		{"dup", int64(4)},
+	}
+	want := []Row{
+		{"g", int64(7)},
+		{"f", int64(6)},
+		{"e", int64(5)},
+		{"d", int64(4)},
+		{"dup", int64(4)},
+		{"c", int64(3)},
+		{"b", int64(2)},
+		{"a", int64(1)},
+		{"n/a", nil},
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+	}{
+		{name: "in memory", limit: 0},
+		{name: "spills every row", limit: 1},
+		{name: "spills every two rows", limit: 2},
+		{name: "spills with rows left in memory", limit: 4},
+		{name: "limit equal to the rows", limit: len(rows)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Collector{}
+			s := NewSorter(c, ByColumn(1, true).Then(ByColumn(0, false)), tt.limit)
+			tbl := New("Numbers", Column{Header: "NAME"}, Column{Header: "VALUE", Kind: Integer})
+			tbl.Rows = rows
+			if err := Write(s, tbl); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if len(c.Tables) != 1 {
+				t.Fatalf("got %d tables, want 1", len(c.Tables))
+			}
+			if got := c.Tables[0].Rows; !reflect.DeepEqual(got, want) {
+				t.Errorf("rows = %v, want %v", got, want)
+			}
+			if len(s.runs) != 0 {
+				t.Errorf("%d run files left after End", len(s.runs))
+			}
+		})
+	}
+}
+
+func TestSorterReuse(t *testing.T) {
+	c := &Collector{}
+	s := NewSorter(c, ByColumn(0, false), 1)
+	for _, values := range [][]any{{int64(2), int64(1)}, {int64(4), int64(3)}} {
+		tbl := New("", Column{Header: "N", Kind: Integer})
+		for _, v := range values {
+			tbl.AddRow(v)
+		}
+		if err := Write(s, tbl); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	want := [][]Row{{{int64(1)}, {int64(2)}}, {{int64(3)}, {int64(4)}}}
+	for i, tbl := range c.Tables {
+		if !reflect.DeepEqual(tbl.Rows, want[i]) {
+			t.Errorf("table %d rows = %v, want %v", i, tbl.Rows, want[i])
+		}
+	}
+}
+
+func TestSorterRemovesRuns(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(s *Sorter) error
+	}{
+		{name: "closed", finish: func(s *Sorter) error { return s.Close() }},
+		{name: "next table", finish: func(s *Sorter) error { return s.Begin(New("")) }},
+		{name: "ended", finish: func(s *Sorter) error { return s.End(New("")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("TMPDIR", dir)
+
+			s := NewSorter(&Collector{}, ByColumn(0, false), 1)
+			if err := s.Begin(New("", Column{Header: "N", Kind: Integer})); err != nil {
+				t.Fatal(err)
+			}
+			for i := int64(0); i < 3; i++ {
+				if err := s.Row(Row{i}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) == 0 {
+				t.Fatalf("no run files spilled to %s", dir)
+			}
+			if err := tt.finish(s); err != nil {
+				t.Fatal(err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("%d run files left in %s", len(entries), dir)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package table
+
+import (
+	"strings"
+	"time"
+)
+
+// RowWriter receives a table a row at a time, so that rows can be written out as they are
+// produced instead of being held in memory. Begin is called once with the table's columns and
+// title but no rows, then Row once per row, then End with the table's totals and notes.
+type RowWriter interface {
+	Begin(t *Table) error
+	Row(row Row) error
+	End(t *Table) error
+}
+
+// Write passes a complete table to a RowWriter.
+func Write(w RowWriter, t *Table) error {
+	head := *t
+	head.Rows = nil
+	if err := w.Begin(&head); err != nil {
+		return err
+	}
+	for _, row := range t.Rows {
+		if err := w.Row(row); err != nil {
+			return err
+		}
+	}
+	return w.End(&head)
+}
+
+// Collector is a RowWriter that keeps every table it receives, rows included.
+type Collector struct {
+	Tables []*Table
+}
+
+func (c *Collector) Begin(t *Table) error {
+	c.Tables = append(c.Tables, t)
+	return nil
+}
+
+func (c *Collector) Row(row Row) error {
+	t := c.Tables[len(c.Tables)-1]
+	t.Rows = append(t.Rows, row)
+	return nil
+}
+
+func (c *Collector) End(*Table) error {
+	return nil
+}
+
+// Less reports whether row a sorts before row b.
+type Less func(a, b Row) bool
+
+// ByColumn orders rows by the value in one column, descending if desc is set. Empty cells sort
+// last either way.
+func ByColumn(col int, desc bool) Less {
+	return func(a, b Row) bool {
+		va, vb := a[col], b[col]
+		if va == nil || vb == nil {
+			return va != nil && vb == nil
+		}
+		c := compare(va, vb)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	}
+}
+
+// Then orders rows by less, and by next among rows less considers equal.
+func (less Less) Then(next Less) Less {
+	return func(a, b Row) bool {
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return next(a, b)
+	}
+}
+
+// compare orders two cells of a column. Cells of different types, such as a placeholder like
+// "N/A" in a numeric column, are compared by their text.
+func compare(a, b any) int {
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmp(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp(x, y)
+		}
+	case time.Duration:
+		if y, ok := b.(time.Duration); ok {
+			return cmp(x, y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0
+			case !x:
+				return -1
+			}
+			return 1
+		}
+	}
+	var c Column
+	return strings.Compare(c.Text(a), c.Text(b))
+}
+
+func cmp[T int64 | float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package table
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// StructuredColumn describes a column of a StructuredTable.
+type StructuredColumn struct {
+	// Key is the name of the column's field in every row: the header in snake case.
+	Key    string `json:"key"`
+	Header string `json:"header"`
+	// Type is one of string, integer, number, percent, boolean or duration.
+	Type string `json:"type"`
+	Unit string `json:"unit,omitempty"`
+}
+
+// StructuredTable is the form tables take in JSON and YAML output: rows are objects keyed by
+// column and hold typed values rather than formatted text.
+type StructuredTable struct {
+	Title   string             `json:"title,omitempty"`
+	Columns []StructuredColumn `json:"columns"`
+	Rows    []map[string]any   `json:"rows"`
+	Totals  map[string]any     `json:"totals,omitempty"`
+	Notes   []string           `json:"notes,omitempty"`
+}
+
+// Structured returns the structured form of a table. Integer columns hold integers, Decimal and
+// Percent columns floating-point numbers, Bool columns booleans and Duration columns whole
+// seconds; empty cells are null.
+func Structured(t *Table) StructuredTable {
+	st := StructuredTable{
+		Title:   t.Title,
+		Columns: StructuredColumns(t),
+		Rows:    make([]map[string]any, 0, len(t.Rows)),
+		Notes:   t.Notes,
+	}
+
+	for _, row := range t.Rows {
+		st.Rows = append(st.Rows, st.object(row))
+	}
+	if t.Totals != nil {
+		st.Totals = st.object(t.Totals)
+	}
+	return st
+}
+
+// StructuredColumns returns the structured form of the columns of a table.
+func StructuredColumns(t *Table) []StructuredColumn {
+	columns := make([]StructuredColumn, 0, len(t.Columns))
+	seen := make(map[string]int)
+	for _, c := range t.Columns {
+		key := Key(c.Header)
+		if key == "" {
+			key = "column"
+		}
+		// Repeated keys are numbered from the second one on: key, key_2, key_3.
+		if seen[key]++; seen[key] > 1 {
+			key = fmt.Sprintf("%s_%d", key, seen[key])
+		}
+		column := StructuredColumn{Key: key, Header: c.Header, Type: c.Kind.String(), Unit: c.Unit}
+		if c.Kind == Duration {
+			column.Unit = "s"
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+func (st StructuredTable) object(row Row) map[string]any {
+	obj := make(map[string]any, len(st.Columns))
+	for i, c := range st.Columns {
+		var v any
+		if i < len(row) {
+			v = StructuredValue(row[i])
+		}
+		obj[c.Key] = v
+	}
+	return obj
+}
+
+// StructuredValue returns a cell value as it is written in structured output: durations as
+// whole seconds and everything else unchanged.
+func StructuredValue(v any) any {
+	if d, ok := v.(time.Duration); ok {
+		return int64(d.Round(time.Second) / time.Second)
+	}
+	return v
+}
+
+// Key returns a header or title in snake case, such as "cpu_req_mcpu" for "CPU REQ (MCPU)".
+func Key(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if underscore && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			underscore = false
+			b.WriteRune(r)
+		} else {
+			underscore = true
+		}
+	}
+	return b.String()
+}
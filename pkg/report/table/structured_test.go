@@ -0,0 +1,86 @@
+package table
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"NAME", "name"},
+		{"CPU REQ (MCPU)", "cpu_req_mcpu"},
+		{"[ POD DETAILS ]", "pod_details"},
+		{"Memory-Usage %", "memory_usage"},
+		{"  ", ""},
+		{"ノード名", "ノード名"},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.in); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStructuredColumns(t *testing.T) {
+	tbl := New("",
+		Column{Header: "NAME"},
+		Column{Header: "CPU", Kind: Integer, Unit: "mCPU"},
+		Column{Header: "cpu", Kind: Percent},
+		Column{Header: "CPU", Kind: Decimal},
+		Column{Header: "%"},
+		Column{Header: "AGE", Kind: Duration, Unit: "h"},
+		Column{Header: "READY", Kind: Bool},
+	)
+	want := []StructuredColumn{
+		{Key: "name", Header: "NAME", Type: "string"},
+		{Key: "cpu", Header: "CPU", Type: "integer", Unit: "mCPU"},
+		{Key: "cpu_2", Header: "cpu", Type: "percent"},
+		{Key: "cpu_3", Header: "CPU", Type: "number"},
+		{Key: "column", Header: "%", Type: "string"},
+		{Key: "age", Header: "AGE", Type: "duration", Unit: "s"},
+		{Key: "ready", Header: "READY", Type: "boolean"},
+	}
+	if got := StructuredColumns(tbl); !reflect.DeepEqual(got, want) {
+		t.Errorf("StructuredColumns() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestStructured(t *testing.T) {
+	tbl := New("Pods",
+		Column{Header: "NAME"},
+		Column{Header: "RESTARTS", Kind: Integer},
+		Column{Header: "CPU %", Kind: Percent},
+		Column{Header: "READY", Kind: Bool},
+		Column{Header: "AGE", Kind: Duration},
+	)
+	tbl.AddRow("web", 3, 12.5, true, 90*time.Minute+400*time.Millisecond)
+	tbl.AddRow("db", nil, nil, false, nil)
+	tbl.SetTotals("TOTAL", 3)
+	tbl.AddNote("estimated")
+
+	st := Structured(tbl)
+	want := []map[string]any{
+		{"name": "web", "restarts": int64(3), "cpu": 12.5, "ready": true, "age": int64(5400)},
+		{"name": "db", "restarts": nil, "cpu": nil, "ready": false, "age": nil},
+	}
+	if !reflect.DeepEqual(st.Rows, want) {
+		t.Errorf("rows =\n%v\nwant\n%v", st.Rows, want)
+	}
+	wantTotals := map[string]any{"name": "TOTAL", "restarts": int64(3), "cpu": nil, "ready": nil, "age": nil}
+	if !reflect.DeepEqual(st.Totals, wantTotals) {
+		t.Errorf("totals = %v, want %v", st.Totals, wantTotals)
+	}
+	if st.Title != "Pods" || !reflect.DeepEqual(st.Notes, []string{"estimated"}) {
+		t.Errorf("title %q and notes %q, want Pods and [estimated]", st.Title, st.Notes)
+	}
+}
+
+func TestStructuredEmpty(t *testing.T) {
+	st := Structured(New("", Column{Header: "NAME"}))
+	if st.Rows == nil || len(st.Rows) != 0 || st.Totals != nil {
+		t.Errorf("empty table gives rows %v and totals %v, want [] and none", st.Rows, st.Totals)
+	}
+}
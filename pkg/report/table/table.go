@@ -0,0 +1,179 @@
+// Package table holds the format-neutral model sections describe their content with, and the
+// renderers that turn it into each report format.
+package table
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kind is the type of the values held in a column.
+type Kind int
+
+const (
+	// Text columns hold strings.
+	Text Kind = iota
+	// Integer columns hold int64 values, such as counts, millicores or MiB.
+	Integer
+	// Decimal columns hold float64 values.
+	Decimal
+	// Percent columns hold float64 values between 0 and 100.
+	Percent
+	// Bool columns hold bool values.
+	Bool
+	// Duration columns hold time.Duration values, such as object ages.
+	Duration
+)
+
+// String returns the name of the kind in structured output.
+func (k Kind) String() string {
+	switch k {
+	case Integer:
+		return "integer"
+	case Decimal:
+		return "number"
+	case Percent:
+		return "percent"
+	case Bool:
+		return "boolean"
+	case Duration:
+		return "duration"
+	default:
+		return "string"
+	}
+}
+
+// Column describes one column of a table.
+type Column struct {
+	// Header is the name the column is rendered under.
+	Header string
+	Kind   Kind
+	// Unit is the unit numeric values are expressed in, such as "mCPU" or "MiB". It is empty
+	// for plain counts and text.
+	Unit string
+	// Format is the fmt verb numeric, bool and duration values are rendered as text with, such
+	// as "%dm" or "%.2fGi". Empty uses the default of the kind; durations are rounded to hours.
+	Format string
+	// Width weighs the column against the others in paged formats, which size columns by their
+	// content: it caps how much of the row a long word can claim and shares the room left over.
+	// Zero counts as 1.
+	Width float64
+}
+
+// Row holds one value per column: a string for Text columns, an int64 for Integer columns,
+// a float64 for Decimal and Percent columns, a bool for Bool columns and a time.Duration for
+// Duration columns. A nil value leaves the cell empty.
+type Row []any
+
+// Table is a titled grid of typed values, with optional totals and notes.
+type Table struct {
+	// Title is rendered above the table. Sections with a single table usually leave it empty
+	// and are rendered under the section name alone.
+	Title   string
+	Columns []Column
+	Rows    []Row
+	// Totals is an optional final row summing up the others.
+	Totals Row
+	// Notes are free-text lines rendered below the table.
+	Notes []string
+}
+
+// New returns an empty table with the given columns.
+func New(title string, columns ...Column) *Table {
+	return &Table{Title: title, Columns: columns}
+}
+
+// AddRow appends a row. Go integer and float types are converted to int64 and float64.
+func (t *Table) AddRow(values ...any) {
+	t.Rows = append(t.Rows, normalize(values))
+}
+
+// SetTotals sets the totals row. Go integer and float types are converted to int64 and float64.
+func (t *Table) SetTotals(values ...any) {
+	t.Totals = normalize(values)
+}
+
+// AddNote appends a line of text below the table.
+func (t *Table) AddNote(format string, args ...any) {
+	t.Notes = append(t.Notes, fmt.Sprintf(format, args...))
+}
+
+// Headers returns the header of every column.
+func (t *Table) Headers() []string {
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Header
+	}
+	return headers
+}
+
+// Strings returns the text of every cell of a row.
+func (t *Table) Strings(row Row) []string {
+	cells := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		if i < len(row) {
+			cells[i] = c.Text(row[i])
+		}
+	}
+	return cells
+}
+
+// Text renders a value of the column as text.
+func (c Column) Text(v any) string {
+	if v == nil {
+		return ""
+	}
+	if d, ok := v.(time.Duration); ok && c.Format == "" {
+		return d.Round(time.Hour).String()
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	format := c.Format
+	if format == "" {
+		switch c.Kind {
+		case Integer:
+			format = "%d"
+		case Decimal:
+			format = "%.2f"
+		case Percent:
+			format = "%.2f%%"
+		default:
+			format = "%v"
+		}
+	}
+	return fmt.Sprintf(format, v)
+}
+
+// Numeric reports whether the column holds numbers.
+func (c Column) Numeric() bool {
+	return c.Kind == Integer || c.Kind == Decimal || c.Kind == Percent
+}
+
+func normalize(values []any) Row {
+	row := make(Row, len(values))
+	for i, v := range values {
+		switch n := v.(type) {
+		case int:
+			row[i] = int64(n)
+		case int32:
+			row[i] = int64(n)
+		case uint32:
+			row[i] = int64(n)
+		case float32:
+			row[i] = float64(n)
+		default:
+			row[i] = v
+		}
+	}
+	return row
+}
+
+// Deref returns the value p points to, or nil for an empty cell if p is nil.
+func Deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,281 @@
+package table
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/width"
+)
+
+const (
+	// textGap is the number of spaces between two columns of a text table.
+	textGap = 2
+	// textMinWidth is the width text columns are not narrowed below to fit a table.
+	textMinWidth = 8
+)
+
+// ANSI escape codes used by colour text output.
+const (
+	ansiBold    = "\x1b[1m"
+	ansiHeading = "\x1b[1;4m"
+	ansiRed     = "\x1b[31m"
+	ansiYellow  = "\x1b[33m"
+	ansiReset   = "\x1b[0m"
+)
+
+// Highlight is how much attention a cell calls for, in formats that can show it.
+type Highlight int
+
+const (
+	// Plain cells are rendered as they are.
+	Plain Highlight = iota
+	// Warning cells deserve a look, such as pending pods.
+	Warning
+	// Critical cells need attention, such as NotReady nodes or failed pods.
+	Critical
+)
+
+// TextWriter is a RowWriter that writes tables as aligned plain-text columns, for terminals.
+// It holds the rows of a table until End, to size every column to its widest cell.
+type TextWriter struct {
+	w io.Writer
+	// Width is the number of terminal columns tables are fitted to, by narrowing their widest
+	// text columns and truncating the cells that no longer fit. Zero leaves tables as wide as
+	// their content.
+	Width int
+	// Color writes titles, headers and totals in bold and highlighted cells in colour, with
+	// ANSI escape codes.
+	Color bool
+	// Highlight returns the highlight of a cell of a row of t. Nil leaves every cell plain.
+	Highlight func(t *Table, row Row, col int) Highlight
+
+	table *Table
+	rows  []textRow
+}
+
+// textRow is a row of a text table as it is printed.
+type textRow struct {
+	cells      []string
+	highlights []Highlight
+	bold       bool
+}
+
+// NewTextWriter returns a TextWriter writing to w.
+func NewTextWriter(w io.Writer) *TextWriter {
+	return &TextWriter{w: w}
+}
+
+// WriteText writes the tables one after another, each as its title, the header row, its rows
+// and totals, and its notes.
+func (w *TextWriter) WriteText(tables ...*Table) error {
+	for _, t := range tables {
+		if err := Write(w, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Heading writes the heading of a section, underlined in bold when colour is on.
+func (w *TextWriter) Heading(text string) error {
+	if _, err := fmt.Fprintf(w.w, "%s\n\n", w.style(textCell(text), ansiHeading)); err != nil {
+		return fmt.Errorf("error writing text heading: %v", err)
+	}
+	return nil
+}
+
+// Message writes a line of text, such as why a section is unavailable, coloured according to
+// its highlight when colour is on.
+func (w *TextWriter) Message(text string, highlight Highlight) error {
+	if _, err := fmt.Fprintf(w.w, "%s\n\n", w.style(textCell(text), highlightCode(highlight))); err != nil {
+		return fmt.Errorf("error writing text message: %v", err)
+	}
+	return nil
+}
+
+func (w *TextWriter) Begin(t *Table) error {
+	w.table = t
+	w.rows = nil
+	return nil
+}
+
+func (w *TextWriter) Row(row Row) error {
+	w.rows = append(w.rows, w.row(row, false))
+	return nil
+}
+
+func (w *TextWriter) End(t *Table) error {
+	rows := w.rows
+	if t.Totals != nil {
+		rows = append(rows, w.row(t.Totals, true))
+	}
+	w.rows = nil
+
+	header := textRow{cells: make([]string, len(t.Columns)), highlights: make([]Highlight, len(t.Columns)), bold: true}
+	for i, h := range t.Headers() {
+		header.cells[i] = textCell(h)
+	}
+	widths := w.widths(t, append([]textRow{header}, rows...))
+
+	var b strings.Builder
+	if t.Title != "" {
+		b.WriteString(w.style(textCell(t.Title), ansiBold))
+		b.WriteString("\n")
+	}
+	w.line(&b, t, widths, header)
+	for _, row := range rows {
+		w.line(&b, t, widths, row)
+	}
+	for _, note := range t.Notes {
+		b.WriteString(textCell(note))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if _, err := io.WriteString(w.w, b.String()); err != nil {
+		return fmt.Errorf("error writing text table: %v", err)
+	}
+	return nil
+}
+
+// row converts a row to the text of its cells and their highlights.
+func (w *TextWriter) row(row Row, bold bool) textRow {
+	r := textRow{cells: w.table.Strings(row), highlights: make([]Highlight, len(w.table.Columns)), bold: bold}
+	for i := range r.cells {
+		r.cells[i] = textCell(r.cells[i])
+		if w.Highlight != nil && !bold {
+			r.highlights[i] = w.Highlight(w.table, row, i)
+		}
+	}
+	return r
+}
+
+// widths returns the width of every column: that of its widest cell, with the widest text
+// columns narrowed until the table fits Width, if set.
+func (w *TextWriter) widths(t *Table, rows []textRow) []int {
+	widths := make([]int, len(t.Columns))
+	for _, row := range rows {
+		for i, cell := range row.cells {
+			widths[i] = max(widths[i], textWidth(cell))
+		}
+	}
+	if w.Width <= 0 {
+		return widths
+	}
+
+	total := textGap * max(len(widths)-1, 0)
+	for _, n := range widths {
+		total += n
+	}
+	for total > w.Width {
+		widest := -1
+		for i, c := range t.Columns {
+			if c.Kind == Text && widths[i] > textMinWidth && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
+
+// line writes a row padded to the column widths, numbers aligned to the right.
+func (w *TextWriter) line(b *strings.Builder, t *Table, widths []int, row textRow) {
+	var l strings.Builder
+	for i, cell := range row.cells {
+		if i > 0 {
+			l.WriteString(strings.Repeat(" ", textGap))
+		}
+		cell = truncateText(cell, widths[i])
+		padding := strings.Repeat(" ", widths[i]-textWidth(cell))
+
+		code := highlightCode(row.highlights[i])
+		if code == "" && row.bold {
+			code = ansiBold
+		}
+		cell = w.style(cell, code)
+
+		if t.Columns[i].Numeric() {
+			l.WriteString(padding + cell)
+		} else {
+			l.WriteString(cell + padding)
+		}
+	}
+	b.WriteString(strings.TrimRight(l.String(), " "))
+	b.WriteString("\n")
+}
+
+// highlightCode returns the ANSI escape code of the colour of a highlight.
+func highlightCode(highlight Highlight) string {
+	switch highlight {
+	case Critical:
+		return ansiRed
+	case Warning:
+		return ansiYellow
+	}
+	return ""
+}
+
+// style wraps text in an ANSI escape code when colour is on.
+func (w *TextWriter) style(text, code string) string {
+	if !w.Color || code == "" || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// textCell returns text on a single line, without control characters that would move the
+// cursor or change the colour of the terminal.
+func textCell(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case r < ' ' || (r >= 0x7f && r <= 0x9f):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// textWidth returns the number of terminal columns text takes: two for East Asian wide
+// characters and one for the others.
+func textWidth(text string) int {
+	n := 0
+	for _, r := range text {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+func runeWidth(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// truncateText cuts text to at most n terminal columns, ending it with an ellipsis if it was
+// cut.
+func truncateText(text string, n int) string {
+	if textWidth(text) <= n {
+		return text
+	}
+	var b strings.Builder
+	used := 0
+	for _, r := range text {
+		if used+runeWidth(r) > n-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += runeWidth(r)
+	}
+	b.WriteString("…")
+	return b.String()
+}
@@ -0,0 +1,220 @@
+package table
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSX column widths, in characters, are derived from the headers within these bounds.
+const (
+	xlsxMinWidth = 10
+	xlsxMaxWidth = 50
+)
+
+// XLSXWriter is a RowWriter that writes tables to the worksheets of a workbook, a row at a time.
+// Numeric columns are written as numbers and booleans as booleans so that they can be filtered,
+// summed and pivoted on. The header row of the first table of each sheet is frozen and carries
+// an auto-filter over the rows below it; later tables on the same sheet are stacked beneath it.
+type XLSXWriter struct {
+	file *excelize.File
+
+	sheet  string
+	stream *excelize.StreamWriter
+	next   int
+	tables int
+	rows   int
+	table  *Table
+	// filterStart and filterEnd span the first table of the sheet, which the auto-filter
+	// covers.
+	filterStart, filterEnd string
+
+	bold, percent int
+}
+
+// NewXLSXWriter returns an XLSXWriter adding sheets to file.
+func NewXLSXWriter(file *excelize.File) (*XLSXWriter, error) {
+	bold, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, fmt.Errorf("error creating XLSX header style: %v", err)
+	}
+	format := `0.00"%"`
+	percent, err := file.NewStyle(&excelize.Style{CustomNumFmt: &format})
+	if err != nil {
+		return nil, fmt.Errorf("error creating XLSX percent style: %v", err)
+	}
+	return &XLSXWriter{file: file, bold: bold, percent: percent}, nil
+}
+
+// Sheet finishes the current sheet and starts writing to a new one with the given name.
+func (w *XLSXWriter) Sheet(name string) error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.file.NewSheet(name); err != nil {
+		return fmt.Errorf("error adding sheet %s to XLSX: %v", name, err)
+	}
+	stream, err := w.file.NewStreamWriter(name)
+	if err != nil {
+		return fmt.Errorf("error writing sheet %s to XLSX: %v", name, err)
+	}
+	w.sheet, w.stream = name, stream
+	w.next, w.tables, w.rows = 1, 0, 0
+	w.filterStart, w.filterEnd = "", ""
+	return nil
+}
+
+// Rows returns the number of rows written to the current sheet, excluding titles, headers,
+// totals and notes.
+func (w *XLSXWriter) Rows() int {
+	return w.rows
+}
+
+// Flush finishes the current sheet, if any. It must be called before the workbook is saved.
+func (w *XLSXWriter) Flush() error {
+	if w.stream == nil {
+		return nil
+	}
+	stream, sheet := w.stream, w.sheet
+	w.stream = nil
+	// The stream writes the sheet's auto-filter when it is flushed, so it is set just before.
+	if w.filterStart != "" && w.filterEnd != "" {
+		if err := w.file.AutoFilter(sheet, w.filterStart+":"+w.filterEnd, nil); err != nil {
+			return fmt.Errorf("error adding auto-filter to sheet %s: %v", sheet, err)
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return fmt.Errorf("error flushing sheet %s to XLSX: %v", sheet, err)
+	}
+	return nil
+}
+
+// Message writes a line of text to the current sheet, such as why a section is unavailable.
+func (w *XLSXWriter) Message(text string) error {
+	return w.write([]any{text}, 0)
+}
+
+func (w *XLSXWriter) Begin(t *Table) error {
+	if w.stream == nil {
+		return fmt.Errorf("no XLSX sheet to write %s to", t.Title)
+	}
+	w.table = t
+	first := w.tables == 0
+	w.tables++
+
+	if !first {
+		// An empty row separates stacked tables.
+		w.next++
+	}
+
+	if first {
+		// Widths and panes must be set before the first row of a streamed sheet.
+		for i, c := range t.Columns {
+			width := float64(len([]rune(c.Header)) + 2)
+			width = min(max(width, xlsxMinWidth), xlsxMaxWidth)
+			if err := w.stream.SetColWidth(i+1, i+1, width); err != nil {
+				return fmt.Errorf("error setting XLSX column width: %v", err)
+			}
+		}
+	}
+
+	if first {
+		header := w.next
+		if t.Title != "" {
+			header++
+		}
+		if err := w.stream.SetPanes(&excelize.Panes{
+			Freeze:      true,
+			YSplit:      header,
+			TopLeftCell: fmt.Sprintf("A%d", header+1),
+			ActivePane:  "bottomLeft",
+		}); err != nil {
+			return fmt.Errorf("error freezing XLSX header row: %v", err)
+		}
+		w.filterStart, _ = excelize.CoordinatesToCellName(1, header)
+		w.filterEnd, _ = excelize.CoordinatesToCellName(max(len(t.Columns), 1), header)
+	}
+
+	if t.Title != "" {
+		if err := w.write([]any{t.Title}, w.bold); err != nil {
+			return err
+		}
+	}
+
+	headers := make([]any, len(t.Columns))
+	for i, h := range t.Headers() {
+		headers[i] = h
+	}
+	return w.write(headers, w.bold)
+}
+
+func (w *XLSXWriter) Row(row Row) error {
+	if err := w.write(w.cells(row, 0), 0); err != nil {
+		return err
+	}
+	w.rows++
+	if w.tables == 1 {
+		w.filterEnd, _ = excelize.CoordinatesToCellName(max(len(w.table.Columns), 1), w.next-1)
+	}
+	return nil
+}
+
+func (w *XLSXWriter) End(t *Table) error {
+	if t.Totals != nil {
+		if err := w.write(w.cells(t.Totals, w.bold), 0); err != nil {
+			return err
+		}
+	}
+	for _, note := range t.Notes {
+		if err := w.write([]any{note}, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cells converts a row to cell values: numbers and booleans as themselves, everything else,
+// durations included, as its text.
+func (w *XLSXWriter) cells(row Row, style int) []any {
+	cells := make([]any, len(w.table.Columns))
+	for i, c := range w.table.Columns {
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		var value any
+		switch v := row[i].(type) {
+		case int64, float64, bool:
+			value = v
+		default:
+			value = c.Text(v)
+		}
+
+		cellStyle := style
+		if c.Kind == Percent && style == 0 {
+			cellStyle = w.percent
+		}
+		if cellStyle != 0 {
+			cells[i] = excelize.Cell{StyleID: cellStyle, Value: value}
+		} else {
+			cells[i] = value
+		}
+	}
+	return cells
+}
+
+// write writes a row of values at the next row of the sheet.
+func (w *XLSXWriter) write(values []any, style int) error {
+	cell, err := excelize.CoordinatesToCellName(1, w.next)
+	if err != nil {
+		return fmt.Errorf("error writing XLSX row: %v", err)
+	}
+	var opts []excelize.RowOpts
+	if style != 0 {
+		opts = append(opts, excelize.RowOpts{StyleID: style})
+	}
+	if err := w.stream.SetRow(cell, values, opts...); err != nil {
+		return fmt.Errorf("error writing XLSX row: %v", err)
+	}
+	w.next++
+	return nil
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"golang.org/x/term"
+)
+
+// Available capacity below these percentages is highlighted in terminal output.
+const (
+	lowCapacityWarning  = 20.0
+	lowCapacityCritical = 10.0
+)
+
+// PrintTables prints the sections of a report to out as aligned text tables, limited to the
+// sections named in names when it is not empty. When out is a terminal, tables are fitted to
+// its width and NotReady nodes, failing pods and low available capacity are coloured, unless
+// NO_COLOR is set. Collection stops when ctx is cancelled or its deadline passes.
+func PrintTables(ctx context.Context, opts Options, report section.Report, names []string, out *os.File) (string, error) {
+	sections, err := selectSections(renderable[section.TableSection](section.For(report)), names)
+	if err != nil {
+		return "", err
+	}
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", err
+	}
+	clusterName := snap.ClusterName
+
+	tw := table.NewTextWriter(out)
+	tw.Highlight = terminalHighlight
+	if fd := int(out.Fd()); term.IsTerminal(fd) {
+		if width, _, err := term.GetSize(fd); err == nil {
+			tw.Width = width
+		}
+		tw.Color = os.Getenv("NO_COLOR") == ""
+	}
+
+	line := fmt.Sprintf("Cluster %s, generated %s", clusterName, time.Now().Format("02-01-2006 15:04"))
+	if !snap.SyncedAt.IsZero() {
+		line += fmt.Sprintf(", rendered from watch cache, last listed %s", snap.SyncedAt.Format("02-01-2006 15:04"))
+	}
+	if err := tw.Message(line, table.Plain); err != nil {
+		return "", err
+	}
+
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+		if err := tw.Heading(title); err != nil {
+			return "", err
+		}
+
+		var failure error
+		if err := section.ResourceErr(s, snap); err != nil {
+			failure = err
+		} else if err := results[i].Err; err != nil {
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if err := renderText(s, tw, snap, results[i].Data); err != nil {
+			if opts.Strict {
+				return "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		}
+		if failure != nil {
+			if err := tw.Message(fmt.Sprintf("section unavailable: %v", failure), table.Critical); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		if err := tw.Heading("Collection errors"); err != nil {
+			return "", err
+		}
+		t := table.New("", table.Column{Header: "Source"}, table.Column{Header: "Reason"})
+		for _, e := range errs {
+			t.AddRow(e.Source, e.Reason)
+		}
+		if err := tw.WriteText(t); err != nil {
+			return "", err
+		}
+	}
+	return clusterName, nil
+}
+
+// renderText prints the tables of a section.
+func renderText(s section.Section, tw *table.TextWriter, snap *snapshot.ClusterSnapshot, data any) error {
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return err
+	}
+	return tw.WriteText(tables...)
+}
+
+// selectSections returns the sections named in names, in report order, or all of them when
+// names is empty. A section is named by its title, ignoring case and brackets, or by the key
+// of its title, such as "pod_details" for "[ POD DETAILS ]".
+func selectSections(sections []section.Section, names []string) ([]section.Section, error) {
+	if len(names) == 0 {
+		return sections, nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[table.Key(name)] = true
+	}
+	var selected []section.Section
+	for _, s := range sections {
+		key := table.Key(s.Name())
+		if wanted[key] {
+			selected = append(selected, s)
+			delete(wanted, key)
+		}
+	}
+	if len(wanted) > 0 {
+		var unknown, available []string
+		for _, name := range names {
+			if wanted[table.Key(name)] {
+				unknown = append(unknown, name)
+			}
+		}
+		for _, s := range sections {
+			available = append(available, table.Key(s.Name()))
+		}
+		return nil, fmt.Errorf("unknown section %s; available sections: %s", strings.Join(unknown, ", "), strings.Join(available, ", "))
+	}
+	return selected, nil
+}
+
+// terminalHighlight highlights the status of NotReady nodes and failing pods, and available
+// capacity percentages that are running low. Pods crash-looping or unable to pull their image
+// are still Running, so a Running pod is failing when the table shows it is not Ready.
+func terminalHighlight(t *table.Table, row table.Row, col int) table.Highlight {
+	if col >= len(row) {
+		return table.Plain
+	}
+	c := t.Columns[col]
+	switch v := row[col].(type) {
+	case string:
+		if !strings.Contains(strings.ToLower(c.Header), "status") {
+			return table.Plain
+		}
+		// The general node table shows the status in brackets after the node name.
+		status := v
+		if open := strings.LastIndex(v, "["); open >= 0 && strings.HasSuffix(v, "]") {
+			status = v[open+1 : len(v)-1]
+		}
+		switch strings.TrimSpace(status) {
+		case "NotReady", "Failed", "Unknown":
+			return table.Critical
+		case "Pending":
+			return table.Warning
+		case "Running":
+			if notReady(t, row) {
+				return table.Critical
+			}
+		}
+	case float64:
+		// Only the percentages on rows of available capacity are checked.
+		label, _ := row[0].(string)
+		if c.Kind != table.Percent || !strings.Contains(label, "Available") {
+			return table.Plain
+		}
+		switch {
+		case v < lowCapacityCritical:
+			return table.Critical
+		case v < lowCapacityWarning:
+			return table.Warning
+		}
+	}
+	return table.Plain
+}
+
+// notReady reports whether a row of a pod table has a conditions column showing Ready=False.
+func notReady(t *table.Table, row table.Row) bool {
+	for i, c := range t.Columns {
+		if i < len(row) && strings.Contains(strings.ToLower(c.Header), "condition") {
+			if conditions, ok := row[i].(string); ok && slices.Contains(strings.Fields(conditions), "Ready=False") {
+				return true
+			}
+		}
+	}
+	return false
+}
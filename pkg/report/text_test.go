@@ -0,0 +1,95 @@
+package report
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+// namedSection is a section with only a name.
+type namedSection string
+
+func (s namedSection) Name() string                     { return string(s) }
+func (namedSection) Dependencies() []section.Dependency { return nil }
+func (namedSection) Resources() []snapshot.Resource     { return nil }
+func (namedSection) Collect(context.Context, section.Clients, *snapshot.ClusterSnapshot) (any, error) {
+	return nil, nil
+}
+
+func TestSelectSections(t *testing.T) {
+	sections := []section.Section{
+		namedSection("[ NODE DETAILS ]"),
+		namedSection("[ POD DETAILS ]"),
+		namedSection("[ SECRETS ]"),
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all", want: []string{"[ NODE DETAILS ]", "[ POD DETAILS ]", "[ SECRETS ]"}},
+		{name: "by key", names: []string{"pod_details"}, want: []string{"[ POD DETAILS ]"}},
+		{name: "by title", names: []string{"[ Secrets ]"}, want: []string{"[ SECRETS ]"}},
+		{name: "report order", names: []string{"secrets", "node details"}, want: []string{"[ NODE DETAILS ]", "[ SECRETS ]"}},
+		{name: "repeated", names: []string{"secrets", "SECRETS"}, want: []string{"[ SECRETS ]"}},
+		{name: "unknown", names: []string{"secrets", "volumes"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := selectSections(sections, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectSections(%v) error = %v, want error %v", tt.names, err, tt.wantErr)
+			}
+			var got []string
+			for _, s := range selected {
+				got = append(got, s.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectSections(%v) = %v, want %v", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalHighlight(t *testing.T) {
+	pods := table.New("",
+		table.Column{Header: "POD NAME"},
+		table.Column{Header: "STATUS"},
+		table.Column{Header: "CONDITIONS"},
+	)
+	nodes := table.New("", table.Column{Header: "NODE [STATUS]"})
+	capacity := table.New("", table.Column{Header: "RESOURCE"}, table.Column{Header: "CPU", Kind: table.Percent})
+
+	tests := []struct {
+		name  string
+		table *table.Table
+		row   table.Row
+		col   int
+		want  table.Highlight
+	}{
+		{name: "running pod", table: pods, row: table.Row{"web", "Running", "Ready=True"}, col: 1, want: table.Plain},
+		{name: "crash-looping pod", table: pods, row: table.Row{"web", "Running", "Initialized=True Ready=False"}, col: 1, want: table.Critical},
+		{name: "pending pod", table: pods, row: table.Row{"web", "Pending", ""}, col: 1, want: table.Warning},
+		{name: "failed pod", table: pods, row: table.Row{"web", "Failed", ""}, col: 1, want: table.Critical},
+		{name: "name column", table: pods, row: table.Row{"Failed", "Running", ""}, col: 0, want: table.Plain},
+		{name: "not ready node", table: nodes, row: table.Row{"node-a [NotReady]"}, col: 0, want: table.Critical},
+		{name: "ready node", table: nodes, row: table.Row{"node-a [Ready]"}, col: 0, want: table.Plain},
+		{name: "low capacity", table: capacity, row: table.Row{"Available", 5.0}, col: 1, want: table.Critical},
+		{name: "capacity running low", table: capacity, row: table.Row{"Available", 15.0}, col: 1, want: table.Warning},
+		{name: "enough capacity", table: capacity, row: table.Row{"Available", 50.0}, col: 1, want: table.Plain},
+		{name: "used capacity", table: capacity, row: table.Row{"Used", 5.0}, col: 1, want: table.Plain},
+		{name: "short row", table: pods, row: table.Row{"web"}, col: 1, want: table.Plain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := terminalHighlight(tt.table, tt.row, tt.col); got != tt.want {
+				t.Errorf("terminalHighlight(%v, %d) = %v, want %v", tt.row, tt.col, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package report
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"github.com/kubesuiteorg/kubereport/assets"
+	"sigs.k8s.io/yaml"
+)
+
+// Theme is the branding and page layout of PDF and HTML reports, read from a YAML file with
+// LoadTheme. Fields the file leaves out keep their defaults. The fields are described with an
+// example in docs/theme.md.
+type Theme struct {
+	// Brand is the name shown above the report title.
+	Brand string `json:"brand,omitempty"`
+	// Logo is the path of a PNG, JPEG or GIF image shown on the PDF cover page and above the
+	// HTML navigation, relative to the theme file. Empty uses the kubereport logo and "none"
+	// shows no logo.
+	Logo string `json:"logo,omitempty"`
+	// Title is the title of the general report and DetailedTitle that of the detailed report.
+	Title         string `json:"title,omitempty"`
+	DetailedTitle string `json:"detailedTitle,omitempty"`
+	// Footer is a line printed at the bottom of every page, such as a confidentiality notice.
+	Footer string `json:"footer,omitempty"`
+	// Paper is the size of PDF pages: A3, A4, A5, Letter or Legal.
+	Paper string `json:"paper,omitempty"`
+	// Orientation is that of the pages of the general report and DetailedOrientation that of
+	// the detailed report: portrait or landscape.
+	Orientation         string      `json:"orientation,omitempty"`
+	DetailedOrientation string      `json:"detailedOrientation,omitempty"`
+	Colors              ThemeColors `json:"colors,omitempty"`
+	Font                ThemeFont   `json:"font,omitempty"`
+
+	// logo is the image Logo names, and logoType its format as gofpdf names it.
+	logo     []byte
+	logoType string
+	// regular and bold are the TrueType fonts Font names. Without bold, bold text is drawn in
+	// the regular font, outlined to make it heavier.
+	regular, bold []byte
+}
+
+// ThemeColors are the colours of a theme, as "#rrggbb".
+type ThemeColors struct {
+	// Primary colours the brand, the headings and the HTML navigation.
+	Primary string `json:"primary,omitempty"`
+	// Accent colours the bars of charts.
+	Accent string `json:"accent,omitempty"`
+	// TableHeader is the background of table header rows.
+	TableHeader string `json:"tableHeader,omitempty"`
+}
+
+// ThemeFont is the font of PDF reports, as paths of TrueType (.ttf) files relative to the theme
+// file. Left out, the bundled M+ 1p font is used, which covers Latin, Cyrillic and Japanese.
+type ThemeFont struct {
+	Regular string `json:"regular,omitempty"`
+	// Bold is used for headings, header rows and totals; left out, they are drawn in Regular,
+	// outlined to make it heavier.
+	Bold string `json:"bold,omitempty"`
+}
+
+// pdfFontFamily is the family the font of a theme is added to PDFs under.
+const pdfFontFamily = "kubereport"
+
+// themePapers are the paper sizes a theme can choose, by their lower-case name.
+var themePapers = map[string]string{"a3": "A3", "a4": "A4", "a5": "A5", "letter": "Letter", "legal": "Legal"}
+
+// DefaultTheme returns the theme of reports without a theme file.
+func DefaultTheme() *Theme {
+	return &Theme{
+		Brand:               "KUBEREPORT",
+		Title:               "Kubernetes Cluster Qualification Report",
+		DetailedTitle:       "Kubernetes Cluster Detailed Report",
+		Paper:               "A4",
+		Orientation:         "portrait",
+		DetailedOrientation: "landscape",
+		Colors: ThemeColors{
+			Primary:     "#1d2330",
+			Accent:      "#3478c3",
+			TableHeader: "#eceff4",
+		},
+		logo:     assets.Logo,
+		logoType: "PNG",
+		regular:  assets.Font,
+	}
+}
+
+// LoadTheme reads a theme file over the default theme and loads the logo it names.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme: %v", err)
+	}
+	theme := DefaultTheme()
+	if err := yaml.UnmarshalStrict(data, theme); err != nil {
+		return nil, fmt.Errorf("failed to parse theme %s: %v", path, err)
+	}
+
+	paper, ok := themePapers[strings.ToLower(theme.Paper)]
+	if !ok {
+		return nil, fmt.Errorf("invalid theme paper %q: use A3, A4, A5, Letter or Legal", theme.Paper)
+	}
+	theme.Paper = paper
+	for _, orientation := range []*string{&theme.Orientation, &theme.DetailedOrientation} {
+		*orientation = strings.ToLower(*orientation)
+		if *orientation != "portrait" && *orientation != "landscape" {
+			return nil, fmt.Errorf("invalid theme orientation %q: use portrait or landscape", *orientation)
+		}
+	}
+	for _, color := range []string{theme.Colors.Primary, theme.Colors.Accent, theme.Colors.TableHeader} {
+		if _, err := parseColor(color); err != nil {
+			return nil, err
+		}
+	}
+
+	if theme.Font.Regular != "" {
+		if theme.regular, err = readThemeFont(path, theme.Font.Regular); err != nil {
+			return nil, err
+		}
+	} else if theme.Font.Bold != "" {
+		return nil, fmt.Errorf("invalid theme font: a bold font needs a regular one")
+	}
+	if theme.Font.Bold != "" {
+		if theme.bold, err = readThemeFont(path, theme.Font.Bold); err != nil {
+			return nil, err
+		}
+	}
+
+	switch theme.Logo {
+	case "":
+	case "none":
+		theme.logo, theme.logoType = nil, ""
+	default:
+		data, err := os.ReadFile(themePath(path, theme.Logo))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read theme logo: %v", err)
+		}
+		switch http.DetectContentType(data) {
+		case "image/png":
+			theme.logoType = "PNG"
+		case "image/jpeg":
+			theme.logoType = "JPG"
+		case "image/gif":
+			theme.logoType = "GIF"
+		default:
+			return nil, fmt.Errorf("invalid theme logo %s: use a PNG, JPEG or GIF image", theme.Logo)
+		}
+		theme.logo = data
+	}
+	return theme, nil
+}
+
+// themePath returns the path of a file named in a theme file, relative to the theme file.
+func themePath(theme, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(theme), name)
+}
+
+// readThemeFont reads a font named in a theme file and checks that gofpdf can embed it.
+// gofpdf prints the fonts it fails to parse rather than failing, so the file is first checked
+// to be TrueType, and then to measure text once added.
+func readThemeFont(theme, name string) ([]byte, error) {
+	data, err := os.ReadFile(themePath(theme, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read theme font: %v", err)
+	}
+	if len(data) < 4 || (string(data[:4]) != "\x00\x01\x00\x00" && string(data[:4]) != "true") {
+		return nil, fmt.Errorf("invalid theme font %s: use a TrueType (.ttf) font", name)
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", data)
+	pdf.SetFont(pdfFontFamily, "", 10)
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("invalid theme font %s: %v", name, err)
+	}
+	if pdf.GetStringWidth("kubereport") <= 0 {
+		return nil, fmt.Errorf("invalid theme font %s: it has no Latin characters", name)
+	}
+	return data, nil
+}
+
+// addPDFFont adds the font of the theme to pdf, in the regular, bold and italic styles that
+// reports use, and reports whether bold is the regular face, to be drawn as a faux bold. The
+// fonts have no italic, so italic text is drawn upright.
+func (t *Theme) addPDFFont(pdf *gofpdf.Fpdf) (fauxBold bool) {
+	bold := t.bold
+	if bold == nil {
+		bold, fauxBold = t.regular, true
+	}
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", t.regular)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "I", t.regular)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "B", bold)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "BI", bold)
+	return fauxBold
+}
+
+// theme returns the theme of the report, the default one unless Theme is set.
+func (opts Options) theme() *Theme {
+	if opts.Theme != nil {
+		return opts.Theme
+	}
+	return DefaultTheme()
+}
+
+// pdfOrientation returns the gofpdf orientation of the general or detailed report.
+func (t *Theme) pdfOrientation(detailed bool) string {
+	orientation := t.Orientation
+	if detailed {
+		orientation = t.DetailedOrientation
+	}
+	if orientation == "landscape" {
+		return "L"
+	}
+	return "P"
+}
+
+// logoMIME returns the media type of the logo, for HTML.
+func (t *Theme) logoMIME() string {
+	switch t.logoType {
+	case "JPG":
+		return "image/jpeg"
+	case "GIF":
+		return "image/gif"
+	}
+	return "image/png"
+}
+
+// parseColor parses a "#rrggbb" colour into its red, green and blue components.
+func parseColor(color string) ([3]int, error) {
+	var rgb [3]int
+	if len(color) != 7 || color[0] != '#' {
+		return rgb, fmt.Errorf("invalid theme color %q: use #rrggbb", color)
+	}
+	for i := range rgb {
+		v, err := strconv.ParseUint(color[1+2*i:3+2*i], 16, 8)
+		if err != nil {
+			return rgb, fmt.Errorf("invalid theme color %q: use #rrggbb", color)
+		}
+		rgb[i] = int(v)
+	}
+	return rgb, nil
+}
+
+// rgb returns the components of a colour of the theme, which LoadTheme has checked.
+func rgb(color string) [3]int {
+	c, _ := parseColor(color)
+	return c
+}
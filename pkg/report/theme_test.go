@@ -0,0 +1,185 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"github.com/kubesuiteorg/kubereport/assets"
+)
+
+func TestLoadTheme(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"logo.png":  assets.Logo,
+		"notes.txt": []byte("not an image"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		theme   string
+		check   func(*Theme) bool
+		wantErr string
+	}{
+		{
+			name:  "empty keeps the defaults",
+			theme: "{}",
+			check: func(th *Theme) bool {
+				d := DefaultTheme()
+				return th.Brand == d.Brand && th.Paper == "A4" && th.Orientation == "portrait" && th.DetailedOrientation == "landscape" && th.logo != nil
+			},
+		},
+		{
+			name:  "names normalised",
+			theme: "paper: letter\norientation: Landscape\ndetailedOrientation: PORTRAIT\n",
+			check: func(th *Theme) bool {
+				return th.Paper == "Letter" && th.Orientation == "landscape" && th.DetailedOrientation == "portrait"
+			},
+		},
+		{
+			name:  "colours",
+			theme: "colors:\n  primary: '#112233'\n",
+			check: func(th *Theme) bool {
+				return th.Colors.Primary == "#112233" && th.Colors.Accent == DefaultTheme().Colors.Accent
+			},
+		},
+		{
+			name:  "logo relative to the theme",
+			theme: "logo: logo.png\n",
+			check: func(th *Theme) bool { return th.logoType == "PNG" && len(th.logo) == len(assets.Logo) },
+		},
+		{
+			name:  "no logo",
+			theme: "logo: none\n",
+			check: func(th *Theme) bool { return th.logo == nil && th.logoType == "" },
+		},
+		{name: "unknown field", theme: "brandName: Acme\n", wantErr: "failed to parse theme"},
+		{name: "paper", theme: "paper: B5\n", wantErr: `invalid theme paper "B5"`},
+		{name: "orientation", theme: "orientation: sideways\n", wantErr: `invalid theme orientation "sideways"`},
+		{name: "colour", theme: "colors:\n  accent: blue\n", wantErr: `invalid theme color "blue"`},
+		{name: "short colour", theme: "colors:\n  tableHeader: '#fff'\n", wantErr: `invalid theme color "#fff"`},
+		{name: "missing logo", theme: "logo: missing.png\n", wantErr: "failed to read theme logo"},
+		{name: "logo not an image", theme: "logo: notes.txt\n", wantErr: "invalid theme logo notes.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "theme.yaml")
+			if err := os.WriteFile(path, []byte(tt.theme), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			theme, err := LoadTheme(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadTheme() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(theme) {
+				t.Errorf("LoadTheme() = %+v", theme)
+			}
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		color   string
+		want    [3]int
+		wantErr bool
+	}{
+		{color: "#000000", want: [3]int{0, 0, 0}},
+		{color: "#3478c3", want: [3]int{52, 120, 195}},
+		{color: "#FFFFFF", want: [3]int{255, 255, 255}},
+		{color: "3478c3", wantErr: true},
+		{color: "#3478c", wantErr: true},
+		{color: "#3478cg", wantErr: true},
+		{color: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseColor(tt.color)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parseColor(%q) = %v, %v, want %v, error %v", tt.color, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestThemeLayout(t *testing.T) {
+	theme := DefaultTheme()
+	if got := theme.pdfOrientation(false); got != "P" {
+		t.Errorf("general report orientation = %s, want P", got)
+	}
+	if got := theme.pdfOrientation(true); got != "L" {
+		t.Errorf("detailed report orientation = %s, want L", got)
+	}
+	css := string(htmlThemeCSS(theme))
+	for _, want := range []string{"--primary: #1d2330", "--accent: #3478c3", "@page { size: a4 portrait; }"} {
+		if !strings.Contains(css, want) {
+			t.Errorf("theme CSS %q lacks %q", css, want)
+		}
+	}
+}
+
+func TestThemeFont(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"regular.ttf": assets.Font,
+		"bold.ttf":    assets.Font,
+		"font.otf":    []byte("OTTO not a TrueType font"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		theme        string
+		wantFauxBold bool
+		wantErr      string
+	}{
+		{name: "bundled font", theme: "{}", wantFauxBold: true},
+		{name: "regular only", theme: "font:\n  regular: regular.ttf\n", wantFauxBold: true},
+		{name: "regular and bold", theme: "font:\n  regular: regular.ttf\n  bold: bold.ttf\n"},
+		{name: "bold only", theme: "font:\n  bold: bold.ttf\n", wantErr: "a bold font needs a regular one"},
+		{name: "not TrueType", theme: "font:\n  regular: font.otf\n", wantErr: "invalid theme font font.otf"},
+		{name: "missing", theme: "font:\n  regular: missing.ttf\n", wantErr: "failed to read theme font"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "theme.yaml")
+			if err := os.WriteFile(path, []byte(tt.theme), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			theme, err := LoadTheme(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadTheme() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pdf := gofpdf.New("P", "mm", "A4", "")
+			if got := theme.addPDFFont(pdf); got != tt.wantFauxBold {
+				t.Errorf("faux bold = %v, want %v", got, tt.wantFauxBold)
+			}
+			for _, style := range []string{"", "B", "I", "BI"} {
+				pdf.SetFont(pdfFontFamily, style, 10)
+			}
+			if err := pdf.Error(); err != nil {
+				t.Errorf("font styles not all added: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// requestStats counts the API requests made through a set of clients and the time they spent
+// throttled, either waiting for the client-side rate limiter or told by the API server to retry
+// later.
+type requestStats struct {
+	requests  atomic.Int64
+	rejected  atomic.Int64
+	throttled atomic.Int64
+}
+
+// requestCounts is a reading of requestStats.
+type requestCounts struct {
+	Requests int64
+	// Rejected is the number of requests the API server answered with 429 Too Many Requests.
+	Rejected  int64
+	Throttled time.Duration
+}
+
+func (s *requestStats) counts() requestCounts {
+	return requestCounts{
+		Requests:  s.requests.Load(),
+		Rejected:  s.rejected.Load(),
+		Throttled: time.Duration(s.throttled.Load()),
+	}
+}
+
+func (c requestCounts) sub(o requestCounts) requestCounts {
+	return requestCounts{
+		Requests:  c.Requests - o.Requests,
+		Rejected:  c.Rejected - o.Rejected,
+		Throttled: c.Throttled - o.Throttled,
+	}
+}
+
+// configureClient applies the QPS, burst and request timeout of opts to config and instruments
+// it so that its requests are counted in the returned stats. All clients created from config
+// share one rate limiter. Watches ignore the request timeout unless watches is false.
+func configureClient(config *rest.Config, opts Options, watches bool) *requestStats {
+	stats := &requestStats{}
+
+	qps, burst := opts.QPS, opts.Burst
+	if qps == 0 {
+		qps = rest.DefaultQPS
+	}
+	if burst <= 0 {
+		burst = rest.DefaultBurst
+	}
+	if qps > 0 {
+		config.QPS, config.Burst = qps, burst
+		config.RateLimiter = measuredLimiter{flowcontrol.NewTokenBucketRateLimiter(qps, burst), stats}
+	} else {
+		// A negative QPS disables client-side rate limiting.
+		config.QPS = -1
+		config.RateLimiter = nil
+	}
+
+	// The timeout of the HTTP client would also end every watch, so it is left unset for
+	// processes that keep watches open.
+	if opts.RequestTimeout > 0 && !watches {
+		config.Timeout = opts.RequestTimeout
+	}
+
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &countingTransport{next: rt, stats: stats}
+	})
+	return stats
+}
+
+// measuredLimiter adds the time spent waiting for a rate limiter to the throttled time.
+type measuredLimiter struct {
+	flowcontrol.RateLimiter
+	stats *requestStats
+}
+
+func (l measuredLimiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	err := l.RateLimiter.Wait(ctx)
+	l.stats.throttled.Add(int64(time.Since(start)))
+	return err
+}
+
+// countingTransport counts requests and the delay API Priority and Fairness asks rejected
+// requests to wait in their Retry-After header, which client-go honours before retrying.
+type countingTransport struct {
+	next  http.RoundTripper
+	stats *requestStats
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.stats.requests.Add(1)
+	resp, err := t.next.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		t.stats.rejected.Add(1)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			t.stats.throttled.Add(int64(time.Duration(seconds) * time.Second))
+		}
+	}
+	return resp, err
+}
+
+// logRequests logs the API requests of a run, to the standard log on stderr outside of a
+// cluster, so that every run reports them.
+func logRequests(counts requestCounts) {
+	format := "API requests: %d (%d rejected with 429), time spent throttled: %s\n"
+	args := []any{counts.Requests, counts.Rejected, counts.Throttled.Round(time.Millisecond)}
+	if logger != nil {
+		logger.Printf(format, args...)
+	} else {
+		log.Printf(format, args...)
+	}
+}
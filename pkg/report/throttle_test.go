@@ -0,0 +1,62 @@
+package report
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roundTripFunc is an http.RoundTripper answering with a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestCountingTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       requestCounts
+	}{
+		{name: "ok", status: http.StatusOK, want: requestCounts{Requests: 1}},
+		{name: "rejected", status: http.StatusTooManyRequests, retryAfter: "3", want: requestCounts{Requests: 1, Rejected: 1, Throttled: 3 * time.Second}},
+		{name: "rejected without delay", status: http.StatusTooManyRequests, want: requestCounts{Requests: 1, Rejected: 1}},
+		{name: "invalid delay", status: http.StatusTooManyRequests, retryAfter: "soon", want: requestCounts{Requests: 1, Rejected: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &requestStats{}
+			transport := &countingTransport{stats: stats, next: roundTripFunc(func(*http.Request) (*http.Response, error) {
+				resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+				if tt.retryAfter != "" {
+					resp.Header.Set("Retry-After", tt.retryAfter)
+				}
+				return resp, nil
+			})}
+			req, _ := http.NewRequest(http.MethodGet, "https://cluster/api/v1/pods", nil)
+			if _, err := transport.RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if got := stats.counts(); got != tt.want {
+				t.Errorf("counts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogRequestsWithoutLogger(t *testing.T) {
+	defer func(l *log.Logger) { logger = l }(logger)
+	logger = nil
+
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	logRequests(requestCounts{Requests: 42, Rejected: 2, Throttled: 1500 * time.Millisecond})
+	if got := buf.String(); !strings.Contains(got, "API requests: 42 (2 rejected with 429), time spent throttled: 1.5s") {
+		t.Errorf("logged %q, want the request counts", got)
+	}
+}
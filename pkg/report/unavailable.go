@@ -0,0 +1,52 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+)
+
+// writePDFUnavailable renders the reason a section could not be produced in place of its table.
+func writePDFUnavailable(pdf *table.PDF, reason error) {
+	table.SetPDFFont(pdf, "I", 11)
+	pdf.SetTextColor(200, 0, 0)
+	pdf.MultiCell(0, 6, table.PDFText(pdf, fmt.Sprintf("section unavailable: %v", reason)), "", "L", false)
+	pdf.SetTextColor(0, 0, 0)
+	table.SetPDFFont(pdf, "", 12)
+}
+
+// writePDFCollectionErrors appends a page listing everything that could not be collected or rendered.
+func writePDFCollectionErrors(doc *pdfDocument, errs []collectionError) {
+	pdf := doc.pdf
+	pdf.AddPage()
+	doc.heading("Collection errors")
+	pdf.Ln(12)
+
+	t := table.New("", table.Column{Header: "Source"}, table.Column{Header: "Reason", Width: 2.5})
+	for _, e := range errs {
+		t.AddRow(e.Source, e.Reason)
+	}
+	table.WritePDF(pdf, t)
+}
+
+// writeCSVUnavailable writes the reason a section could not be produced in place of its rows.
+func writeCSVUnavailable(writer *table.CSVWriter, reason error) error {
+	if err := writer.Record(fmt.Sprintf("section unavailable: %v", reason)); err != nil {
+		return fmt.Errorf("failed to write unavailable section to CSV: %v", err)
+	}
+	return nil
+}
+
+// writeCSVCollectionErrors appends a final section listing everything that could not be collected or rendered.
+func writeCSVCollectionErrors(writer *table.CSVWriter, errs []collectionError) error {
+	rows := [][]string{{"[ COLLECTION ERRORS ]"}, {"Source", "Reason"}}
+	for _, e := range errs {
+		rows = append(rows, []string{e.Source, e.Reason})
+	}
+	for _, row := range rows {
+		if err := writer.Record(row...); err != nil {
+			return fmt.Errorf("failed to write collection errors to CSV: %v", err)
+		}
+	}
+	return nil
+}
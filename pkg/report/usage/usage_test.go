@@ -0,0 +1,63 @@
+package usage
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		quantity   string
+		millicores int64
+		mib        int64
+		gib        float64
+	}{
+		{quantity: "0", millicores: 0, mib: 0, gib: 0},
+		{quantity: "250m", millicores: 250, mib: 0, gib: 1.0 / (1 << 30)},
+		{quantity: "1Gi", millicores: 1073741824000, mib: 1024, gib: 1},
+		{quantity: "1G", millicores: 1000000000000, mib: 953, gib: 1e9 / (1 << 30)},
+		{quantity: "128Mi", millicores: 134217728000, mib: 128, gib: 0.125},
+		{quantity: "100M", millicores: 100000000000, mib: 95, gib: 1e8 / (1 << 30)},
+	}
+	for _, tt := range tests {
+		q := resource.MustParse(tt.quantity)
+		if got := Millicores(q); got != tt.millicores {
+			t.Errorf("Millicores(%s) = %d, want %d", tt.quantity, got, tt.millicores)
+		}
+		if got := MiB(q); got != tt.mib {
+			t.Errorf("MiB(%s) = %d, want %d", tt.quantity, got, tt.mib)
+		}
+		if got := GiB(q); got != tt.gib {
+			t.Errorf("GiB(%s) = %v, want %v", tt.quantity, got, tt.gib)
+		}
+	}
+}
+
+func TestOfPod(t *testing.T) {
+	container := func(cpu, memory string) corev1.Container {
+		return corev1.Container{Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+		}}
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		container("100m", "64Mi"),
+		container("250m", "192Mi"),
+	}}}
+
+	r := OfPod(pod)
+	if got := Millicores(r.CPURequests); got != 350 {
+		t.Errorf("CPU requests = %dm, want 350m", got)
+	}
+	if got := Millicores(r.CPULimits); got != 0 {
+		t.Errorf("CPU limits = %dm, want 0m", got)
+	}
+	if got := MiB(r.MemoryRequests); got != 256 {
+		t.Errorf("memory requests = %d MiB, want 256", got)
+	}
+	if got := MiB(r.MemoryLimits); got != 256 {
+		t.Errorf("memory limits = %d MiB, want 256", got)
+	}
+}
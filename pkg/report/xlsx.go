@@ -0,0 +1,242 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kubesuiteorg/kubereport/pkg/report/section"
+	"github.com/kubesuiteorg/kubereport/pkg/report/snapshot"
+	"github.com/kubesuiteorg/kubereport/pkg/report/table"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSummarySheet is the first sheet of a workbook, linking to the sheet of every section.
+const xlsxSummarySheet = "Summary"
+
+// xlsxMaxSheetName is the longest name Excel accepts for a sheet.
+const xlsxMaxSheetName = 31
+
+// xlsxSheet is a row of the summary sheet.
+type xlsxSheet struct {
+	name   string
+	title  string
+	rows   int
+	status string
+}
+
+// GenerateXLSX writes the sections of a report to a workbook with one sheet per section and a
+// summary sheet linking to them, named after the timestamp. Collection stops when ctx is
+// cancelled or its deadline passes.
+func GenerateXLSX(ctx context.Context, opts Options, report section.Report) (string, string, error) {
+	if logger != nil {
+		logger.Println("Starting XLSX report generation...")
+	}
+
+	sections := renderable[section.TableSection](section.For(report))
+
+	snap, results, err := prepare(ctx, opts, sections)
+	if err != nil {
+		return "", "", err
+	}
+	clusterName := snap.ClusterName
+
+	currentTime := time.Now()
+	formattedTime := currentTime.Format("02-01-2006-15-04")
+	outputPath := fmt.Sprintf("kubernetes_cluster_report_%s.xlsx", formattedTime)
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSummarySheet); err != nil {
+		return "", "", fmt.Errorf("failed to create XLSX summary sheet: %v", err)
+	}
+
+	xw, err := table.NewXLSXWriter(f)
+	if err != nil {
+		return "", "", err
+	}
+
+	used := map[string]bool{strings.ToLower(xlsxSummarySheet): true}
+	var sheets []xlsxSheet
+
+	errs := collectionErrors(snap)
+	for i, s := range sections {
+		if err := ctx.Err(); err != nil {
+			return "", "", fmt.Errorf("report generation cancelled: %v", err)
+		}
+
+		title := strings.TrimSpace(strings.Trim(strings.TrimSpace(s.Name()), "[]"))
+		sheet := xlsxSheet{name: sheetName(title, used), title: title, status: "OK"}
+		if err := xw.Sheet(sheet.name); err != nil {
+			return "", "", err
+		}
+
+		var failure error
+		if err := section.ResourceErr(s, snap); err != nil {
+			failure = err
+		} else if err := results[i].Err; err != nil {
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		} else if err := renderXLSX(s, xw, snap, results[i].Data, opts); err != nil {
+			if logger != nil {
+				logger.Printf("Failed to generate %s: %v\n", s.Name(), err)
+			}
+			if opts.Strict {
+				return "", "", fmt.Errorf("failed to generate %s: %v", s.Name(), err)
+			}
+			failure = err
+			errs = append(errs, collectionError{s.Name(), err.Error()})
+		}
+		if failure != nil {
+			sheet.status = fmt.Sprintf("section unavailable: %v", failure)
+			if err := xw.Message(sheet.status); err != nil {
+				return "", "", err
+			}
+		}
+		sheet.rows = xw.Rows()
+		sheets = append(sheets, sheet)
+	}
+
+	if len(errs) > 0 {
+		sheet := xlsxSheet{name: sheetName("Collection errors", used), title: "Collection errors"}
+		if err := xw.Sheet(sheet.name); err != nil {
+			return "", "", err
+		}
+		t := table.New("", table.Column{Header: "Source"}, table.Column{Header: "Reason"})
+		for _, e := range errs {
+			t.AddRow(e.Source, e.Reason)
+		}
+		if err := table.Write(xw, t); err != nil {
+			return "", "", err
+		}
+		sheet.rows = len(errs)
+		sheets = append(sheets, sheet)
+	}
+
+	if err := xw.Flush(); err != nil {
+		return "", "", err
+	}
+
+	if err := writeXLSXSummary(f, clusterName, currentTime, snap, sheets); err != nil {
+		return "", "", err
+	}
+
+	if err := f.SaveAs(outputPath); err != nil {
+		if logger != nil {
+			logger.Printf("Failed to save XLSX file: %v\n", err)
+		}
+		return "", "", fmt.Errorf("failed to save XLSX file: %v", err)
+	}
+
+	if logger != nil {
+		logger.Println("XLSX report generated successfully.")
+	}
+	return clusterName, outputPath, nil
+}
+
+// renderXLSX writes the tables of a section to the current sheet, a row at a time when streaming.
+func renderXLSX(s section.Section, xw *table.XLSXWriter, snap *snapshot.ClusterSnapshot, data any, opts Options) error {
+	if ss, ok := s.(section.StreamSection); ok && opts.Stream {
+		return ss.Stream(snap, data, xw, opts.SortBuffer)
+	}
+	tables, err := section.TablesOf(s, snap, data)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := table.Write(xw, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeXLSXSummary fills the summary sheet: the cluster, when the report was generated, and a
+// row per section with a link to its sheet.
+func writeXLSXSummary(f *excelize.File, clusterName string, generatedAt time.Time, snap *snapshot.ClusterSnapshot, sheets []xlsxSheet) error {
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create XLSX summary style: %v", err)
+	}
+	link, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Color: "1265BE", Underline: "single"}})
+	if err != nil {
+		return fmt.Errorf("failed to create XLSX summary style: %v", err)
+	}
+
+	rows := [][]any{
+		{"KUBEREPORT"},
+		{"Cluster", clusterName},
+		{"Generated", generatedAt.Format("02-01-2006 15:04")},
+	}
+	if !snap.SyncedAt.IsZero() {
+		rows = append(rows, []any{"Rendered from watch cache, last listed", snap.SyncedAt.Format("02-01-2006 15:04")})
+	}
+	rows = append(rows, nil, []any{"Section", "Rows", "Status"})
+	header := len(rows)
+	for _, sheet := range sheets {
+		rows = append(rows, []any{sheet.title, sheet.rows, sheet.status})
+	}
+
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.SetSheetRow(xlsxSummarySheet, cell, &row); err != nil {
+			return fmt.Errorf("failed to write XLSX summary: %v", err)
+		}
+	}
+
+	if err := f.SetCellStyle(xlsxSummarySheet, "A1", "A1", bold); err != nil {
+		return fmt.Errorf("failed to write XLSX summary: %v", err)
+	}
+	if err := f.SetCellStyle(xlsxSummarySheet, fmt.Sprintf("A%d", header), fmt.Sprintf("C%d", header), bold); err != nil {
+		return fmt.Errorf("failed to write XLSX summary: %v", err)
+	}
+	for i, sheet := range sheets {
+		cell := fmt.Sprintf("A%d", header+i+1)
+		target := fmt.Sprintf("'%s'!A1", strings.ReplaceAll(sheet.name, "'", "''"))
+		if err := f.SetCellHyperLink(xlsxSummarySheet, cell, target, "Location"); err != nil {
+			return fmt.Errorf("failed to link %s from XLSX summary: %v", sheet.title, err)
+		}
+		if err := f.SetCellStyle(xlsxSummarySheet, cell, cell, link); err != nil {
+			return fmt.Errorf("failed to write XLSX summary: %v", err)
+		}
+	}
+
+	if err := f.SetColWidth(xlsxSummarySheet, "A", "A", 40); err != nil {
+		return fmt.Errorf("failed to write XLSX summary: %v", err)
+	}
+	if err := f.SetColWidth(xlsxSummarySheet, "C", "C", 60); err != nil {
+		return fmt.Errorf("failed to write XLSX summary: %v", err)
+	}
+	return nil
+}
+
+// sheetName returns a sheet name for a section title that Excel accepts: without the characters
+// it reserves, at most 31 characters long and, ignoring case, not already in used.
+func sheetName(title string, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return ' '
+		}
+		return r
+	}, title)
+	name = strings.Trim(strings.TrimSpace(name), "'")
+	if name == "" {
+		name = "Section"
+	}
+
+	candidate := truncateRunes(name, xlsxMaxSheetName)
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = truncateRunes(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return strings.TrimSpace(string(runes[:n]))
+	}
+	return s
+}
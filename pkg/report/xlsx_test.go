@@ -0,0 +1,47 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSheetName(t *testing.T) {
+	long := strings.Repeat("Persistent Volume Claims ", 3)
+	tests := []struct {
+		name   string
+		titles []string
+		want   []string
+	}{
+		{name: "plain", titles: []string{"NODE DETAILS"}, want: []string{"NODE DETAILS"}},
+		{name: "forbidden characters", titles: []string{"[ POD: a/b\\c*d? ]"}, want: []string{"POD  a b c d"}},
+		{name: "quotes trimmed", titles: []string{"'quoted'"}, want: []string{"quoted"}},
+		{name: "empty", titles: []string{"[ ]", ""}, want: []string{"Section", "Section (2)"}},
+		{name: "truncated", titles: []string{long}, want: []string{"Persistent Volume Claims Persis"}},
+		{
+			name:   "collisions ignore case",
+			titles: []string{"Pods", "PODS", "pods"},
+			want:   []string{"Pods", "PODS (2)", "pods (3)"},
+		},
+		{
+			name:   "collisions stay within the limit",
+			titles: []string{long, long},
+			want:   []string{"Persistent Volume Claims Persis", "Persistent Volume Claims Pe (2)"},
+		},
+		{name: "runes counted, not bytes", titles: []string{strings.Repeat("ノ", 40)}, want: []string{strings.Repeat("ノ", 31)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			used := make(map[string]bool)
+			for i, title := range tt.titles {
+				got := sheetName(title, used)
+				if got != tt.want[i] {
+					t.Errorf("sheetName(%q) = %q, want %q", title, got, tt.want[i])
+				}
+				if n := utf8.RuneCountInString(got); n > xlsxMaxSheetName {
+					t.Errorf("sheetName(%q) is %d characters long, more than %d", title, n, xlsxMaxSheetName)
+				}
+			}
+		})
+	}
+}